@@ -64,10 +64,18 @@ type User struct {
 // URLValues transforms a User into url.Values using the 'url' struct tag to
 // define the key of the map. Fields are skiped if the value is empty.
 func (u *User) URLValues() url.Values {
+	return structURLValues(u)
+}
+
+// structURLValues transforms the struct pointed to by ptr into url.Values,
+// using the 'url' struct tag to define the key of the map. Fields without the
+// tag, or with a zero value, are skipped; pointer fields are dereferenced, so
+// a pointer to a zero value is still included.
+func structURLValues(ptr interface{}) url.Values {
 	params := url.Values{}
 
-	t := reflect.TypeOf(u).Elem()
-	v := reflect.ValueOf(u).Elem()
+	t := reflect.TypeOf(ptr).Elem()
+	v := reflect.ValueOf(ptr).Elem()
 
 	// Iterate over all available struct fields
 	for i := 0; i < t.NumField(); i++ {
@@ -107,22 +115,28 @@ type Phone struct {
 	Activated        bool
 	Capabilities     []string
 	Encrypted        string
-	Extension        string
+	Extension        string `url:"extension"`
 	Fingerprint      string
 	LastSeen         string `json:"last_seen"`
 	Model            string
-	Name             string
-	Number           string
+	Name             string `url:"name"`
+	Number           string `url:"number"`
 	PhoneID          string `json:"phone_id"`
-	Platform         string
-	Postdelay        string
-	Predelay         string
+	Platform         string `url:"platform"`
+	Postdelay        string `url:"postdelay"`
+	Predelay         string `url:"predelay"`
 	Screenlock       string
 	SMSPasscodesSent bool
-	Type             string
+	Type             string `url:"type"`
 	Users            []User
 }
 
+// URLValues transforms a Phone into url.Values using the 'url' struct tag to
+// define the key of the map. Fields are skiped if the value is empty.
+func (p *Phone) URLValues() url.Values {
+	return structURLValues(p)
+}
+
 // Token models a hardware security token.
 type Token struct {
 	TokenID  string `json:"token_id"`
@@ -137,6 +151,8 @@ type WebAuthnToken struct {
 	DateAdded      uint64 `json:"date_added"`
 	Label          string `json:"label"`
 	WebAuthnKey    string `json:"webauthnkey"`
+	// User is only set on responses from the WebAuthn credential endpoints.
+	User *User `json:"user"`
 }
 
 // U2FToken models a U2F security token.
@@ -447,6 +463,45 @@ func (c *Client) retrieveUserPhones(userID string, params url.Values) (*GetPhone
 	return result, nil
 }
 
+// AssociatePhoneWithUser calls POST /admin/v1/users/:user_id/phones
+// See https://duo.com/docs/adminapi#associate-phone-with-user
+func (c *Client) AssociatePhoneWithUser(userID string, phoneID string) (*duoapi.StatResult, error) {
+	path := fmt.Sprintf("/admin/v1/users/%s/phones", userID)
+
+	params := url.Values{}
+	params.Set("phone_id", phoneID)
+
+	_, body, err := c.SignedCall(http.MethodPost, path, params, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &duoapi.StatResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DisassociatePhoneFromUser calls DELETE /admin/v1/users/:user_id/phones/:phone_id
+// See https://duo.com/docs/adminapi#disassociate-phone-from-user
+func (c *Client) DisassociatePhoneFromUser(userID string, phoneID string) (*duoapi.StatResult, error) {
+	path := fmt.Sprintf("/admin/v1/users/%s/phones/%s", userID, phoneID)
+
+	_, body, err := c.SignedCall(http.MethodDelete, path, nil, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &duoapi.StatResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 // GetUserTokens calls GET /admin/v1/users/:user_id/tokens
 // See https://duo.com/docs/adminapi#retrieve-hardware-tokens-by-user-id
 func (c *Client) GetUserTokens(userID string, options ...func(*url.Values)) (*GetTokensResult, error) {
@@ -509,6 +564,24 @@ func (c *Client) AssociateUserToken(userID, tokenID string) (*StringResult, erro
 	return result, nil
 }
 
+// DisassociateUserToken calls DELETE /admin/v1/users/:user_id/tokens/:token_id
+// See https://duo.com/docs/adminapi#disassociate-hardware-token-from-user
+func (c *Client) DisassociateUserToken(userID, tokenID string) (*duoapi.StatResult, error) {
+	path := fmt.Sprintf("/admin/v1/users/%s/tokens/%s", userID, tokenID)
+
+	_, body, err := c.SignedCall(http.MethodDelete, path, nil, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &duoapi.StatResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 // GetUserU2FTokens calls GET /admin/v1/users/:user_id/u2ftokens
 // See https://duo.com/docs/adminapi#retrieve-u2f-tokens-by-user-id
 func (c *Client) GetUserU2FTokens(userID string, options ...func(*url.Values)) (*GetU2FTokensResult, error) {
@@ -550,28 +623,39 @@ type StringArrayResult struct {
 	Response []string
 }
 
-// GetUserBypassCodes calls POST /admin/v1/users/:user_id/bypass_codes
-// see https://duo.com/docs/adminapi#create-bypass-codes-for-user
-func (c *Client) GetUserBypassCodes(userID string, options ...func(*url.Values)) (*StringArrayResult, error) {
-	path := fmt.Sprintf("/admin/v1/users/%s/bypass_codes", userID)
-
+// GetUserWebAuthnCredentials calls GET /admin/v1/users/:user_id/webauthncredentials
+// See https://duo.com/docs/adminapi#retrieve-webauthn-credentials-by-user-id
+func (c *Client) GetUserWebAuthnCredentials(userID string, options ...func(*url.Values)) (*GetWebAuthnCredentialsResult, error) {
 	params := url.Values{}
 	for _, o := range options {
 		o(&params)
 	}
 
-	_, body, err := c.SignedCall(http.MethodPost, path, params, duoapi.UseTimeout)
+	path := fmt.Sprintf("/admin/v1/users/%s/webauthncredentials", userID)
+	cb := func(params url.Values) (responsePage, error) {
+		return c.retrieveWebAuthnCredentials(path, params)
+	}
+	response, err := c.retrieveItems(params, cb)
 	if err != nil {
 		return nil, err
 	}
 
-	result := &StringArrayResult{}
-	err = json.Unmarshal(body, result)
-	if err != nil {
-		return nil, err
+	return response.(*GetWebAuthnCredentialsResult), nil
+}
+
+// GetUserBypassCodes calls POST /admin/v1/users/:user_id/bypass_codes
+// see https://duo.com/docs/adminapi#create-bypass-codes-for-user
+//
+// Deprecated: despite its name this creates new bypass codes. Use
+// CreateUserBypassCodes, or IssueUserBypassCodes to keep the codes out of the
+// returned result.
+func (c *Client) GetUserBypassCodes(userID string, options ...func(*url.Values)) (*StringArrayResult, error) {
+	params := url.Values{}
+	for _, o := range options {
+		o(&params)
 	}
 
-	return result, nil
+	return c.createUserBypassCodes(userID, params)
 }
 
 // Group methods
@@ -649,6 +733,109 @@ func (c *Client) GetGroup(groupID string) (*GetGroupResult, error) {
 	return result, nil
 }
 
+// GroupUpdate holds the fields of a group to set with CreateGroup or
+// ModifyGroup. Nil fields are left unchanged.
+type GroupUpdate struct {
+	Name *string `url:"name"`
+	Desc *string `url:"desc"`
+	// Status is "active", "bypass" or "disabled".
+	Status *string `url:"status"`
+}
+
+// URLValues transforms a GroupUpdate into url.Values, skipping nil fields.
+func (g *GroupUpdate) URLValues() url.Values {
+	return structURLValues(g)
+}
+
+// CreateGroup calls POST /admin/v1/groups
+// The group's Name is required.
+// See https://duo.com/docs/adminapi#create-group
+func (c *Client) CreateGroup(group GroupUpdate) (*GetGroupResult, error) {
+	_, body, err := c.SignedCall(http.MethodPost, "/admin/v1/groups", group.URLValues(), duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GetGroupResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ModifyGroup calls POST /admin/v1/groups/:group_id
+// See https://duo.com/docs/adminapi#update-group
+func (c *Client) ModifyGroup(groupID string, update GroupUpdate) (*GetGroupResult, error) {
+	path := fmt.Sprintf("/admin/v1/groups/%s", groupID)
+
+	_, body, err := c.SignedCall(http.MethodPost, path, update.URLValues(), duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GetGroupResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteGroup calls DELETE /admin/v1/groups/:group_id
+// See https://duo.com/docs/adminapi#delete-group
+func (c *Client) DeleteGroup(groupID string) (*duoapi.StatResult, error) {
+	path := fmt.Sprintf("/admin/v1/groups/%s", groupID)
+
+	_, body, err := c.SignedCall(http.MethodDelete, path, nil, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &duoapi.StatResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetGroupUsers calls GET /admin/v2/groups/:group_id/users
+// Only the UserID and Username of each user are returned.
+// See https://duo.com/docs/adminapi#v2-groups-get-users
+func (c *Client) GetGroupUsers(groupID string, options ...func(*url.Values)) (*GetUsersResult, error) {
+	params := url.Values{}
+	for _, o := range options {
+		o(&params)
+	}
+
+	cb := func(params url.Values) (responsePage, error) {
+		return c.retrieveGroupUsers(groupID, params)
+	}
+	response, err := c.retrieveItems(params, cb)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.(*GetUsersResult), nil
+}
+
+func (c *Client) retrieveGroupUsers(groupID string, params url.Values) (*GetUsersResult, error) {
+	path := fmt.Sprintf("/admin/v2/groups/%s/users", groupID)
+
+	_, body, err := c.SignedCall(http.MethodGet, path, params, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GetUsersResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 // Phone methods
 
 // GetPhonesNumber sets the optional number parameter for a GetPhones request.
@@ -756,6 +943,180 @@ func (c *Client) DeletePhone(phoneID string) (*duoapi.StatResult, error) {
 	return result, nil
 }
 
+// CreatePhone calls POST /admin/v1/phones
+// The Number, Name, Extension, Type, Platform, Predelay and Postdelay fields
+// of phone are sent when set.
+// See https://duo.com/docs/adminapi#create-phone
+func (c *Client) CreatePhone(phone Phone) (*GetPhoneResult, error) {
+	_, body, err := c.SignedCall(http.MethodPost, "/admin/v1/phones", phone.URLValues(), duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GetPhoneResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ModifyPhone calls POST /admin/v1/phones/:phone_id
+// Only the fields of phone that are set are changed.
+// See https://duo.com/docs/adminapi#modify-phone
+func (c *Client) ModifyPhone(phoneID string, phone Phone) (*GetPhoneResult, error) {
+	path := fmt.Sprintf("/admin/v1/phones/%s", phoneID)
+
+	_, body, err := c.SignedCall(http.MethodPost, path, phone.URLValues(), duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GetPhoneResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ActivationValidSecs sets the optional valid_secs parameter for
+// CreateActivationURL and SendSMSActivation requests.
+func ActivationValidSecs(secs int) func(*url.Values) {
+	return func(opts *url.Values) {
+		opts.Set("valid_secs", strconv.Itoa(secs))
+	}
+}
+
+// ActivationInstall sets the optional install parameter for
+// CreateActivationURL and SendSMSActivation requests, so that an installation
+// URL is generated as well.
+func ActivationInstall() func(*url.Values) {
+	return func(opts *url.Values) {
+		opts.Set("install", "1")
+	}
+}
+
+// ActivationMsg sets the optional activation_msg parameter for a
+// SendSMSActivation request.
+func ActivationMsg(msg string) func(*url.Values) {
+	return func(opts *url.Values) {
+		opts.Set("activation_msg", msg)
+	}
+}
+
+// InstallationMsg sets the optional installation_msg parameter for
+// SendSMSActivation and SendSMSInstallation requests.
+func InstallationMsg(msg string) func(*url.Values) {
+	return func(opts *url.Values) {
+		opts.Set("installation_msg", msg)
+	}
+}
+
+// PhoneActivation models the activation details of a phone.
+type PhoneActivation struct {
+	ActivationBarcode string `json:"activation_barcode"`
+	ActivationMsg     string `json:"activation_msg"`
+	ActivationURL     string `json:"activation_url"`
+	InstallationMsg   string `json:"installation_msg"`
+	InstallationURL   string `json:"installation_url"`
+	ValidSecs         int    `json:"valid_secs"`
+}
+
+// PhoneActivationResult models responses containing phone activation details.
+type PhoneActivationResult struct {
+	duoapi.StatResult
+	Response PhoneActivation
+}
+
+// CreateActivationURL calls POST /admin/v1/phones/:phone_id/activation_url
+// See https://duo.com/docs/adminapi#create-activation-url
+func (c *Client) CreateActivationURL(phoneID string, options ...func(*url.Values)) (*PhoneActivationResult, error) {
+	path := fmt.Sprintf("/admin/v1/phones/%s/activation_url", phoneID)
+
+	params := url.Values{}
+	for _, o := range options {
+		o(&params)
+	}
+
+	_, body, err := c.SignedCall(http.MethodPost, path, params, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PhoneActivationResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SendSMSActivation calls POST /admin/v1/phones/:phone_id/send_sms_activation
+// See https://duo.com/docs/adminapi#send-activation-sms
+func (c *Client) SendSMSActivation(phoneID string, options ...func(*url.Values)) (*PhoneActivationResult, error) {
+	path := fmt.Sprintf("/admin/v1/phones/%s/send_sms_activation", phoneID)
+
+	params := url.Values{}
+	for _, o := range options {
+		o(&params)
+	}
+
+	_, body, err := c.SignedCall(http.MethodPost, path, params, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PhoneActivationResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SendSMSInstallation calls POST /admin/v1/phones/:phone_id/send_sms_installation
+// Only the InstallationMsg field of the result is set.
+// See https://duo.com/docs/adminapi#send-installation-sms
+func (c *Client) SendSMSInstallation(phoneID string, options ...func(*url.Values)) (*PhoneActivationResult, error) {
+	path := fmt.Sprintf("/admin/v1/phones/%s/send_sms_installation", phoneID)
+
+	params := url.Values{}
+	for _, o := range options {
+		o(&params)
+	}
+
+	_, body, err := c.SignedCall(http.MethodPost, path, params, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PhoneActivationResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SendSMSPasscodes calls POST /admin/v1/phones/:phone_id/send_sms_passcodes
+// See https://duo.com/docs/adminapi#send-passcodes-via-sms
+func (c *Client) SendSMSPasscodes(phoneID string) (*StringResult, error) {
+	path := fmt.Sprintf("/admin/v1/phones/%s/send_sms_passcodes", phoneID)
+
+	_, body, err := c.SignedCall(http.MethodPost, path, nil, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &StringResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 // Token methods
 
 // GetTokensTypeAndSerial sets the optional type and serial parameters for a GetTokens request.
@@ -839,6 +1200,94 @@ func (c *Client) GetToken(tokenID string) (*GetTokenResult, error) {
 	return result, nil
 }
 
+// Hardware token types accepted by CreateToken.
+const (
+	TokenTypeHOTP6   = "h6"
+	TokenTypeHOTP8   = "h8"
+	TokenTypeTOTP6   = "t6"
+	TokenTypeTOTP8   = "t8"
+	TokenTypeYubiKey = "yk"
+)
+
+// TokenCreate holds the parameters of a token to create with CreateToken.
+// Secret, Counter and TOTPStep apply to OATH tokens; PrivateID and AESKey
+// apply to YubiKeys in Yubico OTP mode.
+type TokenCreate struct {
+	Type   string `url:"type"`
+	Serial string `url:"serial"`
+	// Secret is the hex encoded OATH secret.
+	Secret string `url:"secret"`
+	// Counter is the initial HOTP counter.
+	Counter *uint64 `url:"counter"`
+	// TOTPStep is the TOTP time step in seconds.
+	TOTPStep  *int   `url:"totp_step"`
+	PrivateID string `url:"private_id"`
+	AESKey    string `url:"aes_key"`
+}
+
+// URLValues transforms a TokenCreate into url.Values, skipping empty fields.
+func (t *TokenCreate) URLValues() url.Values {
+	return structURLValues(t)
+}
+
+// CreateToken calls POST /admin/v1/tokens
+// See https://duo.com/docs/adminapi#create-hardware-token
+func (c *Client) CreateToken(token TokenCreate) (*GetTokenResult, error) {
+	_, body, err := c.SignedCall(http.MethodPost, "/admin/v1/tokens", token.URLValues(), duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GetTokenResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ResyncToken calls POST /admin/v1/tokens/:token_id/resync
+// code1, code2 and code3 are three consecutive codes generated by the token.
+// See https://duo.com/docs/adminapi#resync-hardware-token
+func (c *Client) ResyncToken(tokenID, code1, code2, code3 string) (*StringResult, error) {
+	path := fmt.Sprintf("/admin/v1/tokens/%s/resync", tokenID)
+
+	params := url.Values{}
+	params.Set("code1", code1)
+	params.Set("code2", code2)
+	params.Set("code3", code3)
+
+	_, body, err := c.SignedCall(http.MethodPost, path, params, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &StringResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteToken calls DELETE /admin/v1/tokens/:token_id
+// See https://duo.com/docs/adminapi#delete-hardware-token
+func (c *Client) DeleteToken(tokenID string) (*duoapi.StatResult, error) {
+	path := fmt.Sprintf("/admin/v1/tokens/%s", tokenID)
+
+	_, body, err := c.SignedCall(http.MethodDelete, path, nil, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &duoapi.StatResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 // U2F token methods
 
 // GetU2FTokensResult models responses containing a list of U2F tokens.
@@ -890,9 +1339,15 @@ func (c *Client) retrieveU2FTokens(params url.Values) (*GetU2FTokensResult, erro
 	return result, nil
 }
 
+// GetU2FTokenResult models responses containing a single U2F token.
+type GetU2FTokenResult struct {
+	duoapi.StatResult
+	Response U2FToken
+}
+
 // GetU2FToken calls GET /admin/v1/u2ftokens/:registration_id
 // See https://duo.com/docs/adminapi#retrieve-u2f-token-by-id
-func (c *Client) GetU2FToken(registrationID string) (*GetU2FTokensResult, error) {
+func (c *Client) GetU2FToken(registrationID string) (*GetU2FTokenResult, error) {
 	path := fmt.Sprintf("/admin/v1/u2ftokens/%s", registrationID)
 
 	_, body, err := c.SignedCall(http.MethodGet, path, nil, duoapi.UseTimeout)
@@ -900,7 +1355,118 @@ func (c *Client) GetU2FToken(registrationID string) (*GetU2FTokensResult, error)
 		return nil, err
 	}
 
-	result := &GetU2FTokensResult{}
+	result := &GetU2FTokenResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteU2FToken calls DELETE /admin/v1/u2ftokens/:registration_id
+// See https://duo.com/docs/adminapi#delete-u2f-token
+func (c *Client) DeleteU2FToken(registrationID string) (*duoapi.StatResult, error) {
+	path := fmt.Sprintf("/admin/v1/u2ftokens/%s", registrationID)
+
+	_, body, err := c.SignedCall(http.MethodDelete, path, nil, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &duoapi.StatResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// WebAuthn credential methods
+
+// GetWebAuthnCredentialsResult models responses containing a list of WebAuthn credentials.
+type GetWebAuthnCredentialsResult struct {
+	duoapi.StatResult
+	ListResult
+	Response []WebAuthnToken
+}
+
+func (result *GetWebAuthnCredentialsResult) getResponse() interface{} {
+	return result.Response
+}
+
+func (result *GetWebAuthnCredentialsResult) appendResponse(credentials interface{}) {
+	asserted_credentials := credentials.([]WebAuthnToken)
+	result.Response = append(result.Response, asserted_credentials...)
+}
+
+// GetWebAuthnCredentials calls GET /admin/v1/webauthncredentials
+// See https://duo.com/docs/adminapi#retrieve-webauthn-credentials
+func (c *Client) GetWebAuthnCredentials(options ...func(*url.Values)) (*GetWebAuthnCredentialsResult, error) {
+	params := url.Values{}
+	for _, o := range options {
+		o(&params)
+	}
+
+	cb := func(params url.Values) (responsePage, error) {
+		return c.retrieveWebAuthnCredentials("/admin/v1/webauthncredentials", params)
+	}
+	response, err := c.retrieveItems(params, cb)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.(*GetWebAuthnCredentialsResult), nil
+}
+
+func (c *Client) retrieveWebAuthnCredentials(path string, params url.Values) (*GetWebAuthnCredentialsResult, error) {
+	_, body, err := c.SignedCall(http.MethodGet, path, params, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GetWebAuthnCredentialsResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetWebAuthnCredentialResult models responses containing a single WebAuthn credential.
+type GetWebAuthnCredentialResult struct {
+	duoapi.StatResult
+	Response WebAuthnToken
+}
+
+// GetWebAuthnCredential calls GET /admin/v1/webauthncredentials/:webauthnkey
+// See https://duo.com/docs/adminapi#retrieve-webauthn-credentials-by-key
+func (c *Client) GetWebAuthnCredential(webAuthnKey string) (*GetWebAuthnCredentialResult, error) {
+	path := fmt.Sprintf("/admin/v1/webauthncredentials/%s", webAuthnKey)
+
+	_, body, err := c.SignedCall(http.MethodGet, path, nil, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GetWebAuthnCredentialResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteWebAuthnCredential calls DELETE /admin/v1/webauthncredentials/:webauthnkey
+// See https://duo.com/docs/adminapi#delete-webauthn-credential
+func (c *Client) DeleteWebAuthnCredential(webAuthnKey string) (*duoapi.StatResult, error) {
+	path := fmt.Sprintf("/admin/v1/webauthncredentials/%s", webAuthnKey)
+
+	_, body, err := c.SignedCall(http.MethodDelete, path, nil, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &duoapi.StatResult{}
 	err = json.Unmarshal(body, result)
 	if err != nil {
 		return nil, err
@@ -873,6 +873,61 @@ func TestDisassociateGroupFromUser(t *testing.T) {
 	}
 }
 
+func TestAssociatePhoneWithUser(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			fmt.Fprintln(w, associateGroupWithUserResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.AssociatePhoneWithUser("DU3RP9I2WOC59VZX672N", "DPFZRS9FB0D46QFTM899")
+	if err != nil {
+		t.Errorf("Unexpected error from AssociatePhoneWithUser call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if last_request.URL.Path != "/admin/v1/users/DU3RP9I2WOC59VZX672N/phones" {
+		t.Errorf("Unexpected request path %s", last_request.URL.Path)
+	}
+	if last_request.Form.Get("phone_id") != "DPFZRS9FB0D46QFTM899" {
+		t.Errorf("Expected phone_id in request, but got %s", last_request.Form.Get("phone_id"))
+	}
+}
+
+func TestDisassociatePhoneFromUser(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, associateGroupWithUserResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.DisassociatePhoneFromUser("DU3RP9I2WOC59VZX672N", "DPFZRS9FB0D46QFTM899")
+	if err != nil {
+		t.Errorf("Unexpected error from DisassociatePhoneFromUser call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if last_request.Method != http.MethodDelete {
+		t.Errorf("Expected DELETE, but got %s", last_request.Method)
+	}
+	if last_request.URL.Path != "/admin/v1/users/DU3RP9I2WOC59VZX672N/phones/DPFZRS9FB0D46QFTM899" {
+		t.Errorf("Unexpected request path %s", last_request.URL.Path)
+	}
+}
+
 const getUserPhonesResponse = `{
 	"stat": "OK",
 	"response": [{
@@ -1299,6 +1354,33 @@ func TestAssociateUserToken(t *testing.T) {
 	}
 }
 
+func TestDisassociateUserToken(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, associateUserTokenResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.DisassociateUserToken("DU3RP9I2WOC59VZX672N", "DHEKH0JJIYC1LX3AZWO4")
+	if err != nil {
+		t.Errorf("Unexpected error from DisassociateUserToken call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if last_request.Method != http.MethodDelete {
+		t.Errorf("Expected DELETE, but got %s", last_request.Method)
+	}
+	if last_request.URL.Path != "/admin/v1/users/DU3RP9I2WOC59VZX672N/tokens/DHEKH0JJIYC1LX3AZWO4" {
+		t.Errorf("Unexpected request path %s", last_request.URL.Path)
+	}
+}
+
 const getUserU2FTokensResponse = `{
 	"stat": "OK",
 	"response": [{
@@ -1581,6 +1663,229 @@ func TestGetGroup(t *testing.T) {
 	}
 }
 
+func TestGroupUpdate_URLValues(t *testing.T) {
+	name := "Group Name"
+	empty := ""
+	update := GroupUpdate{Name: &name, Desc: &empty}
+	want := url.Values{
+		"name": []string{"Group Name"},
+		"desc": []string{""},
+	}
+	if got := update.URLValues(); !reflect.DeepEqual(got, want) {
+		t.Errorf("GroupUpdate.URLValues() = %v, want %v", got, want)
+	}
+}
+
+func TestCreateGroup(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			fmt.Fprintln(w, getGroupResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	name := "Group Name"
+	result, err := duo.CreateGroup(GroupUpdate{Name: &name})
+	if err != nil {
+		t.Errorf("Unexpected error from CreateGroup call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if result.Response.GroupID != "DGXXXXXXXXXXXXXXXXXX" {
+		t.Errorf("Expected group ID DGXXXXXXXXXXXXXXXXXX, but got %s", result.Response.GroupID)
+	}
+	if last_request.Method != http.MethodPost {
+		t.Errorf("Expected POST, but got %s", last_request.Method)
+	}
+	if last_request.URL.Path != "/admin/v1/groups" {
+		t.Errorf("Unexpected request path %s", last_request.URL.Path)
+	}
+	if last_request.Form.Get("name") != name {
+		t.Errorf("Expected name %s in request, but got %s", name, last_request.Form.Get("name"))
+	}
+}
+
+func TestModifyGroup(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			fmt.Fprintln(w, getGroupResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	status := "disabled"
+	result, err := duo.ModifyGroup("DGXXXXXXXXXXXXXXXXXX", GroupUpdate{Status: &status})
+	if err != nil {
+		t.Errorf("Unexpected error from ModifyGroup call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if last_request.URL.Path != "/admin/v1/groups/DGXXXXXXXXXXXXXXXXXX" {
+		t.Errorf("Unexpected request path %s", last_request.URL.Path)
+	}
+	if last_request.Form.Get("status") != status {
+		t.Errorf("Expected status %s in request, but got %s", status, last_request.Form.Get("status"))
+	}
+	if _, ok := last_request.Form["name"]; ok {
+		t.Errorf("Expected name to be omitted from request, but got %s", last_request.Form.Get("name"))
+	}
+}
+
+func TestDeleteGroup(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, deleteUserResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.DeleteGroup("DGXXXXXXXXXXXXXXXXXX")
+	if err != nil {
+		t.Errorf("Unexpected error from DeleteGroup call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if last_request.Method != http.MethodDelete {
+		t.Errorf("Expected DELETE, but got %s", last_request.Method)
+	}
+}
+
+const getGroupUsersResponse = `{
+	"stat": "OK",
+	"metadata": {
+		"total_objects": 2
+	},
+	"response": [{
+		"user_id": "DUXXXXXXXXXXXXXXXXX1",
+		"username": "fmodroj"
+	},
+	{
+		"user_id": "DUXXXXXXXXXXXXXXXXX2",
+		"username": "jsmith"
+	}]
+}`
+
+func TestGetGroupUsers(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, getGroupUsersResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.GetGroupUsers("DGXXXXXXXXXXXXXXXXXX")
+	if err != nil {
+		t.Errorf("Unexpected error from GetGroupUsers call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if len(result.Response) != 2 {
+		t.Errorf("Expected 2 users, but got %d", len(result.Response))
+	}
+	if result.Response[1].Username != "jsmith" {
+		t.Errorf("Expected username jsmith, but got %s", result.Response[1].Username)
+	}
+	if last_request.URL.Path != "/admin/v2/groups/DGXXXXXXXXXXXXXXXXXX/users" {
+		t.Errorf("Unexpected request path %s", last_request.URL.Path)
+	}
+
+	request_query := last_request.URL.Query()
+	if request_query["limit"][0] != "100" {
+		t.Errorf("Expected to see a limit of 100 in request, bug got %s", request_query["limit"])
+	}
+	if request_query["offset"][0] != "0" {
+		t.Errorf("Expected to see an offset of 0 in request, bug got %s", request_query["offset"])
+	}
+}
+
+func TestGetGroupUsersMultiple(t *testing.T) {
+	requests := []*http.Request{}
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(requests) == 0 {
+				fmt.Fprintln(w, getUsersPage1Response)
+			} else {
+				fmt.Fprintln(w, getUsersPage2Response)
+			}
+			requests = append(requests, r)
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.GetGroupUsers("DGXXXXXXXXXXXXXXXXXX")
+
+	if len(requests) != 2 {
+		t.Errorf("Expected two requets, found %d", len(requests))
+	}
+
+	if len(result.Response) != 2 {
+		t.Errorf("Expected two users in the response, found %d", len(result.Response))
+	}
+
+	if err != nil {
+		t.Errorf("Expected err to be nil, found %s", err)
+	}
+}
+
+func TestGetGroupUsersPageArgs(t *testing.T) {
+	requests := []*http.Request{}
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, getEmptyPageArgsResponse)
+			requests = append(requests, r)
+		}),
+	)
+
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	_, err := duo.GetGroupUsers("DGXXXXXXXXXXXXXXXXXX", func(values *url.Values) {
+		values.Set("limit", "200")
+		values.Set("offset", "1")
+		return
+	})
+
+	if err != nil {
+		t.Errorf("Encountered unexpected error: %s", err)
+	}
+
+	if len(requests) != 1 {
+		t.Errorf("Expected there to be one request, found %d", len(requests))
+	}
+	request_query := requests[0].URL.Query()
+	if request_query["limit"][0] != "200" {
+		t.Errorf("Expected to see a limit of 200 in request, bug got %s", request_query["limit"])
+	}
+	if request_query["offset"][0] != "1" {
+		t.Errorf("Expected to see an offset of 1 in request, bug got %s", request_query["offset"])
+	}
+}
+
 const getPhonesResponse = `{
 	"stat": "OK",
 	"response": [{
@@ -1907,37 +2212,264 @@ func TestDeletePhone(t *testing.T) {
 	}
 }
 
-const getTokensResponse = `{
-	"stat": "OK",
-	"response": [{
-		"serial": "0",
-		"token_id": "DHIZ34ALBA2445ND4AI2",
-		"type": "d1",
-		"totp_step": null,
-		"users": [{
-			"user_id": "DUJZ2U4L80HT45MQ4EOQ",
-			"username": "jsmith",
-			"alias1": "joe.smith",
-			"alias2": "jsmith@example.com",
-			"realname": "Joe Smith",
-			"email": "jsmith@example.com",
-			"status": "active",
-			"last_login": 1343921403,
-			"notes": ""
-		}]
-	}],
-	"metadata": {
-		"prev_offset": null,
-		"next_offset": null,
-		"total_objects": 1
+func TestPhone_URLValues(t *testing.T) {
+	phone := Phone{
+		Activated: true,
+		Number:    "+15555550100",
+		Type:      "Mobile",
+		Platform:  "Google Android",
+		PhoneID:   "DPFZRS9FB0D46QFTM899",
 	}
-}`
+	want := url.Values{
+		"number":   []string{"+15555550100"},
+		"type":     []string{"Mobile"},
+		"platform": []string{"Google Android"},
+	}
+	if got := phone.URLValues(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Phone.URLValues() = %v, want %v", got, want)
+	}
+}
 
-func TestGetTokens(t *testing.T) {
+func TestCreatePhone(t *testing.T) {
 	var last_request *http.Request
 	ts := httptest.NewTLSServer(
 		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			fmt.Fprintln(w, getTokensResponse)
+			r.ParseForm()
+			fmt.Fprintln(w, getPhoneResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.CreatePhone(Phone{Number: "+15555550100", Type: "Mobile"})
+	if err != nil {
+		t.Errorf("Unexpected error from CreatePhone call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if result.Response.PhoneID != "DPFZRS9FB0D46QFTM899" {
+		t.Errorf("Expected phone ID DPFZRS9FB0D46QFTM899, but got %s", result.Response.PhoneID)
+	}
+	if last_request.Method != http.MethodPost {
+		t.Errorf("Expected POST, but got %s", last_request.Method)
+	}
+	if last_request.URL.Path != "/admin/v1/phones" {
+		t.Errorf("Unexpected request path %s", last_request.URL.Path)
+	}
+	if last_request.Form.Get("number") != "+15555550100" {
+		t.Errorf("Expected number in request, but got %s", last_request.Form.Get("number"))
+	}
+	if last_request.Form.Get("type") != "Mobile" {
+		t.Errorf("Expected type in request, but got %s", last_request.Form.Get("type"))
+	}
+}
+
+func TestModifyPhone(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			fmt.Fprintln(w, getPhoneResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.ModifyPhone("DPFZRS9FB0D46QFTM899", Phone{Name: "Work phone"})
+	if err != nil {
+		t.Errorf("Unexpected error from ModifyPhone call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if last_request.URL.Path != "/admin/v1/phones/DPFZRS9FB0D46QFTM899" {
+		t.Errorf("Unexpected request path %s", last_request.URL.Path)
+	}
+	if len(last_request.Form) != 1 || last_request.Form.Get("name") != "Work phone" {
+		t.Errorf("Expected only name in request, but got %v", last_request.Form)
+	}
+}
+
+const sendSMSActivationResponse = `{
+	"stat": "OK",
+	"response": {
+		"activation_barcode": "https://api-abcdef.duosecurity.com/frame/qr?value=8LIRa5danrICkhHtkLxi-cKLu2DWzDYCmBwBHY2YzW5ZYnYaRxA",
+		"activation_msg": "To activate the Duo Mobile app, click this link: https://m-abcdef.duosecurity.com/iphone/7dE9jCeKm8IL6P2tHYn9",
+		"activation_url": "https://m-abcdef.duosecurity.com/iphone/7dE9jCeKm8IL6P2tHYn9",
+		"installation_msg": "Welcome to Duo! To install the Duo Mobile app, click this link: https://m-abcdef.duosecurity.com/iphone/7dE9jCeKm8IL6P2tHYn9",
+		"installation_url": "https://m-abcdef.duosecurity.com/iphone/7dE9jCeKm8IL6P2tHYn9",
+		"valid_secs": 3600
+	}
+}`
+
+func TestCreateActivationURL(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			fmt.Fprintln(w, sendSMSActivationResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.CreateActivationURL("DPFZRS9FB0D46QFTM899", ActivationValidSecs(3600), ActivationInstall())
+	if err != nil {
+		t.Errorf("Unexpected error from CreateActivationURL call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if result.Response.ValidSecs != 3600 {
+		t.Errorf("Expected valid_secs of 3600, but got %d", result.Response.ValidSecs)
+	}
+	if result.Response.ActivationURL != "https://m-abcdef.duosecurity.com/iphone/7dE9jCeKm8IL6P2tHYn9" {
+		t.Errorf("Unexpected activation URL %s", result.Response.ActivationURL)
+	}
+	if last_request.URL.Path != "/admin/v1/phones/DPFZRS9FB0D46QFTM899/activation_url" {
+		t.Errorf("Unexpected request path %s", last_request.URL.Path)
+	}
+	if last_request.Form.Get("valid_secs") != "3600" {
+		t.Errorf("Expected valid_secs of 3600 in request, but got %s", last_request.Form.Get("valid_secs"))
+	}
+	if last_request.Form.Get("install") != "1" {
+		t.Errorf("Expected install of 1 in request, but got %s", last_request.Form.Get("install"))
+	}
+}
+
+func TestSendSMSActivation(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			fmt.Fprintln(w, sendSMSActivationResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.SendSMSActivation("DPFZRS9FB0D46QFTM899", ActivationMsg("Activate: <acturl>"), InstallationMsg("Install: <insturl>"))
+	if err != nil {
+		t.Errorf("Unexpected error from SendSMSActivation call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if !strings.HasPrefix(result.Response.ActivationMsg, "To activate") {
+		t.Errorf("Unexpected activation message %s", result.Response.ActivationMsg)
+	}
+	if last_request.URL.Path != "/admin/v1/phones/DPFZRS9FB0D46QFTM899/send_sms_activation" {
+		t.Errorf("Unexpected request path %s", last_request.URL.Path)
+	}
+	if last_request.Form.Get("activation_msg") != "Activate: <acturl>" {
+		t.Errorf("Unexpected activation_msg in request %s", last_request.Form.Get("activation_msg"))
+	}
+	if last_request.Form.Get("installation_msg") != "Install: <insturl>" {
+		t.Errorf("Unexpected installation_msg in request %s", last_request.Form.Get("installation_msg"))
+	}
+}
+
+const sendSMSInstallationResponse = `{
+	"stat": "OK",
+	"response": {
+		"installation_msg": "Welcome to Duo! To install the Duo Mobile app, click this link: https://m-abcdef.duosecurity.com/iphone/7dE9jCeKm8IL6P2tHYn9"
+	}
+}`
+
+func TestSendSMSInstallation(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, sendSMSInstallationResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.SendSMSInstallation("DPFZRS9FB0D46QFTM899")
+	if err != nil {
+		t.Errorf("Unexpected error from SendSMSInstallation call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if !strings.HasPrefix(result.Response.InstallationMsg, "Welcome to Duo!") {
+		t.Errorf("Unexpected installation message %s", result.Response.InstallationMsg)
+	}
+	if last_request.URL.Path != "/admin/v1/phones/DPFZRS9FB0D46QFTM899/send_sms_installation" {
+		t.Errorf("Unexpected request path %s", last_request.URL.Path)
+	}
+}
+
+func TestSendSMSPasscodes(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, deletePhoneResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.SendSMSPasscodes("DPFZRS9FB0D46QFTM899")
+	if err != nil {
+		t.Errorf("Unexpected error from SendSMSPasscodes call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if last_request.Method != http.MethodPost {
+		t.Errorf("Expected POST, but got %s", last_request.Method)
+	}
+	if last_request.URL.Path != "/admin/v1/phones/DPFZRS9FB0D46QFTM899/send_sms_passcodes" {
+		t.Errorf("Unexpected request path %s", last_request.URL.Path)
+	}
+}
+
+const getTokensResponse = `{
+	"stat": "OK",
+	"response": [{
+		"serial": "0",
+		"token_id": "DHIZ34ALBA2445ND4AI2",
+		"type": "d1",
+		"totp_step": null,
+		"users": [{
+			"user_id": "DUJZ2U4L80HT45MQ4EOQ",
+			"username": "jsmith",
+			"alias1": "joe.smith",
+			"alias2": "jsmith@example.com",
+			"realname": "Joe Smith",
+			"email": "jsmith@example.com",
+			"status": "active",
+			"last_login": 1343921403,
+			"notes": ""
+		}]
+	}],
+	"metadata": {
+		"prev_offset": null,
+		"next_offset": null,
+		"total_objects": 1
+	}
+}`
+
+func TestGetTokens(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, getTokensResponse)
 			last_request = r
 		}),
 	)
@@ -2130,6 +2662,122 @@ func TestGetToken(t *testing.T) {
 	}
 }
 
+func TestTokenCreate_URLValues(t *testing.T) {
+	counter := uint64(0)
+	token := TokenCreate{
+		Type:    TokenTypeHOTP6,
+		Serial:  "0",
+		Secret:  "3132333435363738393031323334353637383930",
+		Counter: &counter,
+	}
+	want := url.Values{
+		"type":    []string{"h6"},
+		"serial":  []string{"0"},
+		"secret":  []string{"3132333435363738393031323334353637383930"},
+		"counter": []string{"0"},
+	}
+	if got := token.URLValues(); !reflect.DeepEqual(got, want) {
+		t.Errorf("TokenCreate.URLValues() = %v, want %v", got, want)
+	}
+}
+
+func TestCreateToken(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			fmt.Fprintln(w, getTokenResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	step := 60
+	result, err := duo.CreateToken(TokenCreate{
+		Type:     TokenTypeTOTP6,
+		Serial:   "0",
+		Secret:   "3132333435363738393031323334353637383930",
+		TOTPStep: &step,
+	})
+	if err != nil {
+		t.Errorf("Unexpected error from CreateToken call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if result.Response.TokenID != "DHIZ34ALBA2445ND4AI2" {
+		t.Errorf("Expected token ID DHIZ34ALBA2445ND4AI2, but got %s", result.Response.TokenID)
+	}
+	if last_request.Method != http.MethodPost || last_request.URL.Path != "/admin/v1/tokens" {
+		t.Errorf("Unexpected request %s %s", last_request.Method, last_request.URL.Path)
+	}
+	if last_request.Form.Get("type") != "t6" {
+		t.Errorf("Expected type t6 in request, but got %s", last_request.Form.Get("type"))
+	}
+	if last_request.Form.Get("totp_step") != "60" {
+		t.Errorf("Expected totp_step 60 in request, but got %s", last_request.Form.Get("totp_step"))
+	}
+	if _, ok := last_request.Form["counter"]; ok {
+		t.Errorf("Expected counter to be omitted from request")
+	}
+}
+
+func TestResyncToken(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			fmt.Fprintln(w, associateUserTokenResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.ResyncToken("DHIZ34ALBA2445ND4AI2", "123456", "234567", "345678")
+	if err != nil {
+		t.Errorf("Unexpected error from ResyncToken call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if last_request.URL.Path != "/admin/v1/tokens/DHIZ34ALBA2445ND4AI2/resync" {
+		t.Errorf("Unexpected request path %s", last_request.URL.Path)
+	}
+	for key, code := range map[string]string{"code1": "123456", "code2": "234567", "code3": "345678"} {
+		if last_request.Form.Get(key) != code {
+			t.Errorf("Expected %s of %s in request, but got %s", key, code, last_request.Form.Get(key))
+		}
+	}
+}
+
+func TestDeleteToken(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, associateUserTokenResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.DeleteToken("DHIZ34ALBA2445ND4AI2")
+	if err != nil {
+		t.Errorf("Unexpected error from DeleteToken call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if last_request.Method != http.MethodDelete || last_request.URL.Path != "/admin/v1/tokens/DHIZ34ALBA2445ND4AI2" {
+		t.Errorf("Unexpected request %s %s", last_request.Method, last_request.URL.Path)
+	}
+}
+
 const getU2FTokensResponse = `{
 	"stat": "OK",
 	"response": [{
@@ -2322,10 +2970,35 @@ func TestGetU2FTokensPageArgs(t *testing.T) {
 	}
 }
 
+const getU2FTokenResponse = `{
+	"stat": "OK",
+	"response": {
+		"date_added": 1444678994,
+		"registration_id": "D21RU6X1B1DF5P54B6PV",
+		"user": {
+			"alias1": "joe.smith",
+			"alias2": "jsmith@example.com",
+			"alias3": null,
+			"alias4": null,
+			"created": 1384275337,
+			"email": "jsmith@example.com",
+			"firstname": "Joe",
+			"last_directory_sync": 1384275337,
+			"last_login": 1514922986,
+			"lastname": "Smith",
+			"notes": "",
+			"realname": "Joe Smith",
+			"status": "active",
+			"user_id": "DU3RP9I2WOC59VZX672N",
+			"username": "jsmith"
+		}
+	}
+}`
+
 func TestGetU2FToken(t *testing.T) {
 	ts := httptest.NewTLSServer(
 		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			fmt.Fprintln(w, getU2FTokensResponse)
+			fmt.Fprintln(w, getU2FTokenResponse)
 		}),
 	)
 	defer ts.Close()
@@ -2339,11 +3012,283 @@ func TestGetU2FToken(t *testing.T) {
 	if result.Stat != "OK" {
 		t.Errorf("Expected OK, but got %s", result.Stat)
 	}
+	if result.Response.RegistrationID != "D21RU6X1B1DF5P54B6PV" {
+		t.Errorf("Expected registration ID D21RU6X1B1DF5P54B6PV, but got %s", result.Response.RegistrationID)
+	}
+	if result.Response.User == nil || result.Response.User.Username != "jsmith" {
+		t.Errorf("Expected user jsmith, but got %v", result.Response.User)
+	}
+}
+
+func TestDeleteU2FToken(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, deleteUserResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.DeleteU2FToken("D21RU6X1B1DF5P54B6PV")
+	if err != nil {
+		t.Errorf("Unexpected error from DeleteU2FToken call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if last_request.Method != http.MethodDelete || last_request.URL.Path != "/admin/v1/u2ftokens/D21RU6X1B1DF5P54B6PV" {
+		t.Errorf("Unexpected request %s %s", last_request.Method, last_request.URL.Path)
+	}
+}
+
+const getWebAuthnCredentialsResponse = `{
+	"stat": "OK",
+	"response": [{
+		"credential_name": "Touch ID",
+		"date_added": 1550685154,
+		"label": "Touch ID",
+		"user": {
+			"email": "jsmith@example.com",
+			"user_id": "DU3RP9I2WOC59VZX672N",
+			"username": "jsmith"
+		},
+		"webauthnkey": "WABFEOE007ZMV1QAZTRB"
+	}],
+	"metadata": {
+		"prev_offset": null,
+		"next_offset": null,
+		"total_objects": 1
+	}
+}`
+
+const getWebAuthnCredentialsPage1Response = `{
+	"stat": "OK",
+	"response": [{
+		"credential_name": "Touch ID",
+		"label": "Touch ID",
+		"webauthnkey": "WABFEOE007ZMV1QAZTRB"
+	}],
+	"metadata": {
+		"prev_offset": null,
+		"next_offset": 1,
+		"total_objects": 2
+	}
+}`
+
+const getWebAuthnCredentialsPage2Response = `{
+	"stat": "OK",
+	"response": [{
+		"credential_name": "YubiKey",
+		"label": "Security key",
+		"webauthnkey": "WAXXXXXXXXXXXXXXXXX2"
+	}],
+	"metadata": {
+		"prev_offset": 0,
+		"next_offset": null,
+		"total_objects": 2
+	}
+}`
+
+func TestGetWebAuthnCredentials(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, getWebAuthnCredentialsResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.GetWebAuthnCredentials()
+	if err != nil {
+		t.Errorf("Unexpected error from GetWebAuthnCredentials call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
 	if len(result.Response) != 1 {
-		t.Errorf("Expected 1 token, but got %d", len(result.Response))
+		t.Errorf("Expected 1 credential, but got %d", len(result.Response))
 	}
-	if result.Response[0].RegistrationID != "D21RU6X1B1DF5P54B6PV" {
-		t.Errorf("Expected registration ID D21RU6X1B1DF5P54B6PV, but got %s", result.Response[0].RegistrationID)
+	if result.Response[0].WebAuthnKey != "WABFEOE007ZMV1QAZTRB" {
+		t.Errorf("Expected key WABFEOE007ZMV1QAZTRB, but got %s", result.Response[0].WebAuthnKey)
+	}
+	if result.Response[0].User == nil || result.Response[0].User.UserID != "DU3RP9I2WOC59VZX672N" {
+		t.Errorf("Expected user DU3RP9I2WOC59VZX672N, but got %v", result.Response[0].User)
+	}
+	if last_request.URL.Path != "/admin/v1/webauthncredentials" {
+		t.Errorf("Unexpected request path %s", last_request.URL.Path)
+	}
+
+	request_query := last_request.URL.Query()
+	if request_query["limit"][0] != "100" {
+		t.Errorf("Expected to see a limit of 100 in request, bug got %s", request_query["limit"])
+	}
+	if request_query["offset"][0] != "0" {
+		t.Errorf("Expected to see an offset of 0 in request, bug got %s", request_query["offset"])
+	}
+}
+
+func TestGetWebAuthnCredentialsMultipage(t *testing.T) {
+	requests := []*http.Request{}
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(requests) == 0 {
+				fmt.Fprintln(w, getWebAuthnCredentialsPage1Response)
+			} else {
+				fmt.Fprintln(w, getWebAuthnCredentialsPage2Response)
+			}
+			requests = append(requests, r)
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.GetWebAuthnCredentials()
+
+	if len(requests) != 2 {
+		t.Errorf("Expected two requets, found %d", len(requests))
+	}
+
+	if len(result.Response) != 2 {
+		t.Errorf("Expected two credentials in the response, found %d", len(result.Response))
+	}
+
+	if err != nil {
+		t.Errorf("Expected err to be nil, found %s", err)
+	}
+}
+
+func TestGetUserWebAuthnCredentials(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, getWebAuthnCredentialsResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.GetUserWebAuthnCredentials("DU3RP9I2WOC59VZX672N")
+	if err != nil {
+		t.Errorf("Unexpected error from GetUserWebAuthnCredentials call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if len(result.Response) != 1 {
+		t.Errorf("Expected 1 credential, but got %d", len(result.Response))
+	}
+	if last_request.URL.Path != "/admin/v1/users/DU3RP9I2WOC59VZX672N/webauthncredentials" {
+		t.Errorf("Unexpected request path %s", last_request.URL.Path)
+	}
+}
+
+func TestGetUserWebAuthnCredentialsPageArgs(t *testing.T) {
+	requests := []*http.Request{}
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, getEmptyPageArgsResponse)
+			requests = append(requests, r)
+		}),
+	)
+
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	_, err := duo.GetUserWebAuthnCredentials("DU3RP9I2WOC59VZX672N", func(values *url.Values) {
+		values.Set("limit", "200")
+		values.Set("offset", "1")
+		return
+	})
+
+	if err != nil {
+		t.Errorf("Encountered unexpected error: %s", err)
+	}
+
+	if len(requests) != 1 {
+		t.Errorf("Expected there to be one request, found %d", len(requests))
+	}
+	request_query := requests[0].URL.Query()
+	if request_query["limit"][0] != "200" {
+		t.Errorf("Expected to see a limit of 200 in request, bug got %s", request_query["limit"])
+	}
+	if request_query["offset"][0] != "1" {
+		t.Errorf("Expected to see an offset of 1 in request, bug got %s", request_query["offset"])
+	}
+}
+
+const getWebAuthnCredentialResponse = `{
+	"stat": "OK",
+	"response": {
+		"credential_name": "Touch ID",
+		"date_added": 1550685154,
+		"label": "Touch ID",
+		"user": {
+			"email": "jsmith@example.com",
+			"user_id": "DU3RP9I2WOC59VZX672N",
+			"username": "jsmith"
+		},
+		"webauthnkey": "WABFEOE007ZMV1QAZTRB"
+	}
+}`
+
+func TestGetWebAuthnCredential(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, getWebAuthnCredentialResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.GetWebAuthnCredential("WABFEOE007ZMV1QAZTRB")
+	if err != nil {
+		t.Errorf("Unexpected error from GetWebAuthnCredential call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if result.Response.DateAdded != 1550685154 {
+		t.Errorf("Expected date added 1550685154, but got %d", result.Response.DateAdded)
+	}
+	if last_request.URL.Path != "/admin/v1/webauthncredentials/WABFEOE007ZMV1QAZTRB" {
+		t.Errorf("Unexpected request path %s", last_request.URL.Path)
+	}
+}
+
+func TestDeleteWebAuthnCredential(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, deleteUserResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.DeleteWebAuthnCredential("WABFEOE007ZMV1QAZTRB")
+	if err != nil {
+		t.Errorf("Unexpected error from DeleteWebAuthnCredential call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if last_request.Method != http.MethodDelete || last_request.URL.Path != "/admin/v1/webauthncredentials/WABFEOE007ZMV1QAZTRB" {
+		t.Errorf("Unexpected request %s %s", last_request.Method, last_request.URL.Path)
 	}
 }
 
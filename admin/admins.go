@@ -0,0 +1,418 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	duoapi "github.com/duosecurity/duo_api_golang"
+)
+
+// Administrator roles.
+const (
+	AdminRoleOwner              = "Owner"
+	AdminRoleAdministrator      = "Administrator"
+	AdminRoleApplicationManager = "Application Manager"
+	AdminRoleUserManager        = "User Manager"
+	AdminRoleSecurityAnalyst    = "Security Analyst"
+	AdminRoleHelpDesk           = "Help Desk"
+	AdminRoleBilling            = "Billing"
+	AdminRoleReadOnly           = "Read-only"
+)
+
+// Admin models an administrator with access to the Duo Admin Panel.
+type Admin struct {
+	AdminID string `json:"admin_id"`
+	// AdminUnits lists the IDs of the administrative units the admin is
+	// assigned to.
+	AdminUnits             []string `json:"admin_units"`
+	Created                uint64   `json:"created"`
+	Email                  string   `json:"email"`
+	HardToken              *Token   `json:"hardtoken"`
+	LastDirectorySync      *uint64  `json:"last_directory_sync"`
+	LastLogin              *uint64  `json:"last_login"`
+	Name                   string   `json:"name"`
+	PasswordChangeRequired bool     `json:"password_change_required"`
+	Phone                  string   `json:"phone"`
+	// RestrictedByAdminUnits is true when the admin can only manage the
+	// users and groups of their AdminUnits.
+	RestrictedByAdminUnits bool   `json:"restricted_by_admin_units"`
+	Role                   string `json:"role"`
+	Status                 string `json:"status"`
+}
+
+// AdminUpdate holds the fields of an administrator to set with CreateAdmin or
+// ModifyAdmin. Nil fields are left unchanged. Email can only be set on
+// creation, and Status only by ModifyAdmin.
+type AdminUpdate struct {
+	Email                  *string `url:"email"`
+	Name                   *string `url:"name"`
+	Phone                  *string `url:"phone"`
+	Role                   *string `url:"role"`
+	RestrictedByAdminUnits *bool   `url:"restricted_by_admin_units"`
+	// Status is "Active" or "Disabled".
+	Status                 *string `url:"status"`
+	PasswordChangeRequired *bool   `url:"password_change_required"`
+	// TokenID is the hardware token to assign, or "" to remove it.
+	TokenID *string `url:"token_id"`
+}
+
+// URLValues transforms an AdminUpdate into url.Values, skipping nil fields.
+func (a *AdminUpdate) URLValues() url.Values {
+	return structURLValues(a)
+}
+
+// GetAdminsResult models responses containing a list of administrators.
+type GetAdminsResult struct {
+	duoapi.StatResult
+	ListResult
+	Response []Admin
+}
+
+func (result *GetAdminsResult) getResponse() interface{} {
+	return result.Response
+}
+
+func (result *GetAdminsResult) appendResponse(admins interface{}) {
+	asserted_admins := admins.([]Admin)
+	result.Response = append(result.Response, asserted_admins...)
+}
+
+// GetAdmins calls GET /admin/v1/admins
+// See https://duo.com/docs/adminapi#retrieve-administrators
+func (c *Client) GetAdmins(options ...func(*url.Values)) (*GetAdminsResult, error) {
+	params := url.Values{}
+	for _, o := range options {
+		o(&params)
+	}
+
+	cb := func(params url.Values) (responsePage, error) {
+		return c.retrieveAdmins(params)
+	}
+	response, err := c.retrieveItems(params, cb)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.(*GetAdminsResult), nil
+}
+
+func (c *Client) retrieveAdmins(params url.Values) (*GetAdminsResult, error) {
+	_, body, err := c.SignedCall(http.MethodGet, "/admin/v1/admins", params, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GetAdminsResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetAdminResult models responses containing a single administrator.
+type GetAdminResult struct {
+	duoapi.StatResult
+	Response Admin
+}
+
+// GetAdmin calls GET /admin/v1/admins/:admin_id
+// See https://duo.com/docs/adminapi#retrieve-administrator-by-id
+func (c *Client) GetAdmin(adminID string) (*GetAdminResult, error) {
+	path := fmt.Sprintf("/admin/v1/admins/%s", adminID)
+
+	_, body, err := c.SignedCall(http.MethodGet, path, nil, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GetAdminResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CreateAdmin calls POST /admin/v1/admins
+// The admin's Email and Name are required.
+// See https://duo.com/docs/adminapi#create-administrator
+func (c *Client) CreateAdmin(admin AdminUpdate) (*GetAdminResult, error) {
+	_, body, err := c.SignedCall(http.MethodPost, "/admin/v1/admins", admin.URLValues(), duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GetAdminResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ModifyAdmin calls POST /admin/v1/admins/:admin_id
+// See https://duo.com/docs/adminapi#modify-administrator
+func (c *Client) ModifyAdmin(adminID string, update AdminUpdate) (*GetAdminResult, error) {
+	path := fmt.Sprintf("/admin/v1/admins/%s", adminID)
+
+	_, body, err := c.SignedCall(http.MethodPost, path, update.URLValues(), duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GetAdminResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteAdmin calls DELETE /admin/v1/admins/:admin_id
+// See https://duo.com/docs/adminapi#delete-administrator
+func (c *Client) DeleteAdmin(adminID string) (*duoapi.StatResult, error) {
+	path := fmt.Sprintf("/admin/v1/admins/%s", adminID)
+
+	_, body, err := c.SignedCall(http.MethodDelete, path, nil, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &duoapi.StatResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ResetAdminAuthAttempts calls POST /admin/v1/admins/:admin_id/reset
+// See https://duo.com/docs/adminapi#reset-administrator-authentication-attempts
+func (c *Client) ResetAdminAuthAttempts(adminID string) (*duoapi.StatResult, error) {
+	path := fmt.Sprintf("/admin/v1/admins/%s/reset", adminID)
+
+	_, body, err := c.SignedCall(http.MethodPost, path, nil, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &duoapi.StatResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Admin activation methods
+
+// AdminActivationLink models the activation link of an administrator who has
+// not yet completed activation.
+type AdminActivationLink struct {
+	AdminID   string `json:"admin_id"`
+	Email     string `json:"email"`
+	EmailSent bool   `json:"email_sent"`
+	Link      string `json:"link"`
+	Message   string `json:"message"`
+	Role      string `json:"role"`
+	Subject   string `json:"subject"`
+	ValidDays int    `json:"valid_days"`
+}
+
+// AdminActivationLinkResult models responses containing an admin activation link.
+type AdminActivationLinkResult struct {
+	duoapi.StatResult
+	Response AdminActivationLink
+}
+
+// CreateAdminActivationLink calls POST /admin/v1/admins/:admin_id/activation_link
+// See https://duo.com/docs/adminapi#create-existing-administrator-activation-link
+func (c *Client) CreateAdminActivationLink(adminID string) (*AdminActivationLinkResult, error) {
+	path := fmt.Sprintf("/admin/v1/admins/%s/activation_link", adminID)
+
+	_, body, err := c.SignedCall(http.MethodPost, path, nil, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AdminActivationLinkResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// EmailAdminActivationLink calls POST /admin/v1/admins/:admin_id/activation_link/email
+// See https://duo.com/docs/adminapi#email-activation-link-to-existing-administrator
+func (c *Client) EmailAdminActivationLink(adminID string) (*AdminActivationLinkResult, error) {
+	path := fmt.Sprintf("/admin/v1/admins/%s/activation_link/email", adminID)
+
+	_, body, err := c.SignedCall(http.MethodPost, path, nil, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AdminActivationLinkResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteAdminActivationLink calls DELETE /admin/v1/admins/:admin_id/activation_link
+// See https://duo.com/docs/adminapi#delete-existing-administrator-activation-link
+func (c *Client) DeleteAdminActivationLink(adminID string) (*duoapi.StatResult, error) {
+	path := fmt.Sprintf("/admin/v1/admins/%s/activation_link", adminID)
+
+	_, body, err := c.SignedCall(http.MethodDelete, path, nil, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &duoapi.StatResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// AdminActivation models a pending administrator activation.
+type AdminActivation struct {
+	AdminActivationID string `json:"admin_activation_id"`
+	Email             string `json:"email"`
+	EmailSent         bool   `json:"email_sent"`
+	Expires           uint64 `json:"expires"`
+	Link              string `json:"link"`
+	Message           string `json:"message"`
+	Role              string `json:"role"`
+	Subject           string `json:"subject"`
+	ValidDays         int    `json:"valid_days"`
+}
+
+// AdminActivationResult models responses containing a single administrator
+// activation.
+type AdminActivationResult struct {
+	duoapi.StatResult
+	Response AdminActivation
+}
+
+// AdminActivationSendEmail sets the optional send_email parameter for
+// CreateAdminActivation requests, so that the activation link is emailed to
+// the new administrator.
+func AdminActivationSendEmail() func(*url.Values) {
+	return func(opts *url.Values) {
+		opts.Set("send_email", "1")
+	}
+}
+
+// AdminActivationValidDays sets the optional valid_days parameter for
+// CreateAdminActivation requests: the number of days the activation link is
+// valid. The default is 7.
+func AdminActivationValidDays(days int) func(*url.Values) {
+	return func(opts *url.Values) {
+		opts.Set("valid_days", strconv.Itoa(days))
+	}
+}
+
+// CreateAdminActivation calls POST /admin/v1/admins/activations
+// It creates an activation link for a new administrator with the given email
+// and role; the administrator is only created once the link is used. An empty
+// role leaves Duo's default, Owner.
+// See https://duo.com/docs/adminapi#create-administrator-activation-link
+func (c *Client) CreateAdminActivation(email, role string, options ...func(*url.Values)) (*AdminActivationResult, error) {
+	params := url.Values{}
+	params.Set("email", email)
+	if role != "" {
+		params.Set("admin_role", role)
+	}
+	for _, o := range options {
+		o(&params)
+	}
+
+	_, body, err := c.SignedCall(http.MethodPost, "/admin/v1/admins/activations", params, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AdminActivationResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetAdminActivationsResult models responses containing a list of pending
+// administrator activations.
+type GetAdminActivationsResult struct {
+	duoapi.StatResult
+	ListResult
+	Response []AdminActivation
+}
+
+func (result *GetAdminActivationsResult) getResponse() interface{} {
+	return result.Response
+}
+
+func (result *GetAdminActivationsResult) appendResponse(activations interface{}) {
+	asserted_activations := activations.([]AdminActivation)
+	result.Response = append(result.Response, asserted_activations...)
+}
+
+// GetAdminActivations calls GET /admin/v1/admins/activations
+// See https://duo.com/docs/adminapi#retrieve-pending-administrator-activations
+func (c *Client) GetAdminActivations(options ...func(*url.Values)) (*GetAdminActivationsResult, error) {
+	params := url.Values{}
+	for _, o := range options {
+		o(&params)
+	}
+
+	cb := func(params url.Values) (responsePage, error) {
+		return c.retrieveAdminActivations(params)
+	}
+	response, err := c.retrieveItems(params, cb)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.(*GetAdminActivationsResult), nil
+}
+
+func (c *Client) retrieveAdminActivations(params url.Values) (*GetAdminActivationsResult, error) {
+	_, body, err := c.SignedCall(http.MethodGet, "/admin/v1/admins/activations", params, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GetAdminActivationsResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteAdminActivation calls DELETE /admin/v1/admins/activations/:admin_activation_id
+// See https://duo.com/docs/adminapi#delete-pending-administrator-activation
+func (c *Client) DeleteAdminActivation(activationID string) (*duoapi.StatResult, error) {
+	path := fmt.Sprintf("/admin/v1/admins/activations/%s", activationID)
+
+	_, body, err := c.SignedCall(http.MethodDelete, path, nil, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &duoapi.StatResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,545 @@
+package admin
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+const getAdminsResponse = `{
+	"stat": "OK",
+	"metadata": {
+		"total_objects": 2
+	},
+	"response": [{
+		"admin_id": "DELOY0CORL3BFR7ZO8T1",
+		"admin_units": [],
+		"created": 1489612729,
+		"email": "jsmith@example.com",
+		"hardtoken": null,
+		"last_directory_sync": null,
+		"last_login": 1343921403,
+		"name": "Joe Smith",
+		"password_change_required": false,
+		"phone": "+17345551000",
+		"restricted_by_admin_units": false,
+		"role": "Owner",
+		"status": "Active"
+	},
+	{
+		"admin_id": "DE9SDBXB1JCHB1HWIE9N",
+		"admin_units": ["DHEE6JP5W4NTQ3W6YRHK"],
+		"created": 1489612729,
+		"email": "ejones@example.com",
+		"hardtoken": {
+			"serial": "0",
+			"token_id": "DHIZ34ALBA2445ND4AI2",
+			"type": "d1"
+		},
+		"last_directory_sync": null,
+		"last_login": null,
+		"name": "Emily Jones",
+		"password_change_required": true,
+		"phone": "",
+		"restricted_by_admin_units": true,
+		"role": "Help Desk",
+		"status": "Pending Activation"
+	}]
+}`
+
+const getAdminsPage1Response = `{
+	"stat": "OK",
+	"metadata": {
+		"next_offset": 1,
+		"total_objects": 2
+	},
+	"response": [{
+		"admin_id": "DELOY0CORL3BFR7ZO8T1",
+		"name": "Joe Smith"
+	}]
+}`
+
+const getAdminsPage2Response = `{
+	"stat": "OK",
+	"metadata": {
+		"prev_offset": 0,
+		"total_objects": 2
+	},
+	"response": [{
+		"admin_id": "DE9SDBXB1JCHB1HWIE9N",
+		"name": "Emily Jones"
+	}]
+}`
+
+func TestGetAdmins(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, getAdminsResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.GetAdmins()
+	if err != nil {
+		t.Errorf("Unexpected error from GetAdmins call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if len(result.Response) != 2 {
+		t.Fatalf("Expected 2 admins, but got %d", len(result.Response))
+	}
+	owner := result.Response[0]
+	if owner.Role != AdminRoleOwner || owner.HardToken != nil || owner.LastLogin == nil {
+		t.Errorf("Unexpected first admin %+v", owner)
+	}
+	helpdesk := result.Response[1]
+	if helpdesk.Role != AdminRoleHelpDesk || !helpdesk.RestrictedByAdminUnits {
+		t.Errorf("Expected a restricted help desk admin, but got %+v", helpdesk)
+	}
+	if !reflect.DeepEqual(helpdesk.AdminUnits, []string{"DHEE6JP5W4NTQ3W6YRHK"}) {
+		t.Errorf("Unexpected admin units %v", helpdesk.AdminUnits)
+	}
+	if helpdesk.HardToken == nil || helpdesk.HardToken.TokenID != "DHIZ34ALBA2445ND4AI2" {
+		t.Errorf("Unexpected hardware token %v", helpdesk.HardToken)
+	}
+	if last_request.URL.Path != "/admin/v1/admins" {
+		t.Errorf("Unexpected request path %s", last_request.URL.Path)
+	}
+
+	request_query := last_request.URL.Query()
+	if request_query["limit"][0] != "100" {
+		t.Errorf("Expected to see a limit of 100 in request, bug got %s", request_query["limit"])
+	}
+	if request_query["offset"][0] != "0" {
+		t.Errorf("Expected to see an offset of 0 in request, bug got %s", request_query["offset"])
+	}
+}
+
+func TestGetAdminsMultipage(t *testing.T) {
+	requests := []*http.Request{}
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(requests) == 0 {
+				fmt.Fprintln(w, getAdminsPage1Response)
+			} else {
+				fmt.Fprintln(w, getAdminsPage2Response)
+			}
+			requests = append(requests, r)
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.GetAdmins()
+
+	if len(requests) != 2 {
+		t.Errorf("Expected two requets, found %d", len(requests))
+	}
+
+	if len(result.Response) != 2 {
+		t.Errorf("Expected two admins in the response, found %d", len(result.Response))
+	}
+
+	if err != nil {
+		t.Errorf("Expected err to be nil, found %s", err)
+	}
+}
+
+const getAdminResponse = `{
+	"stat": "OK",
+	"response": {
+		"admin_id": "DELOY0CORL3BFR7ZO8T1",
+		"admin_units": [],
+		"created": 1489612729,
+		"email": "jsmith@example.com",
+		"hardtoken": null,
+		"last_directory_sync": null,
+		"last_login": 1343921403,
+		"name": "Joe Smith",
+		"password_change_required": false,
+		"phone": "+17345551000",
+		"restricted_by_admin_units": false,
+		"role": "Owner",
+		"status": "Active"
+	}
+}`
+
+func TestGetAdmin(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, getAdminResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.GetAdmin("DELOY0CORL3BFR7ZO8T1")
+	if err != nil {
+		t.Errorf("Unexpected error from GetAdmin call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if result.Response.Email != "jsmith@example.com" {
+		t.Errorf("Expected email jsmith@example.com, but got %s", result.Response.Email)
+	}
+	if last_request.Method != http.MethodGet || last_request.URL.Path != "/admin/v1/admins/DELOY0CORL3BFR7ZO8T1" {
+		t.Errorf("Unexpected request %s %s", last_request.Method, last_request.URL.Path)
+	}
+}
+
+func TestAdminUpdate_URLValues(t *testing.T) {
+	name := "Joe Smith"
+	restricted := false
+	update := AdminUpdate{Name: &name, RestrictedByAdminUnits: &restricted}
+	want := url.Values{
+		"name":                      []string{"Joe Smith"},
+		"restricted_by_admin_units": []string{"false"},
+	}
+	if got := update.URLValues(); !reflect.DeepEqual(got, want) {
+		t.Errorf("AdminUpdate.URLValues() = %v, want %v", got, want)
+	}
+}
+
+func TestCreateAdmin(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			fmt.Fprintln(w, getAdminResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	email := "jsmith@example.com"
+	name := "Joe Smith"
+	role := AdminRoleOwner
+	result, err := duo.CreateAdmin(AdminUpdate{Email: &email, Name: &name, Role: &role})
+	if err != nil {
+		t.Errorf("Unexpected error from CreateAdmin call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if result.Response.AdminID != "DELOY0CORL3BFR7ZO8T1" {
+		t.Errorf("Expected admin ID DELOY0CORL3BFR7ZO8T1, but got %s", result.Response.AdminID)
+	}
+	if last_request.Method != http.MethodPost || last_request.URL.Path != "/admin/v1/admins" {
+		t.Errorf("Unexpected request %s %s", last_request.Method, last_request.URL.Path)
+	}
+	if last_request.Form.Get("email") != email || last_request.Form.Get("name") != name || last_request.Form.Get("role") != role {
+		t.Errorf("Unexpected request parameters %v", last_request.Form)
+	}
+}
+
+func TestModifyAdmin(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			fmt.Fprintln(w, getAdminResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	status := "Disabled"
+	result, err := duo.ModifyAdmin("DELOY0CORL3BFR7ZO8T1", AdminUpdate{Status: &status})
+	if err != nil {
+		t.Errorf("Unexpected error from ModifyAdmin call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if last_request.URL.Path != "/admin/v1/admins/DELOY0CORL3BFR7ZO8T1" {
+		t.Errorf("Unexpected request path %s", last_request.URL.Path)
+	}
+	if len(last_request.Form) != 1 || last_request.Form.Get("status") != status {
+		t.Errorf("Expected only status in request, but got %v", last_request.Form)
+	}
+}
+
+func TestDeleteAdmin(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, deleteUserResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.DeleteAdmin("DELOY0CORL3BFR7ZO8T1")
+	if err != nil {
+		t.Errorf("Unexpected error from DeleteAdmin call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if last_request.Method != http.MethodDelete || last_request.URL.Path != "/admin/v1/admins/DELOY0CORL3BFR7ZO8T1" {
+		t.Errorf("Unexpected request %s %s", last_request.Method, last_request.URL.Path)
+	}
+}
+
+func TestResetAdminAuthAttempts(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, deleteUserResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.ResetAdminAuthAttempts("DELOY0CORL3BFR7ZO8T1")
+	if err != nil {
+		t.Errorf("Unexpected error from ResetAdminAuthAttempts call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if last_request.Method != http.MethodPost || last_request.URL.Path != "/admin/v1/admins/DELOY0CORL3BFR7ZO8T1/reset" {
+		t.Errorf("Unexpected request %s %s", last_request.Method, last_request.URL.Path)
+	}
+}
+
+const adminActivationLinkResponse = `{
+	"stat": "OK",
+	"response": {
+		"admin_id": "DELOY0CORL3BFR7ZO8T1",
+		"email": "ejones@example.com",
+		"email_sent": true,
+		"link": "https://admin-abcd1234.duosecurity.com/admins/activate/ABCDEF",
+		"message": "Hello, Emily. You have been invited to use the Duo Admin Panel.",
+		"role": "Help Desk",
+		"subject": "Duo Admin Panel activation",
+		"valid_days": 7
+	}
+}`
+
+func TestCreateAdminActivationLink(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, adminActivationLinkResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.CreateAdminActivationLink("DELOY0CORL3BFR7ZO8T1")
+	if err != nil {
+		t.Errorf("Unexpected error from CreateAdminActivationLink call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if result.Response.Link != "https://admin-abcd1234.duosecurity.com/admins/activate/ABCDEF" {
+		t.Errorf("Unexpected activation link %s", result.Response.Link)
+	}
+	if result.Response.ValidDays != 7 {
+		t.Errorf("Expected 7 valid days, but got %d", result.Response.ValidDays)
+	}
+	if last_request.Method != http.MethodPost || last_request.URL.Path != "/admin/v1/admins/DELOY0CORL3BFR7ZO8T1/activation_link" {
+		t.Errorf("Unexpected request %s %s", last_request.Method, last_request.URL.Path)
+	}
+}
+
+func TestEmailAdminActivationLink(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, adminActivationLinkResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.EmailAdminActivationLink("DELOY0CORL3BFR7ZO8T1")
+	if err != nil {
+		t.Errorf("Unexpected error from EmailAdminActivationLink call %v", err.Error())
+	}
+	if !result.Response.EmailSent {
+		t.Errorf("Expected the email to be sent")
+	}
+	if last_request.Method != http.MethodPost || last_request.URL.Path != "/admin/v1/admins/DELOY0CORL3BFR7ZO8T1/activation_link/email" {
+		t.Errorf("Unexpected request %s %s", last_request.Method, last_request.URL.Path)
+	}
+}
+
+func TestDeleteAdminActivationLink(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, deleteUserResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.DeleteAdminActivationLink("DELOY0CORL3BFR7ZO8T1")
+	if err != nil {
+		t.Errorf("Unexpected error from DeleteAdminActivationLink call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if last_request.Method != http.MethodDelete || last_request.URL.Path != "/admin/v1/admins/DELOY0CORL3BFR7ZO8T1/activation_link" {
+		t.Errorf("Unexpected request %s %s", last_request.Method, last_request.URL.Path)
+	}
+}
+
+const createAdminActivationResponse = `{
+	"stat": "OK",
+	"response": {
+		"admin_activation_id": "DEEHI4Z6QIXTC1K0ZA4D",
+		"email": "ejones@example.com",
+		"email_sent": true,
+		"expires": 1580847700,
+		"link": "https://admin-abcd1234.duosecurity.com/admins/activate/ABCDEF",
+		"message": "Hello, Emily. You have been invited to use the Duo Admin Panel.",
+		"role": "Help Desk",
+		"subject": "Duo Admin Panel activation",
+		"valid_days": 3
+	}
+}`
+
+func TestCreateAdminActivation(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			fmt.Fprintln(w, createAdminActivationResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.CreateAdminActivation("ejones@example.com", AdminRoleHelpDesk,
+		AdminActivationSendEmail(), AdminActivationValidDays(3))
+	if err != nil {
+		t.Fatalf("Unexpected error from CreateAdminActivation call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if result.Response.AdminActivationID != "DEEHI4Z6QIXTC1K0ZA4D" || !result.Response.EmailSent || result.Response.ValidDays != 3 {
+		t.Errorf("Unexpected activation %+v", result.Response)
+	}
+	if last_request.Method != http.MethodPost || last_request.URL.Path != "/admin/v1/admins/activations" {
+		t.Errorf("Unexpected request %s %s", last_request.Method, last_request.URL.Path)
+	}
+	expected := url.Values{
+		"email":      {"ejones@example.com"},
+		"admin_role": {"Help Desk"},
+		"send_email": {"1"},
+		"valid_days": {"3"},
+	}
+	if !reflect.DeepEqual(last_request.Form, expected) {
+		t.Errorf("Expected %v, but got %v", expected, last_request.Form)
+	}
+}
+
+const getAdminActivationsResponse = `{
+	"stat": "OK",
+	"metadata": {
+		"total_objects": 1
+	},
+	"response": [{
+		"admin_activation_id": "DEEHI4Z6QIXTC1K0ZA4D",
+		"email": "ejones@example.com",
+		"email_sent": true,
+		"expires": 1580847700,
+		"link": "https://admin-abcd1234.duosecurity.com/admins/activate/ABCDEF",
+		"message": "",
+		"role": "Help Desk",
+		"subject": "",
+		"valid_days": 7
+	}]
+}`
+
+func TestGetAdminActivations(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, getAdminActivationsResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.GetAdminActivations()
+	if err != nil {
+		t.Errorf("Unexpected error from GetAdminActivations call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if len(result.Response) != 1 {
+		t.Fatalf("Expected 1 activation, but got %d", len(result.Response))
+	}
+	if result.Response[0].AdminActivationID != "DEEHI4Z6QIXTC1K0ZA4D" || result.Response[0].Expires != 1580847700 {
+		t.Errorf("Unexpected activation %+v", result.Response[0])
+	}
+	if last_request.URL.Path != "/admin/v1/admins/activations" {
+		t.Errorf("Unexpected request path %s", last_request.URL.Path)
+	}
+	if last_request.URL.Query().Get("limit") != "100" {
+		t.Errorf("Expected to see a limit of 100 in request, bug got %s", last_request.URL.Query().Get("limit"))
+	}
+}
+
+func TestDeleteAdminActivation(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, deleteUserResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.DeleteAdminActivation("DEEHI4Z6QIXTC1K0ZA4D")
+	if err != nil {
+		t.Errorf("Unexpected error from DeleteAdminActivation call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if last_request.Method != http.MethodDelete || last_request.URL.Path != "/admin/v1/admins/activations/DEEHI4Z6QIXTC1K0ZA4D" {
+		t.Errorf("Unexpected request %s %s", last_request.Method, last_request.URL.Path)
+	}
+}
@@ -0,0 +1,224 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	duoapi "github.com/duosecurity/duo_api_golang"
+)
+
+// BypassCode models the metadata of a bypass code. The code itself is only
+// ever returned when it is created.
+type BypassCode struct {
+	AdminEmail   string `json:"admin_email"`
+	BypassCodeID string `json:"bypass_code_id"`
+	Created      uint64 `json:"created"`
+	// Expiration is nil for codes that do not expire.
+	Expiration *uint64 `json:"expiration"`
+	// ReuseCount is nil for codes that can be used an unlimited number of times.
+	ReuseCount *int  `json:"reuse_count"`
+	User       *User `json:"user"`
+}
+
+// BypassCodesOptions holds the optional parameters of CreateUserBypassCodes
+// and IssueUserBypassCodes. Count and Codes cannot both be set.
+type BypassCodesOptions struct {
+	// Count is the number of codes to generate.
+	Count int `url:"count"`
+	// Codes are used instead of generating codes.
+	Codes []string
+	// ReuseCount is the number of times each code can be used, or zero for
+	// unlimited reuse.
+	ReuseCount *int `url:"reuse_count"`
+	// ValidSecs is the number of seconds the codes are valid for, or zero for
+	// codes that never expire.
+	ValidSecs *int `url:"valid_secs"`
+	// PreserveExisting keeps the user's existing codes instead of
+	// invalidating them.
+	PreserveExisting bool `url:"preserve_existing"`
+}
+
+// URLValues transforms BypassCodesOptions into url.Values, skipping unset
+// fields.
+func (o *BypassCodesOptions) URLValues() url.Values {
+	params := structURLValues(o)
+	if len(o.Codes) > 0 {
+		params.Set("codes", strings.Join(o.Codes, ","))
+	}
+	return params
+}
+
+// CreateUserBypassCodes calls POST /admin/v1/users/:user_id/bypass_codes
+// The result holds the new codes in plain text.
+// See https://duo.com/docs/adminapi#create-bypass-codes-for-user
+func (c *Client) CreateUserBypassCodes(userID string, options BypassCodesOptions) (*StringArrayResult, error) {
+	if options.Count != 0 && len(options.Codes) > 0 {
+		return nil, errors.New("bypass codes: count and codes cannot both be set")
+	}
+	return c.createUserBypassCodes(userID, options.URLValues())
+}
+
+func (c *Client) createUserBypassCodes(userID string, params url.Values) (*StringArrayResult, error) {
+	path := fmt.Sprintf("/admin/v1/users/%s/bypass_codes", userID)
+
+	_, body, err := c.SignedCall(http.MethodPost, path, params, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &StringArrayResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// BypassCodeSink receives bypass codes created by IssueUserBypassCodes, for
+// example to hand them to the user over a secure channel.
+type BypassCodeSink func(userID string, codes []string) error
+
+// IssueUserBypassCodes creates bypass codes for a user like
+// CreateUserBypassCodes, but passes the codes to sink instead of returning
+// them, so the result and any error can be logged safely.
+//
+// If sink fails, the codes have still been created; the error returned wraps
+// the sink's error.
+func (c *Client) IssueUserBypassCodes(userID string, sink BypassCodeSink, options BypassCodesOptions) (*duoapi.StatResult, error) {
+	if sink == nil {
+		return nil, errors.New("bypass codes: nil sink")
+	}
+
+	result, err := c.CreateUserBypassCodes(userID, options)
+	if err != nil {
+		return nil, err
+	}
+	if result.Stat != "OK" {
+		return &result.StatResult, nil
+	}
+
+	err = sink(userID, result.Response)
+	for i := range result.Response {
+		result.Response[i] = ""
+	}
+	if err != nil {
+		return nil, fmt.Errorf("bypass codes: delivering codes for user %s: %w", userID, err)
+	}
+	return &result.StatResult, nil
+}
+
+// GetBypassCodesResult models responses containing a list of bypass codes.
+type GetBypassCodesResult struct {
+	duoapi.StatResult
+	ListResult
+	Response []BypassCode
+}
+
+func (result *GetBypassCodesResult) getResponse() interface{} {
+	return result.Response
+}
+
+func (result *GetBypassCodesResult) appendResponse(codes interface{}) {
+	asserted_codes := codes.([]BypassCode)
+	result.Response = append(result.Response, asserted_codes...)
+}
+
+// ListUserBypassCodes calls GET /admin/v1/users/:user_id/bypass_codes
+// See https://duo.com/docs/adminapi#retrieve-bypass-codes-by-user-id
+func (c *Client) ListUserBypassCodes(userID string, options ...func(*url.Values)) (*GetBypassCodesResult, error) {
+	params := url.Values{}
+	for _, o := range options {
+		o(&params)
+	}
+
+	path := fmt.Sprintf("/admin/v1/users/%s/bypass_codes", userID)
+	cb := func(params url.Values) (responsePage, error) {
+		return c.retrieveBypassCodes(path, params)
+	}
+	response, err := c.retrieveItems(params, cb)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.(*GetBypassCodesResult), nil
+}
+
+// GetBypassCodes calls GET /admin/v1/bypass_codes
+// See https://duo.com/docs/adminapi#retrieve-bypass-codes
+func (c *Client) GetBypassCodes(options ...func(*url.Values)) (*GetBypassCodesResult, error) {
+	params := url.Values{}
+	for _, o := range options {
+		o(&params)
+	}
+
+	cb := func(params url.Values) (responsePage, error) {
+		return c.retrieveBypassCodes("/admin/v1/bypass_codes", params)
+	}
+	response, err := c.retrieveItems(params, cb)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.(*GetBypassCodesResult), nil
+}
+
+func (c *Client) retrieveBypassCodes(path string, params url.Values) (*GetBypassCodesResult, error) {
+	_, body, err := c.SignedCall(http.MethodGet, path, params, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GetBypassCodesResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetBypassCodeResult models responses containing a single bypass code.
+type GetBypassCodeResult struct {
+	duoapi.StatResult
+	Response BypassCode
+}
+
+// GetBypassCode calls GET /admin/v1/bypass_codes/:bypass_code_id
+// See https://duo.com/docs/adminapi#retrieve-bypass-code-by-id
+func (c *Client) GetBypassCode(bypassCodeID string) (*GetBypassCodeResult, error) {
+	path := fmt.Sprintf("/admin/v1/bypass_codes/%s", bypassCodeID)
+
+	_, body, err := c.SignedCall(http.MethodGet, path, nil, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GetBypassCodeResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteBypassCode calls DELETE /admin/v1/bypass_codes/:bypass_code_id
+// See https://duo.com/docs/adminapi#delete-bypass-code
+func (c *Client) DeleteBypassCode(bypassCodeID string) (*duoapi.StatResult, error) {
+	path := fmt.Sprintf("/admin/v1/bypass_codes/%s", bypassCodeID)
+
+	_, body, err := c.SignedCall(http.MethodDelete, path, nil, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &duoapi.StatResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,366 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestCreateUserBypassCodes(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			fmt.Fprintln(w, getBypassCodesResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	reuseCount, validSecs := 1, 3600
+	result, err := duo.CreateUserBypassCodes("DU3RP9I2WOC59VZX672N", BypassCodesOptions{
+		Count:            10,
+		ReuseCount:       &reuseCount,
+		ValidSecs:        &validSecs,
+		PreserveExisting: true,
+	})
+	if err != nil {
+		t.Errorf("Unexpected error from CreateUserBypassCodes call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if len(result.Response) != 10 {
+		t.Errorf("Expected 10 codes, but got %d", len(result.Response))
+	}
+	if last_request.Method != http.MethodPost || last_request.URL.Path != "/admin/v1/users/DU3RP9I2WOC59VZX672N/bypass_codes" {
+		t.Errorf("Unexpected request %s %s", last_request.Method, last_request.URL.Path)
+	}
+	want := url.Values{
+		"count":             []string{"10"},
+		"reuse_count":       []string{"1"},
+		"valid_secs":        []string{"3600"},
+		"preserve_existing": []string{"true"},
+	}
+	for key := range want {
+		if last_request.Form.Get(key) != want.Get(key) {
+			t.Errorf("Expected %s of %s in request, but got %s", key, want.Get(key), last_request.Form.Get(key))
+		}
+	}
+}
+
+func TestBypassCodesOptions(t *testing.T) {
+	options := BypassCodesOptions{Codes: []string{"123456789", "987654321"}}
+	params := options.URLValues()
+	if params.Get("codes") != "123456789,987654321" {
+		t.Errorf("Expected comma separated codes, but got %s", params.Get("codes"))
+	}
+	if len(params) != 1 {
+		t.Errorf("Expected only codes to be set, but got %v", params)
+	}
+
+	// Zero is meaningful for reuse_count and valid_secs.
+	zero := 0
+	params = (&BypassCodesOptions{ReuseCount: &zero, ValidSecs: &zero}).URLValues()
+	if params.Get("reuse_count") != "0" || params.Get("valid_secs") != "0" {
+		t.Errorf("Expected zero reuse_count and valid_secs, but got %v", params)
+	}
+}
+
+func TestCreateUserBypassCodesCountAndCodes(t *testing.T) {
+	duo := buildAdminClient("https://example.com", nil)
+
+	_, err := duo.CreateUserBypassCodes("DU3RP9I2WOC59VZX672N", BypassCodesOptions{
+		Count: 2,
+		Codes: []string{"123456789", "987654321"},
+	})
+	if err == nil {
+		t.Error("Expected an error when both count and codes are set")
+	}
+}
+
+func TestIssueUserBypassCodes(t *testing.T) {
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, getBypassCodesResponse)
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	var delivered []string
+	sink := func(userID string, codes []string) error {
+		if userID != "DU3RP9I2WOC59VZX672N" {
+			t.Errorf("Unexpected user ID %s", userID)
+		}
+		delivered = append(delivered, codes...)
+		return nil
+	}
+	result, err := duo.IssueUserBypassCodes("DU3RP9I2WOC59VZX672N", sink, BypassCodesOptions{Count: 10})
+	if err != nil {
+		t.Errorf("Unexpected error from IssueUserBypassCodes call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if len(delivered) != 10 || delivered[0] != "407176182" {
+		t.Errorf("Expected 10 codes to be delivered, but got %v", delivered)
+	}
+	if strings.Contains(fmt.Sprintf("%+v", result), "407176182") {
+		t.Errorf("Expected the result not to contain any codes, but got %+v", result)
+	}
+}
+
+func TestIssueUserBypassCodesSinkError(t *testing.T) {
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, getBypassCodesResponse)
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	sinkErr := errors.New("mail server unavailable")
+	_, err := duo.IssueUserBypassCodes("DU3RP9I2WOC59VZX672N", func(string, []string) error {
+		return sinkErr
+	}, BypassCodesOptions{})
+	if !errors.Is(err, sinkErr) {
+		t.Errorf("Expected the sink error to be wrapped, but got %v", err)
+	}
+	if strings.Contains(err.Error(), "407176182") {
+		t.Errorf("Expected the error not to contain any codes, but got %v", err)
+	}
+
+	_, err = duo.IssueUserBypassCodes("DU3RP9I2WOC59VZX672N", nil, BypassCodesOptions{})
+	if err == nil {
+		t.Error("Expected an error for a nil sink")
+	}
+}
+
+func TestIssueUserBypassCodesFail(t *testing.T) {
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, `{"stat": "FAIL", "code": 40401, "message": "Resource not found"}`)
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	called := false
+	result, err := duo.IssueUserBypassCodes("DU3RP9I2WOC59VZX672N", func(string, []string) error {
+		called = true
+		return nil
+	}, BypassCodesOptions{})
+	if err != nil {
+		t.Errorf("Unexpected error from IssueUserBypassCodes call %v", err.Error())
+	}
+	if result.Stat != "FAIL" {
+		t.Errorf("Expected FAIL, but got %s", result.Stat)
+	}
+	if called {
+		t.Error("Expected the sink not to be called")
+	}
+}
+
+const listBypassCodesResponse = `{
+	"stat": "OK",
+	"response": [{
+		"admin_email": "ejones@example.com",
+		"bypass_code_id": "DBDKZ3DFQ9EI6YNG0PQO",
+		"created": 1507677225,
+		"expiration": 1507680825,
+		"reuse_count": 1,
+		"user": {
+			"user_id": "DU3RP9I2WOC59VZX672N",
+			"username": "jsmith"
+		}
+	},
+	{
+		"admin_email": "ejones@example.com",
+		"bypass_code_id": "DBNGSVGGGXRYJ8XV8Z2L",
+		"created": 1507677225,
+		"expiration": null,
+		"reuse_count": null,
+		"user": {
+			"user_id": "DU3RP9I2WOC59VZX672N",
+			"username": "jsmith"
+		}
+	}],
+	"metadata": {
+		"total_objects": 2
+	}
+}`
+
+const listBypassCodesPage1Response = `{
+	"stat": "OK",
+	"response": [{
+		"bypass_code_id": "DBDKZ3DFQ9EI6YNG0PQO"
+	}],
+	"metadata": {
+		"next_offset": 1,
+		"total_objects": 2
+	}
+}`
+
+const listBypassCodesPage2Response = `{
+	"stat": "OK",
+	"response": [{
+		"bypass_code_id": "DBNGSVGGGXRYJ8XV8Z2L"
+	}],
+	"metadata": {
+		"prev_offset": 0,
+		"total_objects": 2
+	}
+}`
+
+func TestListUserBypassCodes(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, listBypassCodesResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.ListUserBypassCodes("DU3RP9I2WOC59VZX672N")
+	if err != nil {
+		t.Errorf("Unexpected error from ListUserBypassCodes call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if len(result.Response) != 2 {
+		t.Fatalf("Expected 2 bypass codes, but got %d", len(result.Response))
+	}
+	first := result.Response[0]
+	if first.Expiration == nil || *first.Expiration != 1507680825 || first.ReuseCount == nil || *first.ReuseCount != 1 {
+		t.Errorf("Unexpected first bypass code %+v", first)
+	}
+	if result.Response[1].Expiration != nil || result.Response[1].ReuseCount != nil {
+		t.Errorf("Expected no expiration or reuse limit, but got %+v", result.Response[1])
+	}
+	if last_request.Method != http.MethodGet || last_request.URL.Path != "/admin/v1/users/DU3RP9I2WOC59VZX672N/bypass_codes" {
+		t.Errorf("Unexpected request %s %s", last_request.Method, last_request.URL.Path)
+	}
+	if last_request.URL.Query().Get("limit") != "100" {
+		t.Errorf("Expected to see a limit of 100 in request, bug got %s", last_request.URL.Query().Get("limit"))
+	}
+}
+
+func TestGetBypassCodesMultipage(t *testing.T) {
+	requests := []*http.Request{}
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(requests) == 0 {
+				fmt.Fprintln(w, listBypassCodesPage1Response)
+			} else {
+				fmt.Fprintln(w, listBypassCodesPage2Response)
+			}
+			requests = append(requests, r)
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.GetBypassCodes()
+
+	if len(requests) != 2 {
+		t.Errorf("Expected two requets, found %d", len(requests))
+	}
+	if requests[0].URL.Path != "/admin/v1/bypass_codes" {
+		t.Errorf("Unexpected request path %s", requests[0].URL.Path)
+	}
+	if requests[1].URL.Query().Get("offset") != "1" {
+		t.Errorf("Expected to see an offset of 1 in request, bug got %s", requests[1].URL.Query().Get("offset"))
+	}
+
+	if len(result.Response) != 2 {
+		t.Errorf("Expected two bypass codes in the response, found %d", len(result.Response))
+	}
+
+	if err != nil {
+		t.Errorf("Expected err to be nil, found %s", err)
+	}
+}
+
+const getBypassCodeResponse = `{
+	"stat": "OK",
+	"response": {
+		"admin_email": "ejones@example.com",
+		"bypass_code_id": "DBDKZ3DFQ9EI6YNG0PQO",
+		"created": 1507677225,
+		"expiration": 1507680825,
+		"reuse_count": 1,
+		"user": {
+			"user_id": "DU3RP9I2WOC59VZX672N",
+			"username": "jsmith"
+		}
+	}
+}`
+
+func TestGetBypassCode(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, getBypassCodeResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.GetBypassCode("DBDKZ3DFQ9EI6YNG0PQO")
+	if err != nil {
+		t.Errorf("Unexpected error from GetBypassCode call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if result.Response.AdminEmail != "ejones@example.com" {
+		t.Errorf("Expected admin email ejones@example.com, but got %s", result.Response.AdminEmail)
+	}
+	if result.Response.User == nil || result.Response.User.Username != "jsmith" {
+		t.Errorf("Expected user jsmith, but got %v", result.Response.User)
+	}
+	if last_request.URL.Path != "/admin/v1/bypass_codes/DBDKZ3DFQ9EI6YNG0PQO" {
+		t.Errorf("Unexpected request path %s", last_request.URL.Path)
+	}
+}
+
+func TestDeleteBypassCode(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, deleteUserResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.DeleteBypassCode("DBDKZ3DFQ9EI6YNG0PQO")
+	if err != nil {
+		t.Errorf("Unexpected error from DeleteBypassCode call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if last_request.Method != http.MethodDelete || last_request.URL.Path != "/admin/v1/bypass_codes/DBDKZ3DFQ9EI6YNG0PQO" {
+		t.Errorf("Unexpected request %s %s", last_request.Method, last_request.URL.Path)
+	}
+}
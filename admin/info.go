@@ -0,0 +1,172 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	duoapi "github.com/duosecurity/duo_api_golang"
+)
+
+// InfoMintime sets the optional mintime parameter for the info report
+// requests, limiting the report to events after t.
+func InfoMintime(t time.Time) func(*url.Values) {
+	return func(opts *url.Values) {
+		opts.Set("mintime", strconv.FormatInt(t.Unix(), 10))
+	}
+}
+
+// InfoMaxtime sets the optional maxtime parameter for the info report
+// requests, limiting the report to events before t.
+func InfoMaxtime(t time.Time) func(*url.Values) {
+	return func(opts *url.Values) {
+		opts.Set("maxtime", strconv.FormatInt(t.Unix(), 10))
+	}
+}
+
+// InfoSummary models an overview of the objects in a Duo account.
+type InfoSummary struct {
+	AdminCount                int `json:"admin_count"`
+	IntegrationCount          int `json:"integration_count"`
+	TelephonyCreditsRemaining int `json:"telephony_credits_remaining"`
+	UserCount                 int `json:"user_count"`
+}
+
+// GetInfoSummaryResult models responses containing the account summary.
+type GetInfoSummaryResult struct {
+	duoapi.StatResult
+	Response InfoSummary
+}
+
+// GetInfoSummary calls GET /admin/v1/info/summary
+// See https://duo.com/docs/adminapi#retrieve-summary
+func (c *Client) GetInfoSummary() (*GetInfoSummaryResult, error) {
+	_, body, err := c.SignedCall(http.MethodGet, "/admin/v1/info/summary", nil, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GetInfoSummaryResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// InfoTimeRange holds the time range an info report covers, in Unix seconds.
+type InfoTimeRange struct {
+	MaxTime int64 `json:"maxtime"`
+	MinTime int64 `json:"mintime"`
+}
+
+// TelephonyCreditsUsed models the number of telephony credits used over a
+// time range.
+type TelephonyCreditsUsed struct {
+	InfoTimeRange
+	TelephonyCreditsUsed int `json:"telephony_credits_used"`
+}
+
+// GetTelephonyCreditsUsedResult models responses containing a telephony
+// credits report.
+type GetTelephonyCreditsUsedResult struct {
+	duoapi.StatResult
+	Response TelephonyCreditsUsed
+}
+
+// GetTelephonyCreditsUsed calls GET /admin/v1/info/telephony_credits_used
+// See https://duo.com/docs/adminapi#telephony-credits-used-report
+func (c *Client) GetTelephonyCreditsUsed(options ...func(*url.Values)) (*GetTelephonyCreditsUsedResult, error) {
+	params := url.Values{}
+	for _, o := range options {
+		o(&params)
+	}
+
+	_, body, err := c.SignedCall(http.MethodGet, "/admin/v1/info/telephony_credits_used", params, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GetTelephonyCreditsUsedResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// AuthenticationAttempts counts authentication attempts by result, such as
+// "SUCCESS", "FAILURE", "ERROR" and "FRAUD".
+type AuthenticationAttempts map[string]int
+
+// AuthenticationAttemptsReport models the number of authentication attempts
+// over a time range.
+type AuthenticationAttemptsReport struct {
+	InfoTimeRange
+	AuthenticationAttempts AuthenticationAttempts `json:"authentication_attempts"`
+}
+
+// GetAuthenticationAttemptsResult models responses containing an
+// authentication attempts report.
+type GetAuthenticationAttemptsResult struct {
+	duoapi.StatResult
+	Response AuthenticationAttemptsReport
+}
+
+// GetAuthenticationAttempts calls GET /admin/v1/info/authentication_attempts
+// See https://duo.com/docs/adminapi#authentication-attempts-report
+func (c *Client) GetAuthenticationAttempts(options ...func(*url.Values)) (*GetAuthenticationAttemptsResult, error) {
+	params := url.Values{}
+	for _, o := range options {
+		o(&params)
+	}
+
+	_, body, err := c.SignedCall(http.MethodGet, "/admin/v1/info/authentication_attempts", params, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GetAuthenticationAttemptsResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// UserAuthenticationAttemptsReport models the number of users that attempted
+// to authenticate over a time range, counted by result.
+type UserAuthenticationAttemptsReport struct {
+	InfoTimeRange
+	UserAuthenticationAttempts AuthenticationAttempts `json:"user_authentication_attempts"`
+}
+
+// GetUserAuthenticationAttemptsResult models responses containing a user
+// authentication attempts report.
+type GetUserAuthenticationAttemptsResult struct {
+	duoapi.StatResult
+	Response UserAuthenticationAttemptsReport
+}
+
+// GetUserAuthenticationAttempts calls GET /admin/v1/info/user_authentication_attempts
+// See https://duo.com/docs/adminapi#users-with-authentication-attempts-report
+func (c *Client) GetUserAuthenticationAttempts(options ...func(*url.Values)) (*GetUserAuthenticationAttemptsResult, error) {
+	params := url.Values{}
+	for _, o := range options {
+		o(&params)
+	}
+
+	_, body, err := c.SignedCall(http.MethodGet, "/admin/v1/info/user_authentication_attempts", params, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GetUserAuthenticationAttemptsResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,179 @@
+package admin
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const getInfoSummaryResponse = `{
+	"stat": "OK",
+	"response": {
+		"admin_count": 3,
+		"integration_count": 9,
+		"telephony_credits_remaining": 960,
+		"user_count": 8
+	}
+}`
+
+func TestGetInfoSummary(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, getInfoSummaryResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.GetInfoSummary()
+	if err != nil {
+		t.Errorf("Unexpected error from GetInfoSummary call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	want := InfoSummary{AdminCount: 3, IntegrationCount: 9, TelephonyCreditsRemaining: 960, UserCount: 8}
+	if result.Response != want {
+		t.Errorf("Expected summary %+v, but got %+v", want, result.Response)
+	}
+	if last_request.URL.Path != "/admin/v1/info/summary" {
+		t.Errorf("Unexpected request path %s", last_request.URL.Path)
+	}
+}
+
+const getTelephonyCreditsUsedResponse = `{
+	"stat": "OK",
+	"response": {
+		"maxtime": 1565712000,
+		"mintime": 1563120000,
+		"telephony_credits_used": 12
+	}
+}`
+
+func TestGetTelephonyCreditsUsed(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, getTelephonyCreditsUsedResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.GetTelephonyCreditsUsed(
+		InfoMintime(time.Unix(1563120000, 0)),
+		InfoMaxtime(time.Unix(1565712000, 0)),
+	)
+	if err != nil {
+		t.Errorf("Unexpected error from GetTelephonyCreditsUsed call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if result.Response.TelephonyCreditsUsed != 12 || result.Response.MinTime != 1563120000 || result.Response.MaxTime != 1565712000 {
+		t.Errorf("Unexpected report %+v", result.Response)
+	}
+	if last_request.URL.Path != "/admin/v1/info/telephony_credits_used" {
+		t.Errorf("Unexpected request path %s", last_request.URL.Path)
+	}
+	query := last_request.URL.Query()
+	if query.Get("mintime") != "1563120000" || query.Get("maxtime") != "1565712000" {
+		t.Errorf("Unexpected time range in request %v", query)
+	}
+}
+
+const getAuthenticationAttemptsResponse = `{
+	"stat": "OK",
+	"response": {
+		"authentication_attempts": {
+			"ERROR": 1,
+			"FAILURE": 2,
+			"FRAUD": 0,
+			"SUCCESS": 21
+		},
+		"maxtime": 1565712000,
+		"mintime": 1563120000
+	}
+}`
+
+func TestGetAuthenticationAttempts(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, getAuthenticationAttemptsResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.GetAuthenticationAttempts()
+	if err != nil {
+		t.Errorf("Unexpected error from GetAuthenticationAttempts call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	attempts := result.Response.AuthenticationAttempts
+	if attempts["SUCCESS"] != 21 || attempts["FAILURE"] != 2 || attempts["ERROR"] != 1 {
+		t.Errorf("Unexpected attempts %v", attempts)
+	}
+	if last_request.URL.Path != "/admin/v1/info/authentication_attempts" {
+		t.Errorf("Unexpected request path %s", last_request.URL.Path)
+	}
+	if len(last_request.URL.Query()) != 0 {
+		t.Errorf("Expected no parameters in request, but got %v", last_request.URL.Query())
+	}
+}
+
+const getUserAuthenticationAttemptsResponse = `{
+	"stat": "OK",
+	"response": {
+		"maxtime": 1565712000,
+		"mintime": 1563120000,
+		"user_authentication_attempts": {
+			"ERROR": 1,
+			"FAILURE": 1,
+			"FRAUD": 0,
+			"SUCCESS": 6
+		}
+	}
+}`
+
+func TestGetUserAuthenticationAttempts(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, getUserAuthenticationAttemptsResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.GetUserAuthenticationAttempts(InfoMintime(time.Unix(1563120000, 0)))
+	if err != nil {
+		t.Errorf("Unexpected error from GetUserAuthenticationAttempts call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if result.Response.UserAuthenticationAttempts["SUCCESS"] != 6 {
+		t.Errorf("Unexpected attempts %v", result.Response.UserAuthenticationAttempts)
+	}
+	if last_request.URL.Path != "/admin/v1/info/user_authentication_attempts" {
+		t.Errorf("Unexpected request path %s", last_request.URL.Path)
+	}
+	if last_request.URL.Query().Get("mintime") != "1563120000" {
+		t.Errorf("Expected mintime in request, but got %v", last_request.URL.Query())
+	}
+}
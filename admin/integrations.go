@@ -0,0 +1,452 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	duoapi "github.com/duosecurity/duo_api_golang"
+)
+
+// Flag is a boolean that the Admin API encodes as 1 or 0. It decodes from
+// either numbers or JSON booleans, and is sent as "1" or "0".
+type Flag bool
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (f *Flag) UnmarshalJSON(b []byte) error {
+	switch string(b) {
+	case "1", "true":
+		*f = true
+	case "0", "false", "null":
+		*f = false
+	default:
+		return fmt.Errorf("admin: invalid flag value %s", b)
+	}
+	return nil
+}
+
+// String returns "1" or "0", the form the Admin API expects in parameters.
+func (f Flag) String() string {
+	if f {
+		return "1"
+	}
+	return "0"
+}
+
+// Common integration types.
+const (
+	IntegrationTypeAdminAPI    = "adminapi"
+	IntegrationTypeAuthAPI     = "authapi"
+	IntegrationTypeAccountsAPI = "accountsapi"
+	IntegrationTypeWebSDK      = "websdk"
+	IntegrationTypeRDP         = "rdp"
+	IntegrationTypeSSO         = "sso-generic"
+	IntegrationTypeUNIX        = "unix"
+)
+
+// Integration models a Duo application. The secret key is deliberately not
+// part of the model; use GetIntegrationSecretKey to fetch it.
+type Integration struct {
+	AdminAPIAdmins              Flag     `json:"adminapi_admins"`
+	AdminAPIInfo                Flag     `json:"adminapi_info"`
+	AdminAPIIntegrations        Flag     `json:"adminapi_integrations"`
+	AdminAPIReadLog             Flag     `json:"adminapi_read_log"`
+	AdminAPIReadResource        Flag     `json:"adminapi_read_resource"`
+	AdminAPISettings            Flag     `json:"adminapi_settings"`
+	AdminAPIWriteResource       Flag     `json:"adminapi_write_resource"`
+	EnrollPolicy                string   `json:"enroll_policy"`
+	Greeting                    string   `json:"greeting"`
+	GroupsAllowed               []string `json:"groups_allowed"`
+	IntegrationKey              string   `json:"integration_key"`
+	IPWhitelist                 []string `json:"ip_whitelist"`
+	Name                        string   `json:"name"`
+	Notes                       string   `json:"notes"`
+	PolicyKey                   string   `json:"policy_key"`
+	SelfServiceAllowed          Flag     `json:"self_service_allowed"`
+	TrustedDeviceDays           *int     `json:"trusted_device_days"`
+	Type                        string   `json:"type"`
+	UsernameNormalizationPolicy string   `json:"username_normalization_policy"`
+}
+
+// IntegrationUpdate holds the fields of an integration to set with
+// CreateIntegration or ModifyIntegration. Nil fields are left unchanged.
+// Name and Type are required on creation, and Type cannot be modified.
+type IntegrationUpdate struct {
+	Name                        *string `url:"name"`
+	Type                        *string `url:"type"`
+	Notes                       *string `url:"notes"`
+	Greeting                    *string `url:"greeting"`
+	EnrollPolicy                *string `url:"enroll_policy"`
+	UsernameNormalizationPolicy *string `url:"username_normalization_policy"`
+	SelfServiceAllowed          *Flag   `url:"self_service_allowed"`
+	TrustedDeviceDays           *int    `url:"trusted_device_days"`
+	AdminAPIAdmins              *Flag   `url:"adminapi_admins"`
+	AdminAPIInfo                *Flag   `url:"adminapi_info"`
+	AdminAPIIntegrations        *Flag   `url:"adminapi_integrations"`
+	AdminAPIReadLog             *Flag   `url:"adminapi_read_log"`
+	AdminAPIReadResource        *Flag   `url:"adminapi_read_resource"`
+	AdminAPISettings            *Flag   `url:"adminapi_settings"`
+	AdminAPIWriteResource       *Flag   `url:"adminapi_write_resource"`
+	// GroupsAllowed restricts access to the given group IDs. A pointer to an
+	// empty slice allows all users again.
+	GroupsAllowed *[]string
+	// IPWhitelist lists the IP addresses, ranges and CIDRs allowed to bypass
+	// 2FA. A pointer to an empty slice clears it.
+	IPWhitelist *[]string
+}
+
+// URLValues transforms an IntegrationUpdate into url.Values, skipping nil
+// fields.
+func (i *IntegrationUpdate) URLValues() url.Values {
+	params := structURLValues(i)
+	if i.GroupsAllowed != nil {
+		params.Set("groups_allowed", strings.Join(*i.GroupsAllowed, ","))
+	}
+	if i.IPWhitelist != nil {
+		params.Set("ip_whitelist", strings.Join(*i.IPWhitelist, ","))
+	}
+	return params
+}
+
+// GetIntegrationsResult models responses containing a list of integrations.
+type GetIntegrationsResult struct {
+	duoapi.StatResult
+	ListResult
+	Response []Integration
+}
+
+func (result *GetIntegrationsResult) getResponse() interface{} {
+	return result.Response
+}
+
+func (result *GetIntegrationsResult) appendResponse(integrations interface{}) {
+	asserted_integrations := integrations.([]Integration)
+	result.Response = append(result.Response, asserted_integrations...)
+}
+
+// GetIntegrations calls GET /admin/v1/integrations
+// See https://duo.com/docs/adminapi#retrieve-integrations
+func (c *Client) GetIntegrations(options ...func(*url.Values)) (*GetIntegrationsResult, error) {
+	params := url.Values{}
+	for _, o := range options {
+		o(&params)
+	}
+
+	cb := func(params url.Values) (responsePage, error) {
+		return c.retrieveIntegrations(params)
+	}
+	response, err := c.retrieveItems(params, cb)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.(*GetIntegrationsResult), nil
+}
+
+func (c *Client) retrieveIntegrations(params url.Values) (*GetIntegrationsResult, error) {
+	_, body, err := c.SignedCall(http.MethodGet, "/admin/v1/integrations", params, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GetIntegrationsResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetIntegrationResult models responses containing a single integration.
+type GetIntegrationResult struct {
+	duoapi.StatResult
+	Response Integration
+}
+
+// GetIntegration calls GET /admin/v1/integrations/:integration_key
+// See https://duo.com/docs/adminapi#retrieve-integration-by-integration-key
+func (c *Client) GetIntegration(integrationKey string) (*GetIntegrationResult, error) {
+	path := fmt.Sprintf("/admin/v1/integrations/%s", integrationKey)
+
+	_, body, err := c.SignedCall(http.MethodGet, path, nil, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GetIntegrationResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CreateIntegration calls POST /admin/v1/integrations
+// See https://duo.com/docs/adminapi#create-integration
+func (c *Client) CreateIntegration(integration IntegrationUpdate) (*GetIntegrationResult, error) {
+	_, body, err := c.SignedCall(http.MethodPost, "/admin/v1/integrations", integration.URLValues(), duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GetIntegrationResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ModifyIntegration calls POST /admin/v1/integrations/:integration_key
+// See https://duo.com/docs/adminapi#modify-integration
+func (c *Client) ModifyIntegration(integrationKey string, update IntegrationUpdate) (*GetIntegrationResult, error) {
+	path := fmt.Sprintf("/admin/v1/integrations/%s", integrationKey)
+
+	_, body, err := c.SignedCall(http.MethodPost, path, update.URLValues(), duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GetIntegrationResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteIntegration calls DELETE /admin/v1/integrations/:integration_key
+// See https://duo.com/docs/adminapi#delete-integration
+func (c *Client) DeleteIntegration(integrationKey string) (*duoapi.StatResult, error) {
+	path := fmt.Sprintf("/admin/v1/integrations/%s", integrationKey)
+
+	_, body, err := c.SignedCall(http.MethodDelete, path, nil, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &duoapi.StatResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// IntegrationSecretKeyResult models responses containing an integration's
+// secret key.
+type IntegrationSecretKeyResult struct {
+	duoapi.StatResult
+	Response struct {
+		SecretKey string `json:"secret_key"`
+	}
+}
+
+// GetIntegrationSecretKey calls GET /admin/v1/integrations/:integration_key/skey
+// The calling integration needs the "Grant read information" and "Grant
+// applications" permissions.
+// See https://duo.com/docs/adminapi#retrieve-integration-secret-key
+func (c *Client) GetIntegrationSecretKey(integrationKey string) (*IntegrationSecretKeyResult, error) {
+	path := fmt.Sprintf("/admin/v1/integrations/%s/skey", integrationKey)
+
+	_, body, err := c.SignedCall(http.MethodGet, path, nil, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &IntegrationSecretKeyResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Integrations v2 methods
+
+// IntegrationV2 models a Duo application as returned by the v2 integration
+// endpoints, which add user access, API network and SSO settings.
+type IntegrationV2 struct {
+	Integration
+	// NetworksForAPIAccess is a comma-separated list of the IP addresses,
+	// ranges and CIDRs allowed to call an Admin API or Accounts API
+	// integration.
+	NetworksForAPIAccess string `json:"networks_for_api_access"`
+	PromptV4Enabled      Flag   `json:"prompt_v4_enabled"`
+	// SSO holds the single sign-on settings of SSO integrations, as sent by
+	// Duo.
+	SSO json.RawMessage `json:"sso"`
+	// UserAccess is "ALL_USERS", "NO_USERS" or "PERMITTED_GROUPS", in which
+	// case GroupsAllowed lists the groups.
+	UserAccess string `json:"user_access"`
+}
+
+// IntegrationV2Update holds the fields of an integration to set with
+// CreateIntegrationV2 or ModifyIntegrationV2. Nil fields are left unchanged.
+// Name and Type are required on creation, and Type cannot be modified.
+type IntegrationV2Update struct {
+	Name                        *string `json:"name,omitempty"`
+	Type                        *string `json:"type,omitempty"`
+	Notes                       *string `json:"notes,omitempty"`
+	Greeting                    *string `json:"greeting,omitempty"`
+	PolicyKey                   *string `json:"policy_key,omitempty"`
+	UsernameNormalizationPolicy *string `json:"username_normalization_policy,omitempty"`
+	SelfServiceAllowed          *bool   `json:"self_service_allowed,omitempty"`
+	TrustedDeviceDays           *int    `json:"trusted_device_days,omitempty"`
+	PromptV4Enabled             *bool   `json:"prompt_v4_enabled,omitempty"`
+	AdminAPIAdmins              *bool   `json:"adminapi_admins,omitempty"`
+	AdminAPIInfo                *bool   `json:"adminapi_info,omitempty"`
+	AdminAPIIntegrations        *bool   `json:"adminapi_integrations,omitempty"`
+	AdminAPIReadLog             *bool   `json:"adminapi_read_log,omitempty"`
+	AdminAPIReadResource        *bool   `json:"adminapi_read_resource,omitempty"`
+	AdminAPISettings            *bool   `json:"adminapi_settings,omitempty"`
+	AdminAPIWriteResource       *bool   `json:"adminapi_write_resource,omitempty"`
+	NetworksForAPIAccess        *string `json:"networks_for_api_access,omitempty"`
+	UserAccess                  *string `json:"user_access,omitempty"`
+	// GroupsAllowed lists the group IDs allowed when UserAccess is
+	// "PERMITTED_GROUPS". A pointer to an empty slice clears it.
+	GroupsAllowed *[]string `json:"groups_allowed,omitempty"`
+	// SSO holds the single sign-on settings of an SSO integration, in the
+	// form Duo documents for its type.
+	SSO json.RawMessage `json:"sso,omitempty"`
+}
+
+// JSONParams transforms an IntegrationV2Update into duoapi.JSONParams,
+// skipping nil fields.
+func (i *IntegrationV2Update) JSONParams() (duoapi.JSONParams, error) {
+	encoded, err := json.Marshal(i)
+	if err != nil {
+		return nil, err
+	}
+	params := duoapi.JSONParams{}
+	err = json.Unmarshal(encoded, &params)
+	if err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+// GetIntegrationsV2Result models responses containing a list of v2
+// integrations.
+type GetIntegrationsV2Result struct {
+	duoapi.StatResult
+	ListResult
+	Response []IntegrationV2
+}
+
+func (result *GetIntegrationsV2Result) getResponse() interface{} {
+	return result.Response
+}
+
+func (result *GetIntegrationsV2Result) appendResponse(integrations interface{}) {
+	asserted_integrations := integrations.([]IntegrationV2)
+	result.Response = append(result.Response, asserted_integrations...)
+}
+
+// GetIntegrationsV2 calls GET /admin/v2/integrations
+// See https://duo.com/docs/adminapi#retrieve-integrations
+func (c *Client) GetIntegrationsV2(options ...func(*url.Values)) (*GetIntegrationsV2Result, error) {
+	params := url.Values{}
+	for _, o := range options {
+		o(&params)
+	}
+
+	cb := func(params url.Values) (responsePage, error) {
+		return c.retrieveIntegrationsV2(params)
+	}
+	response, err := c.retrieveItems(params, cb)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.(*GetIntegrationsV2Result), nil
+}
+
+func (c *Client) retrieveIntegrationsV2(params url.Values) (*GetIntegrationsV2Result, error) {
+	jsonParams := duoapi.JSONParams{}
+	for key := range params {
+		jsonParams[key] = params.Get(key)
+	}
+
+	_, body, err := c.JSONSignedCall(http.MethodGet, "/admin/v2/integrations", jsonParams, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GetIntegrationsV2Result{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetIntegrationV2Result models responses containing a single v2 integration.
+type GetIntegrationV2Result struct {
+	duoapi.StatResult
+	Response IntegrationV2
+}
+
+// GetIntegrationV2 calls GET /admin/v2/integrations/:integration_key
+// See https://duo.com/docs/adminapi#retrieve-integration-by-integration-key
+func (c *Client) GetIntegrationV2(integrationKey string) (*GetIntegrationV2Result, error) {
+	path := fmt.Sprintf("/admin/v2/integrations/%s", integrationKey)
+
+	_, body, err := c.JSONSignedCall(http.MethodGet, path, nil, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GetIntegrationV2Result{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CreateIntegrationV2 calls POST /admin/v2/integrations
+// See https://duo.com/docs/adminapi#create-integration
+func (c *Client) CreateIntegrationV2(integration IntegrationV2Update) (*GetIntegrationV2Result, error) {
+	params, err := integration.JSONParams()
+	if err != nil {
+		return nil, err
+	}
+
+	_, body, err := c.JSONSignedCall(http.MethodPost, "/admin/v2/integrations", params, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GetIntegrationV2Result{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ModifyIntegrationV2 calls POST /admin/v2/integrations/:integration_key
+// See https://duo.com/docs/adminapi#modify-integration
+func (c *Client) ModifyIntegrationV2(integrationKey string, update IntegrationV2Update) (*GetIntegrationV2Result, error) {
+	path := fmt.Sprintf("/admin/v2/integrations/%s", integrationKey)
+
+	params, err := update.JSONParams()
+	if err != nil {
+		return nil, err
+	}
+
+	_, body, err := c.JSONSignedCall(http.MethodPost, path, params, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GetIntegrationV2Result{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
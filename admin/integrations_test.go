@@ -0,0 +1,577 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFlag(t *testing.T) {
+	var flags struct {
+		A, B, C, D, E Flag
+	}
+	err := json.Unmarshal([]byte(`{"A": 1, "B": 0, "C": true, "D": false, "E": null}`), &flags)
+	if err != nil {
+		t.Fatalf("Unexpected error decoding flags: %v", err)
+	}
+	if !flags.A || flags.B || !flags.C || flags.D || flags.E {
+		t.Errorf("Unexpected flags %+v", flags)
+	}
+	if flags.A.String() != "1" || flags.B.String() != "0" {
+		t.Errorf("Expected flags to format as 1 and 0, but got %s and %s", flags.A, flags.B)
+	}
+
+	var flag Flag
+	if err := json.Unmarshal([]byte(`"yes"`), &flag); err == nil {
+		t.Error("Expected an error for a string flag")
+	}
+}
+
+func TestIntegrationUpdate_URLValues(t *testing.T) {
+	name := "VPN"
+	allowed := Flag(true)
+	denied := Flag(false)
+	update := IntegrationUpdate{
+		Name:                  &name,
+		AdminAPIReadLog:       &allowed,
+		AdminAPIWriteResource: &denied,
+		GroupsAllowed:         &[]string{"DGXXXXXXXXXXXXXXXXX1", "DGXXXXXXXXXXXXXXXXX2"},
+		IPWhitelist:           &[]string{},
+	}
+	want := url.Values{
+		"name":                    []string{"VPN"},
+		"adminapi_read_log":       []string{"1"},
+		"adminapi_write_resource": []string{"0"},
+		"groups_allowed":          []string{"DGXXXXXXXXXXXXXXXXX1,DGXXXXXXXXXXXXXXXXX2"},
+		"ip_whitelist":            []string{""},
+	}
+	if got := update.URLValues(); !reflect.DeepEqual(got, want) {
+		t.Errorf("IntegrationUpdate.URLValues() = %v, want %v", got, want)
+	}
+}
+
+const getIntegrationsResponse = `{
+	"stat": "OK",
+	"metadata": {
+		"total_objects": 1
+	},
+	"response": [{
+		"adminapi_admins": 0,
+		"adminapi_info": 1,
+		"adminapi_integrations": 0,
+		"adminapi_read_log": 1,
+		"adminapi_read_resource": 0,
+		"adminapi_settings": 0,
+		"adminapi_write_resource": 0,
+		"enroll_policy": "enroll",
+		"greeting": "",
+		"groups_allowed": ["DGXXXXXXXXXXXXXXXXXX"],
+		"integration_key": "DIRWIH0ZZPV4G88B37VQ",
+		"ip_whitelist": [],
+		"name": "Log Export",
+		"notes": "",
+		"policy_key": "POD5JNH5TNG2CP7C8FHK",
+		"secret_key": "QO4ZLqQVRIOZYkHfdPDORfcNf8LeXIbCWwHazY7o",
+		"self_service_allowed": false,
+		"trusted_device_days": null,
+		"type": "adminapi",
+		"username_normalization_policy": "None"
+	}]
+}`
+
+const getIntegrationsPage1Response = `{
+	"stat": "OK",
+	"metadata": {
+		"next_offset": 1,
+		"total_objects": 2
+	},
+	"response": [{
+		"integration_key": "DIRWIH0ZZPV4G88B37VQ",
+		"name": "Log Export",
+		"type": "adminapi"
+	}]
+}`
+
+const getIntegrationsPage2Response = `{
+	"stat": "OK",
+	"metadata": {
+		"prev_offset": 0,
+		"total_objects": 2
+	},
+	"response": [{
+		"integration_key": "DIXXXXXXXXXXXXXXXXX2",
+		"name": "VPN",
+		"type": "radius"
+	}]
+}`
+
+func TestGetIntegrations(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, getIntegrationsResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.GetIntegrations()
+	if err != nil {
+		t.Errorf("Unexpected error from GetIntegrations call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if len(result.Response) != 1 {
+		t.Fatalf("Expected 1 integration, but got %d", len(result.Response))
+	}
+	integration := result.Response[0]
+	if integration.Type != IntegrationTypeAdminAPI || integration.PolicyKey != "POD5JNH5TNG2CP7C8FHK" {
+		t.Errorf("Unexpected integration %+v", integration)
+	}
+	if !integration.AdminAPIReadLog || integration.AdminAPIWriteResource {
+		t.Errorf("Unexpected Admin API permissions %+v", integration)
+	}
+	if !reflect.DeepEqual(integration.GroupsAllowed, []string{"DGXXXXXXXXXXXXXXXXXX"}) {
+		t.Errorf("Unexpected groups allowed %v", integration.GroupsAllowed)
+	}
+	if strings.Contains(fmt.Sprintf("%+v", result), "QO4ZLqQVRIOZYkHfdPDORfcNf8LeXIbCWwHazY7o") {
+		t.Error("Expected the secret key to be left out of the result")
+	}
+	if last_request.URL.Path != "/admin/v1/integrations" {
+		t.Errorf("Unexpected request path %s", last_request.URL.Path)
+	}
+	if last_request.URL.Query().Get("limit") != "100" {
+		t.Errorf("Expected to see a limit of 100 in request, bug got %s", last_request.URL.Query().Get("limit"))
+	}
+}
+
+func TestGetIntegrationsMultipage(t *testing.T) {
+	requests := []*http.Request{}
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(requests) == 0 {
+				fmt.Fprintln(w, getIntegrationsPage1Response)
+			} else {
+				fmt.Fprintln(w, getIntegrationsPage2Response)
+			}
+			requests = append(requests, r)
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.GetIntegrations()
+
+	if len(requests) != 2 {
+		t.Errorf("Expected two requets, found %d", len(requests))
+	}
+
+	if len(result.Response) != 2 {
+		t.Errorf("Expected two integrations in the response, found %d", len(result.Response))
+	}
+
+	if err != nil {
+		t.Errorf("Expected err to be nil, found %s", err)
+	}
+}
+
+const getIntegrationResponse = `{
+	"stat": "OK",
+	"response": {
+		"adminapi_admins": 0,
+		"adminapi_info": 1,
+		"adminapi_integrations": 0,
+		"adminapi_read_log": 1,
+		"adminapi_read_resource": 0,
+		"adminapi_settings": 0,
+		"adminapi_write_resource": 0,
+		"enroll_policy": "enroll",
+		"greeting": "",
+		"groups_allowed": [],
+		"integration_key": "DIRWIH0ZZPV4G88B37VQ",
+		"ip_whitelist": ["192.0.2.0/24"],
+		"name": "Log Export",
+		"notes": "",
+		"policy_key": "",
+		"secret_key": "QO4ZLqQVRIOZYkHfdPDORfcNf8LeXIbCWwHazY7o",
+		"self_service_allowed": true,
+		"trusted_device_days": 30,
+		"type": "adminapi",
+		"username_normalization_policy": "None"
+	}
+}`
+
+func TestGetIntegration(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, getIntegrationResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.GetIntegration("DIRWIH0ZZPV4G88B37VQ")
+	if err != nil {
+		t.Errorf("Unexpected error from GetIntegration call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if !result.Response.SelfServiceAllowed {
+		t.Errorf("Expected self service to be allowed")
+	}
+	if result.Response.TrustedDeviceDays == nil || *result.Response.TrustedDeviceDays != 30 {
+		t.Errorf("Expected 30 trusted device days, but got %v", result.Response.TrustedDeviceDays)
+	}
+	if last_request.Method != http.MethodGet || last_request.URL.Path != "/admin/v1/integrations/DIRWIH0ZZPV4G88B37VQ" {
+		t.Errorf("Unexpected request %s %s", last_request.Method, last_request.URL.Path)
+	}
+}
+
+func TestCreateIntegration(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			fmt.Fprintln(w, getIntegrationResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	name := "Log Export"
+	typ := IntegrationTypeAdminAPI
+	readLog := Flag(true)
+	result, err := duo.CreateIntegration(IntegrationUpdate{Name: &name, Type: &typ, AdminAPIReadLog: &readLog})
+	if err != nil {
+		t.Errorf("Unexpected error from CreateIntegration call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if result.Response.IntegrationKey != "DIRWIH0ZZPV4G88B37VQ" {
+		t.Errorf("Expected integration key DIRWIH0ZZPV4G88B37VQ, but got %s", result.Response.IntegrationKey)
+	}
+	if last_request.Method != http.MethodPost || last_request.URL.Path != "/admin/v1/integrations" {
+		t.Errorf("Unexpected request %s %s", last_request.Method, last_request.URL.Path)
+	}
+	if last_request.Form.Get("type") != "adminapi" || last_request.Form.Get("adminapi_read_log") != "1" {
+		t.Errorf("Unexpected request parameters %v", last_request.Form)
+	}
+}
+
+func TestModifyIntegration(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			fmt.Fprintln(w, getIntegrationResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.ModifyIntegration("DIRWIH0ZZPV4G88B37VQ", IntegrationUpdate{
+		GroupsAllowed: &[]string{"DGXXXXXXXXXXXXXXXXXX"},
+	})
+	if err != nil {
+		t.Errorf("Unexpected error from ModifyIntegration call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if last_request.URL.Path != "/admin/v1/integrations/DIRWIH0ZZPV4G88B37VQ" {
+		t.Errorf("Unexpected request path %s", last_request.URL.Path)
+	}
+	if len(last_request.Form) != 1 || last_request.Form.Get("groups_allowed") != "DGXXXXXXXXXXXXXXXXXX" {
+		t.Errorf("Expected only groups_allowed in request, but got %v", last_request.Form)
+	}
+}
+
+func TestDeleteIntegration(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, deleteUserResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.DeleteIntegration("DIRWIH0ZZPV4G88B37VQ")
+	if err != nil {
+		t.Errorf("Unexpected error from DeleteIntegration call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if last_request.Method != http.MethodDelete || last_request.URL.Path != "/admin/v1/integrations/DIRWIH0ZZPV4G88B37VQ" {
+		t.Errorf("Unexpected request %s %s", last_request.Method, last_request.URL.Path)
+	}
+}
+
+const getIntegrationSecretKeyResponse = `{
+	"stat": "OK",
+	"response": {
+		"secret_key": "QO4ZLqQVRIOZYkHfdPDORfcNf8LeXIbCWwHazY7o"
+	}
+}`
+
+func TestGetIntegrationSecretKey(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, getIntegrationSecretKeyResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.GetIntegrationSecretKey("DIRWIH0ZZPV4G88B37VQ")
+	if err != nil {
+		t.Errorf("Unexpected error from GetIntegrationSecretKey call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if result.Response.SecretKey != "QO4ZLqQVRIOZYkHfdPDORfcNf8LeXIbCWwHazY7o" {
+		t.Errorf("Unexpected secret key %s", result.Response.SecretKey)
+	}
+	if last_request.Method != http.MethodGet || last_request.URL.Path != "/admin/v1/integrations/DIRWIH0ZZPV4G88B37VQ/skey" {
+		t.Errorf("Unexpected request %s %s", last_request.Method, last_request.URL.Path)
+	}
+}
+
+const getIntegrationV2Response = `{
+	"stat": "OK",
+	"response": {
+		"adminapi_admins": false,
+		"adminapi_info": true,
+		"adminapi_integrations": false,
+		"adminapi_read_log": true,
+		"adminapi_read_resource": false,
+		"adminapi_settings": false,
+		"adminapi_write_resource": false,
+		"greeting": "",
+		"groups_allowed": [],
+		"integration_key": "DIRWIH0ZZPV4G88B37VQ",
+		"name": "Log Export",
+		"networks_for_api_access": "192.0.2.0/24",
+		"notes": "",
+		"policy_key": "POD5JNH5TNG2CP7C8FHK",
+		"prompt_v4_enabled": 1,
+		"secret_key": "QO4ZLqQVRIOZYkHfdPDORfcNf8LeXIbCWwHazY7o",
+		"self_service_allowed": false,
+		"sso": null,
+		"trusted_device_days": null,
+		"type": "adminapi",
+		"user_access": "ALL_USERS",
+		"username_normalization_policy": "None"
+	}
+}`
+
+const getIntegrationsV2Page1Response = `{
+	"stat": "OK",
+	"metadata": {
+		"next_offset": 1,
+		"total_objects": 2
+	},
+	"response": [{
+		"integration_key": "DIRWIH0ZZPV4G88B37VQ",
+		"name": "Log Export",
+		"type": "adminapi",
+		"user_access": "ALL_USERS"
+	}]
+}`
+
+const getIntegrationsV2Page2Response = `{
+	"stat": "OK",
+	"metadata": {
+		"prev_offset": 0,
+		"total_objects": 2
+	},
+	"response": [{
+		"integration_key": "DIXXXXXXXXXXXXXXXXX2",
+		"name": "Web SSO",
+		"sso": {"saml_config": {"entity_id": "https://sp.example.com"}},
+		"type": "sso-generic",
+		"user_access": "PERMITTED_GROUPS"
+	}]
+}`
+
+func TestGetIntegrationsV2Multipage(t *testing.T) {
+	requests := []*http.Request{}
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(requests) == 0 {
+				fmt.Fprintln(w, getIntegrationsV2Page1Response)
+			} else {
+				fmt.Fprintln(w, getIntegrationsV2Page2Response)
+			}
+			requests = append(requests, r)
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.GetIntegrationsV2()
+	if err != nil {
+		t.Fatalf("Unexpected error from GetIntegrationsV2 call %v", err.Error())
+	}
+	if len(requests) != 2 {
+		t.Fatalf("Expected two requests, found %d", len(requests))
+	}
+	if requests[0].URL.Path != "/admin/v2/integrations" {
+		t.Errorf("Unexpected request path %s", requests[0].URL.Path)
+	}
+	if requests[0].URL.Query().Get("limit") != "100" || requests[1].URL.Query().Get("offset") != "1" {
+		t.Errorf("Unexpected paging parameters %v, %v", requests[0].URL.Query(), requests[1].URL.Query())
+	}
+	if len(result.Response) != 2 {
+		t.Fatalf("Expected two integrations in the response, found %d", len(result.Response))
+	}
+	var sso IntegrationV2
+	for _, integration := range result.Response {
+		if integration.IntegrationKey == "DIXXXXXXXXXXXXXXXXX2" {
+			sso = integration
+		}
+	}
+	if sso.UserAccess != "PERMITTED_GROUPS" || string(sso.SSO) != `{"saml_config": {"entity_id": "https://sp.example.com"}}` {
+		t.Errorf("Unexpected integration %+v", sso)
+	}
+}
+
+func TestGetIntegrationV2(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, getIntegrationV2Response)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.GetIntegrationV2("DIRWIH0ZZPV4G88B37VQ")
+	if err != nil {
+		t.Fatalf("Unexpected error from GetIntegrationV2 call %v", err.Error())
+	}
+	integration := result.Response
+	if integration.IntegrationKey != "DIRWIH0ZZPV4G88B37VQ" || integration.UserAccess != "ALL_USERS" ||
+		integration.NetworksForAPIAccess != "192.0.2.0/24" || !integration.PromptV4Enabled {
+		t.Errorf("Unexpected integration %+v", integration)
+	}
+	if !integration.AdminAPIReadLog || integration.AdminAPIWriteResource {
+		t.Errorf("Unexpected Admin API permissions %+v", integration)
+	}
+	if strings.Contains(fmt.Sprintf("%+v", result), "QO4ZLqQVRIOZYkHfdPDORfcNf8LeXIbCWwHazY7o") {
+		t.Error("Expected the secret key to be left out of the result")
+	}
+	if last_request.Method != http.MethodGet || last_request.URL.Path != "/admin/v2/integrations/DIRWIH0ZZPV4G88B37VQ" {
+		t.Errorf("Unexpected request %s %s", last_request.Method, last_request.URL.Path)
+	}
+}
+
+func TestCreateIntegrationV2(t *testing.T) {
+	var params map[string]interface{}
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			params = policyRequestBody(t, r)
+			fmt.Fprintln(w, getIntegrationV2Response)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	name := "Log Export"
+	typ := IntegrationTypeAdminAPI
+	readLog := true
+	networks := "192.0.2.0/24"
+	result, err := duo.CreateIntegrationV2(IntegrationV2Update{
+		Name:                 &name,
+		Type:                 &typ,
+		AdminAPIReadLog:      &readLog,
+		NetworksForAPIAccess: &networks,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error from CreateIntegrationV2 call %v", err.Error())
+	}
+	if result.Response.IntegrationKey != "DIRWIH0ZZPV4G88B37VQ" {
+		t.Errorf("Unexpected integration %+v", result.Response)
+	}
+	if last_request.Method != http.MethodPost || last_request.URL.Path != "/admin/v2/integrations" {
+		t.Errorf("Unexpected request %s %s", last_request.Method, last_request.URL.Path)
+	}
+	want := map[string]interface{}{
+		"name":                    "Log Export",
+		"type":                    "adminapi",
+		"adminapi_read_log":       true,
+		"networks_for_api_access": "192.0.2.0/24",
+	}
+	if !reflect.DeepEqual(params, want) {
+		t.Errorf("Unexpected request body %v", params)
+	}
+}
+
+func TestModifyIntegrationV2(t *testing.T) {
+	var params map[string]interface{}
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			params = policyRequestBody(t, r)
+			fmt.Fprintln(w, getIntegrationV2Response)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	userAccess := "ALL_USERS"
+	_, err := duo.ModifyIntegrationV2("DIRWIH0ZZPV4G88B37VQ", IntegrationV2Update{
+		UserAccess:    &userAccess,
+		GroupsAllowed: &[]string{},
+		SSO:           json.RawMessage(`{"saml_config":{"entity_id":"https://sp.example.com"}}`),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error from ModifyIntegrationV2 call %v", err.Error())
+	}
+	if last_request.Method != http.MethodPost || last_request.URL.Path != "/admin/v2/integrations/DIRWIH0ZZPV4G88B37VQ" {
+		t.Errorf("Unexpected request %s %s", last_request.Method, last_request.URL.Path)
+	}
+	want := map[string]interface{}{
+		"user_access":    "ALL_USERS",
+		"groups_allowed": []interface{}{},
+		"sso": map[string]interface{}{
+			"saml_config": map[string]interface{}{"entity_id": "https://sp.example.com"},
+		},
+	}
+	if !reflect.DeepEqual(params, want) {
+		t.Errorf("Unexpected request body %v", params)
+	}
+}
@@ -15,6 +15,43 @@ import (
 // maxLogV1PageSize sets 1000 as the maximum page size for API V1 log endpoints.
 const maxLogV1PageSize = 1000
 
+// LogLocation is the geographic location of an IP address in a log.
+type LogLocation struct {
+	City    string `json:"city"`
+	Country string `json:"country"`
+	State   string `json:"state"`
+}
+
+// unmarshalLog decodes a log record into typed, which must be a pointer to a struct without custom unmarshalling, and returns a copy of the raw JSON along with the time parsed from its timestamp field.
+func unmarshalLog(data []byte, typed interface{}) (json.RawMessage, time.Time, error) {
+	if err := json.Unmarshal(data, typed); err != nil {
+		return nil, time.Time{}, err
+	}
+
+	var timestamp struct {
+		Timestamp    interface{} `json:"timestamp"`
+		ISOTimestamp interface{} `json:"isotimestamp"`
+	}
+	if err := json.Unmarshal(data, &timestamp); err != nil {
+		return nil, time.Time{}, err
+	}
+	// Logs without a usable timestamp are kept, with a zero Time.
+	t, err := parseLogTimestamp(timestamp.Timestamp)
+	if err != nil {
+		t, _ = parseLogTimestamp(timestamp.ISOTimestamp)
+	}
+
+	return append(json.RawMessage(nil), data...), t, nil
+}
+
+// marshalLog returns the raw JSON a log was decoded from, falling back to encoding typed when the log was not decoded from JSON.
+func marshalLog(raw json.RawMessage, typed interface{}) ([]byte, error) {
+	if raw != nil {
+		return raw, nil
+	}
+	return json.Marshal(typed)
+}
+
 /*
  * V2 Logs
  */
@@ -24,6 +61,29 @@ type LogListV2Metadata struct {
 	NextOffset []string `json:"next_offset"`
 }
 
+// UnmarshalJSON accepts next_offset either as an array of strings (authentication logs) or as a single comma separated string (activity and telephony logs).
+func (metadata *LogListV2Metadata) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		NextOffset json.RawMessage `json:"next_offset"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	metadata.NextOffset = nil
+	if len(raw.NextOffset) == 0 || string(raw.NextOffset) == "null" {
+		return nil
+	}
+
+	var offset string
+	if err := json.Unmarshal(raw.NextOffset, &offset); err == nil {
+		if offset != "" {
+			metadata.NextOffset = strings.Split(offset, ",")
+		}
+		return nil
+	}
+	return json.Unmarshal(raw.NextOffset, &metadata.NextOffset)
+}
+
 // GetNextOffset uses response metadata to return an option that will configure a request to fetch the next page of logs. It returns nil when no more logs can be fetched.
 func (metadata LogListV2Metadata) GetNextOffset() func(params *url.Values) {
 	offset := strings.Join(metadata.NextOffset, ",")
@@ -43,9 +103,87 @@ type AuthLogResult struct {
 	Response AuthLogList `json:"response"`
 }
 
+// AuthLogAccessDevice describes the device used to access an application in an authentication log.
+type AuthLogAccessDevice struct {
+	Browser        string       `json:"browser"`
+	BrowserVersion string       `json:"browser_version"`
+	EPKey          string       `json:"epkey"`
+	FlashVersion   string       `json:"flash_version"`
+	Hostname       string       `json:"hostname"`
+	IP             string       `json:"ip"`
+	JavaVersion    string       `json:"java_version"`
+	Location       *LogLocation `json:"location"`
+	OS             string       `json:"os"`
+	OSVersion      string       `json:"os_version"`
+}
+
+// AuthLogAuthDevice describes the device used to approve an authentication.
+type AuthLogAuthDevice struct {
+	IP       string       `json:"ip"`
+	Key      string       `json:"key"`
+	Location *LogLocation `json:"location"`
+	Name     string       `json:"name"`
+}
+
+// AuthLogApplication identifies the application of an authentication log.
+type AuthLogApplication struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+}
+
+// AuthLogUser identifies the user of an authentication log.
+type AuthLogUser struct {
+	Groups []string `json:"groups"`
+	Key    string   `json:"key"`
+	Name   string   `json:"name"`
+}
+
 // An AuthLog retrieved from https://duo.com/docs/adminapi#authentication-logs
-// TODO: @Duo update this to be a struct based on the returned JSON structure of an authentication log.
-type AuthLog map[string]interface{}
+type AuthLog struct {
+	AccessDevice          *AuthLogAccessDevice `json:"access_device"`
+	Alias                 string               `json:"alias"`
+	Application           *AuthLogApplication  `json:"application"`
+	AuthDevice            *AuthLogAuthDevice   `json:"auth_device"`
+	Email                 string               `json:"email"`
+	EventType             string               `json:"event_type"`
+	Factor                string               `json:"factor"`
+	ISOTimestamp          string               `json:"isotimestamp"`
+	OODSoftware           string               `json:"ood_software"`
+	Reason                string               `json:"reason"`
+	Result                string               `json:"result"`
+	TrustedEndpointStatus string               `json:"trusted_endpoint_status"`
+	TxID                  string               `json:"txid"`
+	User                  *AuthLogUser         `json:"user"`
+
+	// Time is parsed from the timestamp field of the log.
+	Time time.Time `json:"-"`
+	// Raw holds the JSON the log was decoded from, including fields without a typed equivalent.
+	Raw json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON decodes the typed fields of the log and keeps the raw JSON.
+func (log *AuthLog) UnmarshalJSON(data []byte) error {
+	type authLog AuthLog
+	var typed authLog
+	raw, t, err := unmarshalLog(data, &typed)
+	if err != nil {
+		return err
+	}
+	*log = AuthLog(typed)
+	log.Time, log.Raw = t, raw
+	return nil
+}
+
+// MarshalJSON returns the raw JSON the log was decoded from.
+func (log AuthLog) MarshalJSON() ([]byte, error) {
+	type authLog AuthLog
+	return marshalLog(log.Raw, authLog(log))
+}
+
+// Timestamp returns the time of the log.
+func (log AuthLog) Timestamp() (time.Time, error) {
+	return logTimestamp(log.Time)
+}
 
 // An AuthLogList holds retreived logs and V2 metadata used for pagination.
 type AuthLogList struct {
@@ -57,6 +195,130 @@ type AuthLogList struct {
 // Calls GET /admin/v2/logs/authentication
 // See https://duo.com/docs/adminapi#authentication-logs
 func (c *Client) GetAuthLogs(mintime time.Time, window time.Duration, options ...func(*url.Values)) (*AuthLogResult, error) {
+	result := &AuthLogResult{}
+	if err := c.getLogsV2("/admin/v2/logs/authentication", mintime, window, options, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// V2 Activity Logs
+
+// ActivityLogResult is the structured JSON result of GetActivityLogs.
+type ActivityLogResult struct {
+	duoapi.StatResult
+	Response ActivityLogList `json:"response"`
+}
+
+// An ActivityLogList holds retrieved logs and V2 metadata used for pagination.
+type ActivityLogList struct {
+	Metadata LogListV2Metadata `json:"metadata"`
+	Logs     []ActivityLog     `json:"items"`
+}
+
+// ActivityAccessDevice describes the device an activity was performed from.
+type ActivityAccessDevice struct {
+	Browser        string `json:"browser"`
+	BrowserVersion string `json:"browser_version"`
+	IP             *struct {
+		Address string `json:"address"`
+	} `json:"ip"`
+	Location  *LogLocation `json:"location"`
+	OS        string       `json:"os"`
+	OSVersion string       `json:"os_version"`
+}
+
+// ActivityAction describes the action of an activity log.
+type ActivityAction struct {
+	// Details is passed through as returned by the API, which may be a
+	// JSON encoded string, an object or null depending on the action.
+	Details json.RawMessage `json:"details"`
+	Type    string          `json:"type"`
+}
+
+// ActivityParty describes the actor, target or application of an activity log.
+type ActivityParty struct {
+	// Details is passed through as returned by the API, which may be a
+	// JSON encoded string, an object or null depending on the party.
+	Details json.RawMessage `json:"details"`
+	Key     string          `json:"key"`
+	Name    string          `json:"name"`
+	Type    string          `json:"type"`
+}
+
+// An ActivityLog retrieved from https://duo.com/docs/adminapi#activity-logs
+type ActivityLog struct {
+	AccessDevice *ActivityAccessDevice `json:"access_device"`
+	Action       *ActivityAction       `json:"action"`
+	ActivityID   string                `json:"activity_id"`
+	Actor        *ActivityParty        `json:"actor"`
+	AKey         string                `json:"akey"`
+	Application  *ActivityParty        `json:"application"`
+	Target       *ActivityParty        `json:"target"`
+	// TS is the ISO 8601 time of the activity, see Timestamp.
+	TS string `json:"ts"`
+}
+
+// Timestamp parses the ts value of the log.
+func (log ActivityLog) Timestamp() (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, log.TS)
+}
+
+// GetActivityLogs retrieves a page of activity logs within the time range starting at mintime and ending at mintime + window. It relies on the option provided by ActivityLogResult.Response.Metadata.GetNextOffset() for pagination.
+// Calls GET /admin/v2/logs/activity
+// See https://duo.com/docs/adminapi#activity-logs
+func (c *Client) GetActivityLogs(mintime time.Time, window time.Duration, options ...func(*url.Values)) (*ActivityLogResult, error) {
+	result := &ActivityLogResult{}
+	if err := c.getLogsV2("/admin/v2/logs/activity", mintime, window, options, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// V2 Telephony Logs
+
+// TelephonyLogV2Result is the structured JSON result of GetTelephonyLogsV2.
+type TelephonyLogV2Result struct {
+	duoapi.StatResult
+	Response TelephonyLogV2List `json:"response"`
+}
+
+// A TelephonyLogV2List holds retrieved logs and V2 metadata used for pagination.
+type TelephonyLogV2List struct {
+	Metadata LogListV2Metadata `json:"metadata"`
+	Logs     []TelephonyLogV2  `json:"items"`
+}
+
+// A TelephonyLogV2 retrieved from https://duo.com/docs/adminapi#telephony-logs
+type TelephonyLogV2 struct {
+	Context     string `json:"context"`
+	Credits     int    `json:"credits"`
+	Phone       string `json:"phone"`
+	TelephonyID string `json:"telephony_id"`
+	// TS is the ISO 8601 time of the event, see Timestamp.
+	TS   string `json:"ts"`
+	TxID string `json:"txid"`
+	Type string `json:"type"`
+}
+
+// Timestamp parses the ts value of the log.
+func (log TelephonyLogV2) Timestamp() (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, log.TS)
+}
+
+// GetTelephonyLogsV2 retrieves a page of telephony logs within the time range starting at mintime and ending at mintime + window. It relies on the option provided by TelephonyLogV2Result.Response.Metadata.GetNextOffset() for pagination.
+// Calls GET /admin/v2/logs/telephony
+// See https://duo.com/docs/adminapi#telephony-logs
+func (c *Client) GetTelephonyLogsV2(mintime time.Time, window time.Duration, options ...func(*url.Values)) (*TelephonyLogV2Result, error) {
+	result := &TelephonyLogV2Result{}
+	if err := c.getLogsV2("/admin/v2/logs/telephony", mintime, window, options, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// getLogsV2 retrieves a page of logs from a V2 log endpoint for the time range starting at mintime and ending at mintime + window, and unmarshals it into result.
+func (c *Client) getLogsV2(path string, mintime time.Time, window time.Duration, options []func(*url.Values), result interface{}) error {
 	// Format mintime & maxtime parameters
 	minMs := mintime.UnixNano() / int64(time.Millisecond)
 	maxMs := mintime.Add(window).UnixNano() / int64(time.Millisecond)
@@ -74,26 +336,21 @@ func (c *Client) GetAuthLogs(mintime time.Time, window time.Duration, options ..
 		opt(&params)
 	}
 
-	// Retrieve page of authentication logs
+	// Retrieve page of logs
 	resp, body, err := c.SignedCall(
 		http.MethodGet,
-		"/admin/v2/logs/authentication",
+		path,
 		params,
 	)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("invalid HTTP response code from Duo API: [%d] %s", resp.StatusCode, resp.Status)
+		return fmt.Errorf("invalid HTTP response code from Duo API: [%d] %s", resp.StatusCode, resp.Status)
 	}
 
 	// Unmarshal received JSON into expected structure
-	result := &AuthLogResult{}
-	if err = json.Unmarshal(body, result); err != nil {
-		return nil, err
-	}
-
-	return result, nil
+	return json.Unmarshal(body, result)
 }
 
 /*
@@ -102,16 +359,20 @@ func (c *Client) GetAuthLogs(mintime time.Time, window time.Duration, options ..
 
 // parseLogV1Timestamp attempts to coerce the timestamp field of a log into a time.Time
 func parseLogV1Timestamp(log map[string]interface{}) (time.Time, error) {
-	var timestamp time.Time
-
 	// Skip nil logs
 	if log == nil {
-		return timestamp, fmt.Errorf("cannot determine timestamp of nil log")
+		return time.Time{}, fmt.Errorf("cannot determine timestamp of nil log")
 	}
 
+	return parseLogTimestamp(log["timestamp"])
+}
+
+// parseLogTimestamp attempts to coerce the value of a log timestamp field into a time.Time
+func parseLogTimestamp(untypedTimestamp interface{}) (time.Time, error) {
+	var timestamp time.Time
+
 	// Skip logs without a timestamp
-	untypedTimestamp, ok := log["timestamp"]
-	if !ok || untypedTimestamp == nil {
+	if untypedTimestamp == nil {
 		return timestamp, fmt.Errorf("failed to parse value for timestamp field from log data")
 	}
 
@@ -120,6 +381,20 @@ func parseLogV1Timestamp(log map[string]interface{}) (time.Time, error) {
 	case float64:
 		timestamp = time.Unix(int64(num), 0)
 		break
+	case json.Number:
+		return parseLogTimestamp(string(num))
+	case string:
+		// Epoch seconds, which may carry a fractional part, or an ISO 8601 timestamp.
+		if seconds, err := strconv.ParseInt(num, 10, 64); err == nil {
+			timestamp = time.Unix(seconds, 0)
+		} else if seconds, err := strconv.ParseFloat(num, 64); err == nil {
+			timestamp = time.Unix(int64(seconds), 0)
+		} else if iso, err := time.Parse(time.RFC3339Nano, num); err == nil {
+			timestamp = iso
+		} else {
+			return timestamp, fmt.Errorf("received unparseable string value %q in timestamp field from log data", num)
+		}
+		break
 	case int:
 		timestamp = time.Unix(int64(num), 0)
 		break
@@ -133,14 +408,22 @@ func parseLogV1Timestamp(log map[string]interface{}) (time.Time, error) {
 		return timestamp, fmt.Errorf("received non-integer value in parsed timestamp field from log data")
 	}
 
-	// Skip logs with zero value timestamp
-	if timestamp.IsZero() {
+	// Skip logs with zero value timestamp, including the Unix epoch
+	if timestamp.IsZero() || timestamp.Unix() == 0 {
 		return timestamp, fmt.Errorf("timestamp parsed from log data is zero")
 	}
 
 	return timestamp, nil
 }
 
+// logTimestamp returns the time parsed from a typed log, or an error if the log had no usable timestamp.
+func logTimestamp(t time.Time) (time.Time, error) {
+	if t.IsZero() {
+		return t, fmt.Errorf("failed to parse value for timestamp field from log data")
+	}
+	return t, nil
+}
+
 // getLogListV1NextOffset provides an option for pagination based on log timestamps. It returns nil when no more logs can be fetched.
 func getLogListV1NextOffset(end time.Time, timestamps ...time.Time) func(params *url.Values) {
 	// Receiving less than a full page indicates there are no more pages to fetch.
@@ -193,6 +476,109 @@ func getLogListV1NextOffset(end time.Time, timestamps ...time.Time) func(params
 	}
 }
 
+// logV1Entry identifies a log received from a V1 log endpoint by its time and a key built from its typed fields.
+type logV1Entry struct {
+	time time.Time
+	key  string
+}
+
+// logV1Key builds the key of a logV1Entry from the typed fields of a log. Unlike the raw JSON, it does not depend on the order of keys or on whitespace.
+func logV1Key(fields ...string) string {
+	return strings.Join(fields, "\x00")
+}
+
+// logV1Dedup drops logs that were already received at the boundary between pages. Pages after the first start at the latest timestamp of the previous page, so logs sharing that timestamp are received twice.
+type logV1Dedup struct {
+	boundary time.Time
+	seen     map[string]int
+}
+
+// filter reports which logs of a page have not been received before, and remembers the logs sharing the latest timestamp of the page.
+func (d *logV1Dedup) filter(entries []logV1Entry) []bool {
+	keep := make([]bool, len(entries))
+	var latest time.Time
+	for i, entry := range entries {
+		if entry.time.After(latest) {
+			latest = entry.time
+		}
+		if entry.time.Equal(d.boundary) && d.seen[entry.key] > 0 {
+			d.seen[entry.key]--
+			continue
+		}
+		keep[i] = true
+	}
+
+	// Logs at the latest timestamp will be received again with the next page.
+	d.boundary = latest
+	d.seen = map[string]int{}
+	for _, entry := range entries {
+		if entry.time.Equal(latest) {
+			d.seen[entry.key]++
+		}
+	}
+	return keep
+}
+
+// logV1Page is implemented by the log lists of V1 log endpoints so that retrieveLogsV1 can page through them.
+type logV1Page interface {
+	GetNextOffset(maxtime time.Time) func(params *url.Values)
+	entries() []logV1Entry
+}
+
+// logV1Fetcher retrieves the page of V1 logs configured by options.
+type logV1Fetcher func(options []func(*url.Values)) (logV1Page, error)
+
+// retrieveLogsV1 follows the pagination of a V1 log endpoint up to maxtime, calling keep with the index of every log of a page that was not received before and is not after maxtime.
+//
+// Each page after the first starts at the latest timestamp of the previous page. The V1 API cannot return more than a full page of logs sharing that timestamp, so a full page with a single timestamp is reported as an error instead of moving on to the next second and leaving a gap.
+func retrieveLogsV1(maxtime time.Time, options []func(*url.Values), fetch logV1Fetcher, keep func(page logV1Page, i int)) error {
+	var dedup logV1Dedup
+	var next func(*url.Values)
+	for {
+		page, err := fetch(withNextOffset(options, next))
+		if err != nil {
+			return err
+		}
+
+		entries := page.entries()
+		for i, kept := range dedup.filter(entries) {
+			if kept && !entries[i].time.After(maxtime) {
+				keep(page, i)
+			}
+		}
+
+		if next = page.GetNextOffset(maxtime); next == nil {
+			return nil
+		}
+		if single, ok := singleLogV1Timestamp(entries); ok {
+			return fmt.Errorf("more than %d logs at %s cannot be retrieved through the V1 API", maxLogV1PageSize, single.UTC().Format(time.RFC3339))
+		}
+	}
+}
+
+// singleLogV1Timestamp reports whether every log with a timestamp has the same one, and returns it.
+func singleLogV1Timestamp(entries []logV1Entry) (time.Time, bool) {
+	var single time.Time
+	for _, entry := range entries {
+		if entry.time.IsZero() {
+			continue
+		}
+		if !single.IsZero() && !entry.time.Equal(single) {
+			return time.Time{}, false
+		}
+		single = entry.time
+	}
+	return single, !single.IsZero()
+}
+
+// withNextOffset returns options followed by next, without modifying options.
+func withNextOffset(options []func(*url.Values), next func(*url.Values)) []func(*url.Values) {
+	if next == nil {
+		return options
+	}
+	return append(options[:len(options):len(options)], next)
+}
+
 // V1 Admin Logs
 
 // AdminLogResult is the structured JSON result of GetAdminLogs (for the V1 API).
@@ -202,12 +588,42 @@ type AdminLogResult struct {
 }
 
 // An AdminLog retrieved from https://duo.com/docs/adminapi#administrator-logs
-// TODO: @Duo update this to be a struct based on the returned JSON structure of an admin log.
-type AdminLog map[string]interface{}
+type AdminLog struct {
+	Action string `json:"action"`
+	// Description is a JSON encoded string whose contents depend on Action.
+	Description  string `json:"description"`
+	ISOTimestamp string `json:"isotimestamp"`
+	Object       string `json:"object"`
+	Username     string `json:"username"`
+
+	// Time is parsed from the timestamp field of the log.
+	Time time.Time `json:"-"`
+	// Raw holds the JSON the log was decoded from, including fields without a typed equivalent.
+	Raw json.RawMessage `json:"-"`
+}
 
-// Timestamp parses and coerces the timestamp value of the log.
+// UnmarshalJSON decodes the typed fields of the log and keeps the raw JSON.
+func (log *AdminLog) UnmarshalJSON(data []byte) error {
+	type adminLog AdminLog
+	var typed adminLog
+	raw, t, err := unmarshalLog(data, &typed)
+	if err != nil {
+		return err
+	}
+	*log = AdminLog(typed)
+	log.Time, log.Raw = t, raw
+	return nil
+}
+
+// MarshalJSON returns the raw JSON the log was decoded from.
+func (log AdminLog) MarshalJSON() ([]byte, error) {
+	type adminLog AdminLog
+	return marshalLog(log.Raw, adminLog(log))
+}
+
+// Timestamp returns the time of the log.
 func (log AdminLog) Timestamp() (time.Time, error) {
-	return parseLogV1Timestamp(log)
+	return logTimestamp(log.Time)
 }
 
 // An AdminLogList holds log entries and provides functionality used for pagination.
@@ -233,6 +649,14 @@ func (logs AdminLogList) GetNextOffset(maxtime time.Time) func(params *url.Value
 	return getLogListV1NextOffset(maxtime, timestamps...)
 }
 
+func (logs AdminLogList) entries() []logV1Entry {
+	entries := make([]logV1Entry, len(logs))
+	for i, log := range logs {
+		entries[i] = logV1Entry{time: log.Time, key: logV1Key(log.Action, log.Object, log.Username, log.Description)}
+	}
+	return entries
+}
+
 // GetAdminLogs retrieves a page of admin logs with timestamps starting at mintime. It relies on the option provided by AdminLogResult.Logs.GetNextOffset() for pagination.
 // Calls GET /admin/v1/logs/administrator
 // See https://duo.com/docs/adminapi#administrator-logs
@@ -273,6 +697,28 @@ func (c *Client) GetAdminLogs(mintime time.Time, options ...func(*url.Values)) (
 	return result, nil
 }
 
+// GetAllAdminLogs retrieves every admin log with timestamps from mintime to maxtime, each exactly once. It follows the pagination of GetAdminLogs, and returns an error if more logs share a timestamp than fit in a page, as the V1 API cannot return them.
+// Calls GET /admin/v1/logs/administrator
+// See https://duo.com/docs/adminapi#administrator-logs
+func (c *Client) GetAllAdminLogs(mintime, maxtime time.Time, options ...func(*url.Values)) (AdminLogList, error) {
+	var logs AdminLogList
+	cb := func(options []func(*url.Values)) (logV1Page, error) {
+		result, err := c.GetAdminLogs(mintime, options...)
+		if err != nil {
+			return nil, err
+		}
+		return result.Logs, result.Err()
+	}
+	err := retrieveLogsV1(maxtime, options, cb, func(page logV1Page, i int) {
+		logs = append(logs, page.(AdminLogList)[i])
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return logs, nil
+}
+
 // V1 Telephony Logs
 
 // TelephonyLogResult is the structured JSON result of GetTelephonyLogs (for the V1 API).
@@ -282,12 +728,41 @@ type TelephonyLogResult struct {
 }
 
 // A TelephonyLog retrieved from https://duo.com/docs/adminapi#telephony-logs
-// TODO: @Duo update this to be a struct based on the returned JSON structure of a telephony log.
-type TelephonyLog map[string]interface{}
+type TelephonyLog struct {
+	Context      string `json:"context"`
+	Credits      int    `json:"credits"`
+	ISOTimestamp string `json:"isotimestamp"`
+	Phone        string `json:"phone"`
+	Type         string `json:"type"`
+
+	// Time is parsed from the timestamp field of the log.
+	Time time.Time `json:"-"`
+	// Raw holds the JSON the log was decoded from, including fields without a typed equivalent.
+	Raw json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON decodes the typed fields of the log and keeps the raw JSON.
+func (log *TelephonyLog) UnmarshalJSON(data []byte) error {
+	type telephonyLog TelephonyLog
+	var typed telephonyLog
+	raw, t, err := unmarshalLog(data, &typed)
+	if err != nil {
+		return err
+	}
+	*log = TelephonyLog(typed)
+	log.Time, log.Raw = t, raw
+	return nil
+}
 
-// Timestamp parses and coerces the timestamp value of the log.
+// MarshalJSON returns the raw JSON the log was decoded from.
+func (log TelephonyLog) MarshalJSON() ([]byte, error) {
+	type telephonyLog TelephonyLog
+	return marshalLog(log.Raw, telephonyLog(log))
+}
+
+// Timestamp returns the time of the log.
 func (log TelephonyLog) Timestamp() (time.Time, error) {
-	return parseLogV1Timestamp(log)
+	return logTimestamp(log.Time)
 }
 
 // An TelephonyLogList holds log entries and provides functionality used for pagination.
@@ -313,6 +788,14 @@ func (logs TelephonyLogList) GetNextOffset(maxtime time.Time) func(params *url.V
 	return getLogListV1NextOffset(maxtime, timestamps...)
 }
 
+func (logs TelephonyLogList) entries() []logV1Entry {
+	entries := make([]logV1Entry, len(logs))
+	for i, log := range logs {
+		entries[i] = logV1Entry{time: log.Time, key: logV1Key(log.Context, strconv.Itoa(log.Credits), log.Phone, log.Type)}
+	}
+	return entries
+}
+
 // GetTelephonyLogs retrieves a page of telephony logs with timestamps starting at mintime. It relies on the option provided by TelephonyLogResult.Logs.GetNextOffset() for pagination.
 // Calls GET /admin/v1/logs/telephony
 // See https://duo.com/docs/adminapi#telephony-logs
@@ -352,3 +835,181 @@ func (c *Client) GetTelephonyLogs(mintime time.Time, options ...func(*url.Values
 
 	return result, nil
 }
+
+// GetAllTelephonyLogs retrieves every telephony log with timestamps from mintime to maxtime, each exactly once. It follows the pagination of GetTelephonyLogs, and returns an error if more logs share a timestamp than fit in a page, as the V1 API cannot return them.
+// Calls GET /admin/v1/logs/telephony
+// See https://duo.com/docs/adminapi#telephony-logs
+func (c *Client) GetAllTelephonyLogs(mintime, maxtime time.Time, options ...func(*url.Values)) (TelephonyLogList, error) {
+	var logs TelephonyLogList
+	cb := func(options []func(*url.Values)) (logV1Page, error) {
+		result, err := c.GetTelephonyLogs(mintime, options...)
+		if err != nil {
+			return nil, err
+		}
+		return result.Logs, result.Err()
+	}
+	err := retrieveLogsV1(maxtime, options, cb, func(page logV1Page, i int) {
+		logs = append(logs, page.(TelephonyLogList)[i])
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return logs, nil
+}
+
+// V1 Offline Enrollment Logs
+
+// OfflineEnrollmentLogResult is the structured JSON result of GetOfflineEnrollmentLogs (for the V1 API).
+type OfflineEnrollmentLogResult struct {
+	duoapi.StatResult
+	Logs OfflineEnrollmentLogList `json:"response"`
+}
+
+// An OfflineEnrollmentLog retrieved from https://duo.com/docs/adminapi#offline-enrollment-logs
+type OfflineEnrollmentLog struct {
+	// Action is one of "o2fa_user_provisioned", "o2fa_user_deprovisioned" or "o2fa_user_reenrolled".
+	Action string `json:"action"`
+	// Description is a JSON encoded string, see ParseDescription.
+	Description  string `json:"description"`
+	ISOTimestamp string `json:"isotimestamp"`
+	// Object is the name of the Windows Logon integration.
+	Object   string `json:"object"`
+	Username string `json:"username"`
+
+	// Time is parsed from the timestamp field of the log.
+	Time time.Time `json:"-"`
+	// Raw holds the JSON the log was decoded from, including fields without a typed equivalent.
+	Raw json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON decodes the typed fields of the log and keeps the raw JSON.
+func (log *OfflineEnrollmentLog) UnmarshalJSON(data []byte) error {
+	type offlineEnrollmentLog OfflineEnrollmentLog
+	var typed offlineEnrollmentLog
+	raw, t, err := unmarshalLog(data, &typed)
+	if err != nil {
+		return err
+	}
+	*log = OfflineEnrollmentLog(typed)
+	log.Time, log.Raw = t, raw
+	return nil
+}
+
+// MarshalJSON returns the raw JSON the log was decoded from.
+func (log OfflineEnrollmentLog) MarshalJSON() ([]byte, error) {
+	type offlineEnrollmentLog OfflineEnrollmentLog
+	return marshalLog(log.Raw, offlineEnrollmentLog(log))
+}
+
+// OfflineEnrollmentDescription holds the details encoded in the description of an offline enrollment log.
+type OfflineEnrollmentDescription struct {
+	Factor    string `json:"factor"`
+	Hostname  string `json:"hostname"`
+	UserAgent string `json:"user_agent"`
+}
+
+// Timestamp returns the time of the log.
+func (log OfflineEnrollmentLog) Timestamp() (time.Time, error) {
+	return logTimestamp(log.Time)
+}
+
+// ParseDescription decodes the JSON encoded description of the log.
+func (log OfflineEnrollmentLog) ParseDescription() (OfflineEnrollmentDescription, error) {
+	var description OfflineEnrollmentDescription
+	err := json.Unmarshal([]byte(log.Description), &description)
+	return description, err
+}
+
+// An OfflineEnrollmentLogList holds log entries and provides functionality used for pagination.
+type OfflineEnrollmentLogList []OfflineEnrollmentLog
+
+// GetNextOffset uses log timestamps to return an option that will configure a request to fetch the next page of logs. It returns nil when no more logs can be fetched.
+func (logs OfflineEnrollmentLogList) GetNextOffset(maxtime time.Time) func(params *url.Values) {
+	// Receiving less than a full page indicates there are no more pages to fetch.
+	if len(logs) < maxLogV1PageSize {
+		return nil
+	}
+
+	// Gather log timestamps
+	timestamps := make([]time.Time, 0, len(logs))
+	for _, log := range logs {
+		ts, err := log.Timestamp()
+		if err != nil {
+			continue
+		}
+		timestamps = append(timestamps, ts)
+	}
+
+	return getLogListV1NextOffset(maxtime, timestamps...)
+}
+
+func (logs OfflineEnrollmentLogList) entries() []logV1Entry {
+	entries := make([]logV1Entry, len(logs))
+	for i, log := range logs {
+		entries[i] = logV1Entry{time: log.Time, key: logV1Key(log.Action, log.Object, log.Username, log.Description)}
+	}
+	return entries
+}
+
+// GetOfflineEnrollmentLogs retrieves a page of offline enrollment logs with timestamps starting at mintime. It relies on the option provided by OfflineEnrollmentLogResult.Logs.GetNextOffset() for pagination.
+// Calls GET /admin/v1/logs/offline_enrollment
+// See https://duo.com/docs/adminapi#offline-enrollment-logs
+func (c *Client) GetOfflineEnrollmentLogs(mintime time.Time, options ...func(*url.Values)) (*OfflineEnrollmentLogResult, error) {
+	// Format mintime parameter
+	min := mintime.UnixNano() / int64(time.Second)
+	mintimeStr := strconv.FormatInt(min, 10)
+
+	// Request defaults
+	params := url.Values{
+		"mintime": []string{mintimeStr},
+	}
+
+	// Configure request with additional options
+	for _, opt := range options {
+		opt(&params)
+	}
+
+	// Retrieve page of offline enrollment logs
+	resp, body, err := c.SignedCall(
+		http.MethodGet,
+		"/admin/v1/logs/offline_enrollment",
+		params,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("invalid HTTP response code from Duo API: [%d] %s", resp.StatusCode, resp.Status)
+	}
+
+	// Unmarshal received JSON into expected structure
+	result := &OfflineEnrollmentLogResult{}
+	if err = json.Unmarshal(body, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetAllOfflineEnrollmentLogs retrieves every offline enrollment log with timestamps from mintime to maxtime, each exactly once. It follows the pagination of GetOfflineEnrollmentLogs, and returns an error if more logs share a timestamp than fit in a page, as the V1 API cannot return them.
+// Calls GET /admin/v1/logs/offline_enrollment
+// See https://duo.com/docs/adminapi#offline-enrollment-logs
+func (c *Client) GetAllOfflineEnrollmentLogs(mintime, maxtime time.Time, options ...func(*url.Values)) (OfflineEnrollmentLogList, error) {
+	var logs OfflineEnrollmentLogList
+	cb := func(options []func(*url.Values)) (logV1Page, error) {
+		result, err := c.GetOfflineEnrollmentLogs(mintime, options...)
+		if err != nil {
+			return nil, err
+		}
+		return result.Logs, result.Err()
+	}
+	err := retrieveLogsV1(maxtime, options, cb, func(page logV1Page, i int) {
+		logs = append(logs, page.(OfflineEnrollmentLogList)[i])
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return logs, nil
+}
@@ -1,10 +1,15 @@
 package admin
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -48,6 +53,260 @@ func TestParseLogV1Timestamp(t *testing.T) {
 	}
 }
 
+// TestParseLogTimestamp ensures that numeric and string epoch seconds as well as ISO 8601 timestamps are parsed.
+func TestParseLogTimestamp(t *testing.T) {
+	expected := time.Unix(1346172820, 0)
+	for _, value := range []interface{}{
+		float64(1346172820),
+		int64(1346172820),
+		json.Number("1346172820"),
+		"1346172820",
+		"1346172820.5",
+		"2012-08-28T16:53:40+00:00",
+		"2012-08-28T12:53:40.000000-04:00",
+	} {
+		timestamp, err := parseLogTimestamp(value)
+		if err != nil {
+			t.Errorf("Failed to parse log timestamp %#v: %v", value, err)
+			continue
+		}
+		if !timestamp.Equal(expected) {
+			t.Errorf("Parsed incorrect value for log timestamp %#v, expected %v but got: %v", value, expected, timestamp)
+		}
+	}
+
+	for _, value := range []interface{}{nil, "", "yesterday", true, float64(0)} {
+		if _, err := parseLogTimestamp(value); err == nil {
+			t.Errorf("Expected an error parsing log timestamp %#v, but got nil", value)
+		}
+	}
+}
+
+// TestLogV1TimeFromJSON ensures that typed logs parse string timestamps and fall back to the ISO timestamp.
+func TestLogV1TimeFromJSON(t *testing.T) {
+	expected := time.Unix(1346172820, 0)
+	for _, data := range []string{
+		`{"action": "user_update", "timestamp": "1346172820"}`,
+		`{"action": "user_update", "isotimestamp": "2012-08-28T16:53:40+00:00"}`,
+		`{"action": "user_update", "timestamp": null, "isotimestamp": "2012-08-28T16:53:40+00:00"}`,
+	} {
+		var log AdminLog
+		if err := json.Unmarshal([]byte(data), &log); err != nil {
+			t.Errorf("Unexpected error unmarshalling %s: %v", data, err)
+			continue
+		}
+		if timestamp, err := log.Timestamp(); err != nil || !timestamp.Equal(expected) {
+			t.Errorf("Expected timestamp %v for %s, but got %v (%v)", expected, data, timestamp, err)
+		}
+	}
+}
+
+// TestLogV1Dedup ensures that only logs repeated from the latest timestamp of the previous page are dropped.
+func TestLogV1Dedup(t *testing.T) {
+	t1 := time.Unix(1346172820, 0)
+	t2 := t1.Add(time.Second)
+	var dedup logV1Dedup
+
+	first := dedup.filter([]logV1Entry{{t1, "a"}, {t2, "b"}, {t2, "c"}, {t2, "c"}})
+	if !reflect.DeepEqual(first, []bool{true, true, true, true}) {
+		t.Errorf("Expected every log of the first page to be kept, but got %v", first)
+	}
+
+	// b and one c are repeated, the second c is the same log received again, d is new
+	second := dedup.filter([]logV1Entry{{t2, "b"}, {t2, "c"}, {t2, "d"}, {t2, "c"}})
+	if want := []bool{false, false, true, false}; !reflect.DeepEqual(second, want) {
+		t.Errorf("Expected %v, but got %v", want, second)
+	}
+
+	// Only logs at the boundary timestamp are considered repeated
+	third := dedup.filter([]logV1Entry{{t2.Add(time.Second), "b"}})
+	if !reflect.DeepEqual(third, []bool{true}) {
+		t.Errorf("Expected a log at a new timestamp to be kept, but got %v", third)
+	}
+}
+
+// serveLogsV1 serves logs like a V1 log endpoint: the first page of up to 1000 logs with timestamps at or after mintime, oldest first.
+func serveLogsV1(t *testing.T, logs []map[string]interface{}, requests *int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		mintime, err := strconv.ParseInt(r.URL.Query().Get("mintime"), 10, 64)
+		if err != nil {
+			t.Fatalf("Invalid mintime in request: %v", err)
+		}
+		page := []map[string]interface{}{}
+		for _, log := range logs {
+			if log["timestamp"].(int64) >= mintime && len(page) < maxLogV1PageSize {
+				page = append(page, log)
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"stat": "OK", "response": page})
+	})
+}
+
+// TestGetAllLogsV1 ensures that every log in the time range is retrieved exactly once across pages.
+func TestGetAllLogsV1(t *testing.T) {
+	start := time.Unix(1346172000, 0)
+
+	// 2500 logs, three per second, so pages end part way through a second
+	logs := make([]map[string]interface{}, 0, 2500)
+	for i := 0; i < 2500; i++ {
+		logs = append(logs, map[string]interface{}{
+			"timestamp": start.Unix() + int64(i/3),
+			"object":    fmt.Sprintf("log-%d", i),
+			"phone":     fmt.Sprintf("+1555%07d", i),
+		})
+	}
+	// The range ends with log-2399, the last of its second
+	maxtime := start.Add(799 * time.Second)
+
+	retrievers := map[string]func(c *Client) ([]string, error){
+		"admin": func(c *Client) ([]string, error) {
+			result, err := c.GetAllAdminLogs(start, maxtime)
+			objects := []string{}
+			for _, log := range result {
+				objects = append(objects, log.Object)
+			}
+			return objects, err
+		},
+		"telephony": func(c *Client) ([]string, error) {
+			result, err := c.GetAllTelephonyLogs(start, maxtime)
+			objects := []string{}
+			for _, log := range result {
+				objects = append(objects, fmt.Sprintf("log-%d", mustAtoi(t, log.Phone[5:])))
+			}
+			return objects, err
+		},
+		"offline enrollment": func(c *Client) ([]string, error) {
+			result, err := c.GetAllOfflineEnrollmentLogs(start, maxtime)
+			objects := []string{}
+			for _, log := range result {
+				objects = append(objects, log.Object)
+			}
+			return objects, err
+		},
+	}
+
+	for name, retrieve := range retrievers {
+		requests := 0
+		ts := httptest.NewTLSServer(serveLogsV1(t, logs, &requests))
+		duo := buildAdminClient(ts.URL, nil)
+
+		objects, err := retrieve(duo)
+		ts.Close()
+		if err != nil {
+			t.Errorf("Unexpected error retrieving all %s logs: %v", name, err)
+			continue
+		}
+		if requests != 3 {
+			t.Errorf("Expected 3 requests for %s logs, but got %d", name, requests)
+		}
+		if len(objects) != 2400 {
+			t.Errorf("Expected 2400 %s logs, but got %d", name, len(objects))
+		}
+		counts := map[string]int{}
+		for _, object := range objects {
+			counts[object]++
+		}
+		for i := 0; i < 2400; i++ {
+			if object := fmt.Sprintf("log-%d", i); counts[object] != 1 {
+				t.Errorf("Expected %s log %s exactly once, but got it %d times", name, object, counts[object])
+			}
+		}
+	}
+}
+
+func mustAtoi(t *testing.T, s string) int {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		t.Fatalf("Invalid number %q: %v", s, err)
+	}
+	return n
+}
+
+// TestGetAllLogsV1Reformatted ensures that logs repeated at a page boundary are dropped even when their JSON is formatted differently.
+func TestGetAllLogsV1Reformatted(t *testing.T) {
+	start := time.Unix(1346172000, 0)
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logs := []string{}
+			if r.URL.Query().Get("mintime") == strconv.FormatInt(start.Unix(), 10) {
+				// 998 logs in the first second, then the first 2 logs of the next
+				for i := 0; i < maxLogV1PageSize; i++ {
+					logs = append(logs, fmt.Sprintf(`{"action":"user_update","object":"log-%d","timestamp":%d}`, i, start.Unix()+int64(i/998)))
+				}
+			} else {
+				// The same 2 logs with different key order and whitespace, then a new one
+				for i := 998; i < 1001; i++ {
+					logs = append(logs, fmt.Sprintf(`{ "timestamp" : %d,  "object" : "log-%d", "action" : "user_update" }`, start.Unix()+1, i))
+				}
+			}
+			fmt.Fprintf(w, `{"stat": "OK", "response": [%s]}`, strings.Join(logs, ","))
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.GetAllAdminLogs(start, start.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Unexpected error retrieving all admin logs: %v", err)
+	}
+	if len(result) != 1001 {
+		t.Errorf("Expected 1001 admin logs, but got %d", len(result))
+	}
+	counts := map[string]int{}
+	for _, log := range result {
+		counts[log.Object]++
+	}
+	for i := 0; i < 1001; i++ {
+		if object := fmt.Sprintf("log-%d", i); counts[object] != 1 {
+			t.Errorf("Expected admin log %s exactly once, but got it %d times", object, counts[object])
+		}
+	}
+}
+
+// TestGetAllLogsV1Overflow ensures that logs which cannot be retrieved because more than a page share one second are reported rather than skipped.
+func TestGetAllLogsV1Overflow(t *testing.T) {
+	start := time.Unix(1346172000, 0)
+	logs := make([]map[string]interface{}, 0, 1300)
+	for i := 0; i < 1300; i++ {
+		second := int64(0)
+		if i >= 1200 {
+			second = 1
+		}
+		logs = append(logs, map[string]interface{}{
+			"timestamp": start.Unix() + second,
+			"object":    fmt.Sprintf("log-%d", i),
+		})
+	}
+
+	requests := 0
+	ts := httptest.NewTLSServer(serveLogsV1(t, logs, &requests))
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	if result, err := duo.GetAllAdminLogs(start, start.Add(time.Hour)); err == nil {
+		t.Errorf("Expected an error when more than a page of logs share one second, but got %d logs", len(result))
+	}
+}
+
+// TestGetAllLogsV1Error ensures that a failed page is reported.
+func TestGetAllLogsV1Error(t *testing.T) {
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, `{"stat": "FAIL", "code": 42901, "message": "Too Many Requests"}`)
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	if _, err := duo.GetAllAdminLogs(time.Unix(1346172000, 0), time.Unix(1346173000, 0)); err == nil {
+		t.Errorf("Expected an error from GetAllAdminLogs for a failed page, but got nil")
+	}
+}
+
 // getAuthLogsResponse is an example response from the Duo API documentation example: https://duo.com/docs/adminapi#authentication-logs
 const getAuthLogsResponse = `{
     "response": {
@@ -134,9 +393,28 @@ func TestGetAuthLogs(t *testing.T) {
 	if length := len(result.Response.Logs); length != 1 {
 		t.Errorf("Expected 1 log, but got %d", length)
 	}
-	if txid := result.Response.Logs[0]["txid"]; txid != "340a23e3-23f3-23c1-87dc-1491a23dfdbb" {
+	if txid := result.Response.Logs[0].TxID; txid != "340a23e3-23f3-23c1-87dc-1491a23dfdbb" {
 		t.Errorf("Expected txid '340a23e3-23f3-23c1-87dc-1491a23dfdbb', but got %v", txid)
 	}
+	log := result.Response.Logs[0]
+	if log.Factor != "duo_push" || log.Result != "success" || log.Reason != "user_approved" {
+		t.Errorf("Unexpected factor, result or reason in %+v", log)
+	}
+	if log.AccessDevice == nil || log.AccessDevice.Location == nil || log.AccessDevice.Location.City != "Ann Arbor" {
+		t.Errorf("Unexpected access device %+v", log.AccessDevice)
+	}
+	if log.AuthDevice == nil || log.AuthDevice.Name != "My iPhone X (734-555-2342)" {
+		t.Errorf("Unexpected auth device %+v", log.AuthDevice)
+	}
+	if log.Application == nil || log.Application.Key != "DIY231J8BR23QK4UKBY8" {
+		t.Errorf("Unexpected application %+v", log.Application)
+	}
+	if log.User == nil || log.User.Name != "narroway@example.com" {
+		t.Errorf("Unexpected user %+v", log.User)
+	}
+	if expectedTs := time.Unix(1532951962, 0); !log.Time.Equal(expectedTs) {
+		t.Errorf("Expected time %v, but got: %v", expectedTs, log.Time)
+	}
 	if next := result.Response.Metadata.GetNextOffset(); next == nil {
 		t.Errorf("Expected metadata.GetNextOffset option to configure pagination for next request, got nil")
 	}
@@ -153,6 +431,265 @@ func TestGetAuthLogs(t *testing.T) {
 	}
 }
 
+// TestLogListV2MetadataUnmarshal ensures that next_offset is accepted both as an array and as a comma separated string.
+func TestLogListV2MetadataUnmarshal(t *testing.T) {
+	cases := map[string][]string{
+		`{"next_offset": ["1532951895000", "af0ba235-0b33-23c8-bc23-a31aa0231de8"]}`: {"1532951895000", "af0ba235-0b33-23c8-bc23-a31aa0231de8"},
+		`{"next_offset": "1666714065304,5bf1a860-fe39-49e3-be29-217659663a74"}`:      {"1666714065304", "5bf1a860-fe39-49e3-be29-217659663a74"},
+		`{"next_offset": ""}`:   nil,
+		`{"next_offset": null}`: nil,
+		`{}`:                    nil,
+	}
+	for data, want := range cases {
+		var metadata LogListV2Metadata
+		if err := json.Unmarshal([]byte(data), &metadata); err != nil {
+			t.Errorf("Unexpected error unmarshalling %s: %v", data, err)
+			continue
+		}
+		if !reflect.DeepEqual(metadata.NextOffset, want) {
+			t.Errorf("Unmarshalling %s: expected next offset %q, but got %q", data, want, metadata.NextOffset)
+		}
+	}
+}
+
+// TestAuthLogRawJSON ensures that fields without a typed equivalent remain available from the raw JSON of a log.
+func TestAuthLogRawJSON(t *testing.T) {
+	data := `{"txid": "340a23e3", "timestamp": 1532951962, "adaptive_trust_assessments": {"more_secure_auth": {"detected_attack_detectors": []}}}`
+
+	var log AuthLog
+	if err := json.Unmarshal([]byte(data), &log); err != nil {
+		t.Fatalf("Unexpected error unmarshalling log: %v", err)
+	}
+	if log.TxID != "340a23e3" {
+		t.Errorf("Expected txid '340a23e3', but got %q", log.TxID)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(log.Raw, &fields); err != nil {
+		t.Fatalf("Unexpected error unmarshalling raw log: %v", err)
+	}
+	if _, ok := fields["adaptive_trust_assessments"]; !ok {
+		t.Errorf("Expected raw log to keep untyped fields, but got %v", fields)
+	}
+
+	encoded, err := json.Marshal(log)
+	if err != nil {
+		t.Fatalf("Unexpected error marshalling log: %v", err)
+	}
+	compacted := &bytes.Buffer{}
+	json.Compact(compacted, []byte(data))
+	if string(encoded) != compacted.String() {
+		t.Errorf("Expected log to marshal to its raw JSON %s, but got %s", compacted, encoded)
+	}
+
+	var missing AuthLog
+	if err := json.Unmarshal([]byte(`{"txid": "340a23e3"}`), &missing); err != nil {
+		t.Fatalf("Unexpected error unmarshalling log: %v", err)
+	}
+	if _, err := missing.Timestamp(); err == nil {
+		t.Errorf("Expected an error for a log without a timestamp, but got nil")
+	}
+}
+
+// getActivityLogsResponse is an example response from the Duo API documentation example: https://duo.com/docs/adminapi#activity-logs
+const getActivityLogsResponse = `{
+	"stat": "OK",
+	"response": {
+		"items": [
+			{
+				"access_device": {
+					"browser": "Chrome",
+					"browser_version": "109.0.0.0",
+					"ip": {
+						"address": "192.168.0.1"
+					},
+					"location": {
+						"city": "Ann Arbor",
+						"country": "United States",
+						"state": "Michigan"
+					},
+					"os": "Mac OS X",
+					"os_version": "10.15.7"
+				},
+				"action": {
+					"details": null,
+					"type": "admin_login"
+				},
+				"activity_id": "1b8d7a52-2f73-4a33-9f9c-cb3f7e0e52f2",
+				"actor": {
+					"details": "{\"created_by\": \"API\"}",
+					"key": "DEWGH6P2UD4PDP00L4ZG",
+					"name": "Jane Smith",
+					"type": "admin"
+				},
+				"akey": "DA9VZOC8CWLFEJFKHR1U",
+				"application": null,
+				"target": {
+					"details": null,
+					"key": "DEWGH6P2UD4PDP00L4ZG",
+					"name": "Jane Smith",
+					"type": "admin"
+				},
+				"ts": "2023-03-01T16:38:42.123456+00:00"
+			}
+		],
+		"metadata": {
+			"next_offset": "1677688722123,1b8d7a52-2f73-4a33-9f9c-cb3f7e0e52f2",
+			"total_objects": {
+				"relation": "eq",
+				"value": 1
+			}
+		}
+	}
+}`
+
+// TestGetActivityLogs ensures proper functionality of the client.GetActivityLogs method.
+func TestGetActivityLogs(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, getActivityLogsResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	mintime := time.Unix(1677688700, 0)
+	window := 30 * time.Second
+	result, err := duo.GetActivityLogs(mintime, window)
+	if err != nil {
+		t.Fatalf("Unexpected error from GetActivityLogs call: %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if length := len(result.Response.Logs); length != 1 {
+		t.Fatalf("Expected 1 log, but got %d", length)
+	}
+	log := result.Response.Logs[0]
+	if log.Action == nil || log.Action.Type != "admin_login" {
+		t.Errorf("Unexpected action %+v", log.Action)
+	}
+	if log.Actor == nil || log.Actor.Name != "Jane Smith" || string(log.Actor.Details) != `"{\"created_by\": \"API\"}"` {
+		t.Errorf("Unexpected actor %+v", log.Actor)
+	}
+	if log.Application != nil {
+		t.Errorf("Expected no application, but got %+v", log.Application)
+	}
+	if log.AccessDevice == nil || log.AccessDevice.IP == nil || log.AccessDevice.IP.Address != "192.168.0.1" {
+		t.Errorf("Unexpected access device %+v", log.AccessDevice)
+	}
+	timestamp, err := log.Timestamp()
+	if err != nil {
+		t.Errorf("Failed to parse timestamp: %v", err)
+	}
+	if expectedTs := time.Unix(1677688722, 123456000); !expectedTs.Equal(timestamp) {
+		t.Errorf("Expected timestamp %v, but got: %v", expectedTs, timestamp)
+	}
+
+	next := result.Response.Metadata.GetNextOffset()
+	if next == nil {
+		t.Fatalf("Expected metadata.GetNextOffset option to configure pagination for next request, got nil")
+	}
+	params := url.Values{}
+	next(&params)
+	if offset := params.Get("next_offset"); offset != "1677688722123,1b8d7a52-2f73-4a33-9f9c-cb3f7e0e52f2" {
+		t.Errorf("Expected next offset to round trip, but got %q", offset)
+	}
+
+	if last_request.URL.Path != "/admin/v2/logs/activity" {
+		t.Errorf("Unexpected request path %s", last_request.URL.Path)
+	}
+	request_query := last_request.URL.Query()
+	if qMintime := request_query.Get("mintime"); qMintime != "1677688700000" {
+		t.Errorf("Expected to see a mintime of 1677688700000 in request, but got %q", qMintime)
+	}
+	if qMaxtime := request_query.Get("maxtime"); qMaxtime != "1677688730000" {
+		t.Errorf("Expected to see a maxtime of 1677688730000 in request, but got %q", qMaxtime)
+	}
+}
+
+// getTelephonyLogsV2Response is an example response from the Duo API documentation example: https://duo.com/docs/adminapi#telephony-logs
+const getTelephonyLogsV2Response = `{
+	"stat": "OK",
+	"response": {
+		"items": [
+			{
+				"context": "administrator login",
+				"credits": 1,
+				"phone": "+15035550100",
+				"telephony_id": "220f9ac8-ae53-4b32-9e58-bdc4b4e2f5a5",
+				"ts": "2022-10-25T16:07:45.304526+00:00",
+				"txid": "2d3e9c0a-2c3b-4a2f-8b59-5e4e91c1c3a4",
+				"type": "sms"
+			}
+		],
+		"metadata": {
+			"next_offset": "1666714065304,5bf1a860-fe39-49e3-be29-217659663a74",
+			"total_objects": {
+				"relation": "eq",
+				"value": 1
+			}
+		}
+	}
+}`
+
+// TestGetTelephonyLogsV2 ensures proper functionality of the client.GetTelephonyLogsV2 method.
+func TestGetTelephonyLogsV2(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, getTelephonyLogsV2Response)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	lastMetadata := LogListV2Metadata{
+		NextOffset: []string{"1666714000000", "0c1a2b3c-fe39-49e3-be29-217659663a74"},
+	}
+	mintime := time.Unix(1666714000, 0)
+	window := time.Minute
+	result, err := duo.GetTelephonyLogsV2(mintime, window, lastMetadata.GetNextOffset())
+	if err != nil {
+		t.Fatalf("Unexpected error from GetTelephonyLogsV2 call: %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if length := len(result.Response.Logs); length != 1 {
+		t.Fatalf("Expected 1 log, but got %d", length)
+	}
+	log := result.Response.Logs[0]
+	if log.Type != "sms" || log.Credits != 1 || log.Context != "administrator login" {
+		t.Errorf("Unexpected log %+v", log)
+	}
+	timestamp, err := log.Timestamp()
+	if err != nil {
+		t.Errorf("Failed to parse timestamp: %v", err)
+	}
+	if expectedTs := time.Unix(1666714065, 304526000); !expectedTs.Equal(timestamp) {
+		t.Errorf("Expected timestamp %v, but got: %v", expectedTs, timestamp)
+	}
+	if next := result.Response.Metadata.GetNextOffset(); next == nil {
+		t.Errorf("Expected metadata.GetNextOffset option to configure pagination for next request, got nil")
+	}
+
+	if last_request.URL.Path != "/admin/v2/logs/telephony" {
+		t.Errorf("Unexpected request path %s", last_request.URL.Path)
+	}
+	request_query := last_request.URL.Query()
+	if qMaxtime := request_query.Get("maxtime"); qMaxtime != "1666714060000" {
+		t.Errorf("Expected to see a maxtime of 1666714060000 in request, but got %q", qMaxtime)
+	}
+	if qNextOffset := request_query.Get("next_offset"); qNextOffset != "1666714000000,0c1a2b3c-fe39-49e3-be29-217659663a74" {
+		t.Errorf("Expected to see the previous next_offset in request, but got %q", qNextOffset)
+	}
+}
+
 // getAdminLogsResponse is an example response from the Duo API documentation example: https://duo.com/docs/adminapi#administrator-logs
 const getAdminLogsResponse = `{
 	"stat": "OK",
@@ -198,6 +735,12 @@ func TestGetAdminLogs(t *testing.T) {
 	if length := len(result.Logs); length != 2 {
 		t.Errorf("Expected 2 logs, but got %d", length)
 	}
+	if log := result.Logs[0]; log.Action != "user_update" || log.Object != "jsmith" || log.Username != "admin" {
+		t.Errorf("Unexpected log %+v", log)
+	}
+	if object := result.Logs[1].Object; object != "" {
+		t.Errorf("Expected empty object for null, but got %q", object)
+	}
 	timestamp, err := result.Logs[0].Timestamp()
 	if err != nil {
 		t.Errorf("Failed to parse timestamp timestamp: %v", err)
@@ -228,7 +771,7 @@ func TestAdminLogsNextOffset(t *testing.T) {
 	// Ensure mintime == maxtime returns maxtime + 1
 	logs := make([]AdminLog, 0, 1000)
 	for i := 0; i < 1000; i++ {
-		logs = append(logs, AdminLog{"timestamp": 1346172816})
+		logs = append(logs, AdminLog{Time: time.Unix(1346172816, 0)})
 	}
 	result.Logs = AdminLogList(logs)
 	params := &url.Values{}
@@ -238,7 +781,7 @@ func TestAdminLogsNextOffset(t *testing.T) {
 	}
 
 	// Ensure single maxtime returns maxtime
-	result.Logs[0] = AdminLog{"timestamp": 1346172820}
+	result.Logs[0] = AdminLog{Time: time.Unix(1346172820, 0)}
 	params = &url.Values{}
 	result.Logs.GetNextOffset(maxtime)(params)
 	if newMintime := params.Get("mintime"); newMintime != "1346172820" {
@@ -284,6 +827,9 @@ func TestGetTelephonyLogs(t *testing.T) {
 	if length := len(result.Logs); length != 1 {
 		t.Errorf("Expected 1 logs, but got %d", length)
 	}
+	if log := result.Logs[0]; log.Context != "authentication" || log.Credits != 1 || log.Type != "sms" {
+		t.Errorf("Unexpected log %+v", log)
+	}
 	timestamp, err := result.Logs[0].Timestamp()
 	if err != nil {
 		t.Errorf("Failed to parse timestamp timestamp: %v", err)
@@ -314,7 +860,7 @@ func TestTelephonyLogsNextOffset(t *testing.T) {
 	// Ensure mintime == maxtime returns maxtime + 1
 	logs := make([]TelephonyLog, 0, 1000)
 	for i := 0; i < 1000; i++ {
-		logs = append(logs, TelephonyLog{"timestamp": 1346172816})
+		logs = append(logs, TelephonyLog{Time: time.Unix(1346172816, 0)})
 	}
 	result.Logs = TelephonyLogList(logs)
 	params := &url.Values{}
@@ -324,10 +870,110 @@ func TestTelephonyLogsNextOffset(t *testing.T) {
 	}
 
 	// Ensure single maxtime returns maxtime
-	result.Logs[0] = TelephonyLog{"timestamp": 1346172820}
+	result.Logs[0] = TelephonyLog{Time: time.Unix(1346172820, 0)}
 	params = &url.Values{}
 	result.Logs.GetNextOffset(maxtime)(params)
 	if newMintime := params.Get("mintime"); newMintime != "1346172820" {
 		t.Errorf("Expected new mintime to be 1346172820, got: %v", newMintime)
 	}
 }
+
+// getOfflineEnrollmentLogsResponse is an example response from the Duo API documentation example: https://duo.com/docs/adminapi#offline-enrollment-logs
+const getOfflineEnrollmentLogsResponse = `{
+	"stat": "OK",
+	"response": [{
+		"action": "o2fa_user_provisioned",
+		"description": "{\"user_agent\": \"DuoCredProv/4.0.6.413 (Windows NT 6.3.9600; x64; Server)\", \"hostname\": \"WKSW10x64\", \"factor\": \"duo_otp\"}",
+		"object": "Acme-Laptop-Logon",
+		"timestamp": 1547159890,
+		"username": "narroway"
+	}]
+}`
+
+// TestGetOfflineEnrollmentLogs ensures proper functionality of the client.GetOfflineEnrollmentLogs method.
+func TestGetOfflineEnrollmentLogs(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, getOfflineEnrollmentLogsResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	mintime := time.Unix(1547159800, 0)
+	maxtime := mintime.Add(time.Minute * 10)
+	result, err := duo.GetOfflineEnrollmentLogs(mintime)
+
+	if err != nil {
+		t.Fatalf("Unexpected error from GetOfflineEnrollmentLogs call: %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if length := len(result.Logs); length != 1 {
+		t.Fatalf("Expected 1 logs, but got %d", length)
+	}
+	log := result.Logs[0]
+	if log.Action != "o2fa_user_provisioned" || log.Object != "Acme-Laptop-Logon" || log.Username != "narroway" {
+		t.Errorf("Unexpected log %+v", log)
+	}
+	timestamp, err := log.Timestamp()
+	if err != nil {
+		t.Errorf("Failed to parse timestamp: %v", err)
+	}
+	if expectedTs := time.Unix(1547159890, 0); !expectedTs.Equal(timestamp) {
+		t.Errorf("Expected timestamp %v, but got: %v", expectedTs, timestamp)
+	}
+	description, err := log.ParseDescription()
+	if err != nil {
+		t.Errorf("Failed to parse description: %v", err)
+	}
+	if description.Hostname != "WKSW10x64" || description.Factor != "duo_otp" {
+		t.Errorf("Unexpected description %+v", description)
+	}
+	if next := result.Logs.GetNextOffset(maxtime); next != nil {
+		t.Errorf("Expected no next page available, got non-nil option")
+	}
+
+	if last_request.URL.Path != "/admin/v1/logs/offline_enrollment" {
+		t.Errorf("Unexpected request path %s", last_request.URL.Path)
+	}
+	request_query := last_request.URL.Query()
+	if qMintime := request_query["mintime"][0]; qMintime != "1547159800" {
+		t.Errorf("Expected to see a mintime of 1547159800 in request, but got %q", qMintime)
+	}
+}
+
+// TestOfflineEnrollmentLogsNextOffset ensures proper pagination functionality for OfflineEnrollmentLogResult
+func TestOfflineEnrollmentLogsNextOffset(t *testing.T) {
+	maxtime := time.Unix(1547159900, 0)
+
+	// Ensure < 1000 logs returns none
+	result := &OfflineEnrollmentLogResult{}
+	if next := result.Logs.GetNextOffset(maxtime); next != nil {
+		t.Errorf("Expected no next page available, got non-nil option")
+	}
+
+	// Ensure mintime == maxtime returns maxtime + 1
+	logs := make([]OfflineEnrollmentLog, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		logs = append(logs, OfflineEnrollmentLog{Time: time.Unix(1547159890, 0)})
+	}
+	result.Logs = OfflineEnrollmentLogList(logs)
+	params := &url.Values{}
+	result.Logs.GetNextOffset(maxtime)(params)
+	if newMintime := params.Get("mintime"); newMintime != "1547159891" {
+		t.Errorf("Expected new mintime to be 1547159891, got: %v", newMintime)
+	}
+
+	// Ensure single maxtime returns maxtime
+	result.Logs[0] = OfflineEnrollmentLog{Time: time.Unix(1547159895, 0)}
+	params = &url.Values{}
+	result.Logs.GetNextOffset(maxtime)(params)
+	if newMintime := params.Get("mintime"); newMintime != "1547159895" {
+		t.Errorf("Expected new mintime to be 1547159895, got: %v", newMintime)
+	}
+}
@@ -0,0 +1,488 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+
+	duoapi "github.com/duosecurity/duo_api_golang"
+)
+
+// Policy models a Duo policy from the v2 Policies API.
+type Policy struct {
+	IsGlobalPolicy bool           `json:"is_global_policy"`
+	PolicyKey      string         `json:"policy_key"`
+	PolicyName     string         `json:"policy_name"`
+	Sections       PolicySections `json:"sections"`
+}
+
+// PolicySections holds the settings of a policy, grouped in sections. A nil
+// section is not part of the policy, or is left unchanged by UpdatePolicy.
+// The same goes for the nil settings of a section, while a pointer to an
+// empty value, such as an empty list, is sent as such.
+//
+// Sections without a typed field are kept in Unknown, keyed by section name,
+// and written back unchanged when the sections are encoded again.
+type PolicySections struct {
+	AuthenticationMethods  *AuthenticationMethodsSection  `json:"authentication_methods,omitempty"`
+	FullDiskEncryption     *FullDiskEncryptionSection     `json:"full_disk_encryption,omitempty"`
+	MobileDeviceBiometrics *MobileDeviceBiometricsSection `json:"mobile_device_biometrics,omitempty"`
+	NewUserPolicy          *NewUserPolicySection          `json:"new_user_policy,omitempty"`
+	ScreenLock             *ScreenLockSection             `json:"screen_lock,omitempty"`
+	TamperedDevices        *TamperedDevicesSection        `json:"tampered_devices,omitempty"`
+
+	Unknown map[string]json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *PolicySections) UnmarshalJSON(data []byte) error {
+	return unmarshalSection(data, s)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s PolicySections) MarshalJSON() ([]byte, error) {
+	return marshalSection(s)
+}
+
+// AuthenticationMethodsSection controls which authentication methods users
+// may use. Fields not modelled here are kept in Unknown.
+type AuthenticationMethodsSection struct {
+	// AllowedAuthList lists methods such as "duo-push", "duo-passcode",
+	// "webauthn-platform", "webauthn-roaming", "hardware-token",
+	// "phonecall" and "sms".
+	AllowedAuthList     *[]string `json:"allowed_auth_list,omitempty"`
+	AutoRetrySMS        *bool     `json:"auto_retry_sms,omitempty"`
+	BlockedAuthList     *[]string `json:"blocked_auth_list,omitempty"`
+	RequireVerifiedPush *bool     `json:"require_verified_push,omitempty"`
+	VerifiedPushDigits  *int      `json:"verified_push_digits,omitempty"`
+
+	Unknown map[string]json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *AuthenticationMethodsSection) UnmarshalJSON(data []byte) error {
+	return unmarshalSection(data, s)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s AuthenticationMethodsSection) MarshalJSON() ([]byte, error) {
+	return marshalSection(s)
+}
+
+// FullDiskEncryptionSection controls whether devices must be encrypted.
+type FullDiskEncryptionSection struct {
+	RequireEncryption *bool `json:"require_encryption,omitempty"`
+
+	Unknown map[string]json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *FullDiskEncryptionSection) UnmarshalJSON(data []byte) error {
+	return unmarshalSection(data, s)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s FullDiskEncryptionSection) MarshalJSON() ([]byte, error) {
+	return marshalSection(s)
+}
+
+// MobileDeviceBiometricsSection controls whether Duo Push requires biometric
+// verification.
+type MobileDeviceBiometricsSection struct {
+	RequireBiometrics *bool `json:"require_biometrics,omitempty"`
+
+	Unknown map[string]json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *MobileDeviceBiometricsSection) UnmarshalJSON(data []byte) error {
+	return unmarshalSection(data, s)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s MobileDeviceBiometricsSection) MarshalJSON() ([]byte, error) {
+	return marshalSection(s)
+}
+
+// NewUserPolicySection controls what happens to users who are not enrolled.
+type NewUserPolicySection struct {
+	// NewUserBehavior is "enroll", "no-mfa" or "deny".
+	NewUserBehavior *string `json:"new_user_behavior,omitempty"`
+
+	Unknown map[string]json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *NewUserPolicySection) UnmarshalJSON(data []byte) error {
+	return unmarshalSection(data, s)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s NewUserPolicySection) MarshalJSON() ([]byte, error) {
+	return marshalSection(s)
+}
+
+// ScreenLockSection controls whether mobile devices must have a screen lock.
+type ScreenLockSection struct {
+	RequireScreenLock *bool `json:"require_screen_lock,omitempty"`
+
+	Unknown map[string]json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *ScreenLockSection) UnmarshalJSON(data []byte) error {
+	return unmarshalSection(data, s)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s ScreenLockSection) MarshalJSON() ([]byte, error) {
+	return marshalSection(s)
+}
+
+// TamperedDevicesSection controls whether jailbroken or rooted devices may
+// be used.
+type TamperedDevicesSection struct {
+	AllowTamperedDevices *bool `json:"allow_tampered_devices,omitempty"`
+
+	Unknown map[string]json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *TamperedDevicesSection) UnmarshalJSON(data []byte) error {
+	return unmarshalSection(data, s)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s TamperedDevicesSection) MarshalJSON() ([]byte, error) {
+	return marshalSection(s)
+}
+
+// unmarshalSection decodes the JSON object in data into the section pointed
+// to by s, keeping the members without a typed field in its Unknown field.
+func unmarshalSection(data []byte, s interface{}) error {
+	v := reflect.ValueOf(s).Elem()
+	plain := reflect.New(plainStruct(v.Type()))
+	unknown, err := unmarshalKnown(data, plain.Interface())
+	if err != nil {
+		return err
+	}
+	v.Set(plain.Elem().Convert(v.Type()))
+	v.FieldByName("Unknown").Set(reflect.ValueOf(unknown))
+	return nil
+}
+
+// marshalSection encodes the section s, adding the members of its Unknown
+// field.
+func marshalSection(s interface{}) ([]byte, error) {
+	v := reflect.ValueOf(s)
+	unknown := v.FieldByName("Unknown").Interface().(map[string]json.RawMessage)
+	return marshalKnown(v.Convert(plainStruct(v.Type())).Interface(), unknown)
+}
+
+// plainStruct returns a struct type with the fields of struct type t but
+// none of its methods, so that encoding/json does not call the section's
+// own MarshalJSON and UnmarshalJSON.
+func plainStruct(t reflect.Type) reflect.Type {
+	fields := make([]reflect.StructField, t.NumField())
+	for i := range fields {
+		fields[i] = t.Field(i)
+	}
+	return reflect.StructOf(fields)
+}
+
+// unmarshalKnown decodes the JSON object in data into v, a pointer to a
+// struct, and returns the members of the object that do not correspond to
+// one of v's fields.
+func unmarshalKnown(data []byte, v interface{}) (map[string]json.RawMessage, error) {
+	var members map[string]json.RawMessage
+	err := json.Unmarshal(data, &members)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(data, v)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range jsonFieldNames(reflect.TypeOf(v).Elem()) {
+		delete(members, name)
+	}
+	if len(members) == 0 {
+		return nil, nil
+	}
+	return members, nil
+}
+
+// marshalKnown encodes the struct v as a JSON object, adding the members in
+// unknown that v does not set itself.
+func marshalKnown(v interface{}, unknown map[string]json.RawMessage) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(unknown) == 0 {
+		return data, err
+	}
+
+	var members map[string]json.RawMessage
+	err = json.Unmarshal(data, &members)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range unknown {
+		if _, ok := members[name]; !ok {
+			members[name] = value
+		}
+	}
+	return json.Marshal(members)
+}
+
+// jsonFieldNames returns the JSON member names of the fields of struct type t.
+func jsonFieldNames(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// GetPoliciesResult models responses containing a list of policies.
+type GetPoliciesResult struct {
+	duoapi.StatResult
+	ListResult
+	Response []Policy
+}
+
+func (result *GetPoliciesResult) getResponse() interface{} {
+	return result.Response
+}
+
+func (result *GetPoliciesResult) appendResponse(policies interface{}) {
+	asserted_policies := policies.([]Policy)
+	result.Response = append(result.Response, asserted_policies...)
+}
+
+// GetPolicies calls GET /admin/v2/policies
+// See https://duo.com/docs/adminapi#retrieve-policies
+func (c *Client) GetPolicies(options ...func(*url.Values)) (*GetPoliciesResult, error) {
+	params := url.Values{}
+	for _, o := range options {
+		o(&params)
+	}
+
+	cb := func(params url.Values) (responsePage, error) {
+		return c.retrievePolicies(params)
+	}
+	response, err := c.retrieveItems(params, cb)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.(*GetPoliciesResult), nil
+}
+
+func (c *Client) retrievePolicies(params url.Values) (*GetPoliciesResult, error) {
+	jsonParams := duoapi.JSONParams{}
+	for key := range params {
+		jsonParams[key] = params.Get(key)
+	}
+
+	_, body, err := c.JSONSignedCall(http.MethodGet, "/admin/v2/policies", jsonParams, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GetPoliciesResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetPolicyResult models responses containing a single policy.
+type GetPolicyResult struct {
+	duoapi.StatResult
+	Response Policy
+}
+
+// GetPolicy calls GET /admin/v2/policies/:policy_key
+// See https://duo.com/docs/adminapi#retrieve-policy-by-key
+func (c *Client) GetPolicy(policyKey string) (*GetPolicyResult, error) {
+	path := fmt.Sprintf("/admin/v2/policies/%s", policyKey)
+
+	_, body, err := c.JSONSignedCall(http.MethodGet, path, nil, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GetPolicyResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetGlobalPolicy calls GET /admin/v2/policies/global
+// See https://duo.com/docs/adminapi#retrieve-global-policy
+func (c *Client) GetGlobalPolicy() (*GetPolicyResult, error) {
+	_, body, err := c.JSONSignedCall(http.MethodGet, "/admin/v2/policies/global", nil, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GetPolicyResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CreatePolicy calls POST /admin/v2/policies
+// Sections left nil take their default values.
+// See https://duo.com/docs/adminapi#create-policy
+func (c *Client) CreatePolicy(name string, sections PolicySections) (*GetPolicyResult, error) {
+	params := duoapi.JSONParams{
+		"policy_name": name,
+		"sections":    sections,
+	}
+
+	_, body, err := c.JSONSignedCall(http.MethodPost, "/admin/v2/policies", params, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GetPolicyResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PolicyUpdate holds the changes to make to a policy with UpdatePolicy.
+type PolicyUpdate struct {
+	// PolicyName renames the policy when set.
+	PolicyName string
+	// Sections holds the sections to change. Nil sections are left as they
+	// are.
+	Sections PolicySections
+	// SectionsToDelete names sections to reset to their default values.
+	SectionsToDelete []string
+}
+
+// UpdatePolicy calls PUT /admin/v2/policies/:policy_key
+// See https://duo.com/docs/adminapi#update-policy
+func (c *Client) UpdatePolicy(policyKey string, update PolicyUpdate) (*GetPolicyResult, error) {
+	path := fmt.Sprintf("/admin/v2/policies/%s", policyKey)
+
+	params := duoapi.JSONParams{
+		"sections": update.Sections,
+	}
+	if update.PolicyName != "" {
+		params["policy_name"] = update.PolicyName
+	}
+	if len(update.SectionsToDelete) > 0 {
+		params["sections_to_delete"] = update.SectionsToDelete
+	}
+
+	_, body, err := c.JSONSignedCall(http.MethodPut, path, params, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GetPolicyResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeletePolicy calls DELETE /admin/v2/policies/:policy_key
+// See https://duo.com/docs/adminapi#delete-policy
+func (c *Client) DeletePolicy(policyKey string) (*duoapi.StatResult, error) {
+	path := fmt.Sprintf("/admin/v2/policies/%s", policyKey)
+
+	_, body, err := c.JSONSignedCall(http.MethodDelete, path, nil, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &duoapi.StatResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CopyPolicyResult models responses containing the policies created by
+// CopyPolicy.
+type CopyPolicyResult struct {
+	duoapi.StatResult
+	Response []Policy
+}
+
+// CopyPolicy calls POST /admin/v2/policies/copy
+// One copy of the policy is made for each of newNames.
+// See https://duo.com/docs/adminapi#copy-policy
+func (c *Client) CopyPolicy(policyKey string, newNames ...string) (*CopyPolicyResult, error) {
+	params := duoapi.JSONParams{
+		"policy_key":            policyKey,
+		"new_policy_names_list": newNames,
+	}
+
+	_, body, err := c.JSONSignedCall(http.MethodPost, "/admin/v2/policies/copy", params, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CopyPolicyResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ApplyPolicyToApplications calls POST /admin/v2/policies/:policy_key/apply
+// The policy becomes the application policy of every integration in apply,
+// and stops being the application policy of every integration in unapply.
+// See https://duo.com/docs/adminapi#apply-policy-to-applications
+func (c *Client) ApplyPolicyToApplications(policyKey string, apply, unapply []string) (*duoapi.StatResult, error) {
+	path := fmt.Sprintf("/admin/v2/policies/%s/apply", policyKey)
+
+	apps := map[string][]string{}
+	if len(apply) > 0 {
+		apps["apply_list"] = apply
+	}
+	if len(unapply) > 0 {
+		apps["unapply_list"] = unapply
+	}
+	params := duoapi.JSONParams{
+		"apply_to_apps": apps,
+	}
+
+	_, body, err := c.JSONSignedCall(http.MethodPost, path, params, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &duoapi.StatResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
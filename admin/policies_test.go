@@ -0,0 +1,470 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+const policySectionsJSON = `{
+	"authentication_methods": {
+		"allowed_auth_list": ["duo-push", "webauthn-roaming"],
+		"auto_retry_sms": false,
+		"blocked_auth_list": ["sms", "phonecall"],
+		"require_verified_push": true,
+		"verified_push_digits": 6,
+		"future_setting": {"nested": [1, 2, 3]}
+	},
+	"new_user_policy": {
+		"new_user_behavior": "deny"
+	},
+	"screen_lock": {
+		"require_screen_lock": true
+	},
+	"user_location": {
+		"default_action": "allow",
+		"deny_countries": ["XX"]
+	},
+	"browsers": {
+		"blocked_browsers_list": ["flash"]
+	}
+}`
+
+func TestPolicySectionsUnmarshal(t *testing.T) {
+	var sections PolicySections
+	err := json.Unmarshal([]byte(policySectionsJSON), &sections)
+	if err != nil {
+		t.Fatalf("Unexpected error decoding sections: %v", err)
+	}
+
+	methods := sections.AuthenticationMethods
+	if methods == nil {
+		t.Fatal("Expected authentication methods to be decoded")
+	}
+	if methods.AllowedAuthList == nil || !reflect.DeepEqual(*methods.AllowedAuthList, []string{"duo-push", "webauthn-roaming"}) {
+		t.Errorf("Unexpected allowed methods %v", methods.AllowedAuthList)
+	}
+	if methods.RequireVerifiedPush == nil || !*methods.RequireVerifiedPush || methods.VerifiedPushDigits == nil || *methods.VerifiedPushDigits != 6 {
+		t.Errorf("Unexpected verified push settings %+v", methods)
+	}
+	if string(methods.Unknown["future_setting"]) != `{"nested": [1, 2, 3]}` {
+		t.Errorf("Expected the unknown setting to be kept, but got %s", methods.Unknown)
+	}
+	if sections.NewUserPolicy == nil || sections.NewUserPolicy.NewUserBehavior == nil || *sections.NewUserPolicy.NewUserBehavior != "deny" {
+		t.Errorf("Unexpected new user policy %+v", sections.NewUserPolicy)
+	}
+	if sections.ScreenLock == nil || sections.ScreenLock.Unknown != nil {
+		t.Errorf("Unexpected screen lock section %+v", sections.ScreenLock)
+	}
+	if sections.TamperedDevices != nil {
+		t.Errorf("Expected no tampered devices section, but got %+v", sections.TamperedDevices)
+	}
+	if len(sections.Unknown) != 2 || sections.Unknown["user_location"] == nil || sections.Unknown["browsers"] == nil {
+		t.Errorf("Expected the unknown sections to be kept, but got %v", sections.Unknown)
+	}
+}
+
+func TestPolicySectionsRoundTrip(t *testing.T) {
+	var sections PolicySections
+	err := json.Unmarshal([]byte(policySectionsJSON), &sections)
+	if err != nil {
+		t.Fatalf("Unexpected error decoding sections: %v", err)
+	}
+	encoded, err := json.Marshal(sections)
+	if err != nil {
+		t.Fatalf("Unexpected error encoding sections: %v", err)
+	}
+
+	var want, got interface{}
+	json.Unmarshal([]byte(policySectionsJSON), &want)
+	json.Unmarshal(encoded, &got)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Round trip changed the sections:\ngot  %s\nwant %s", encoded, policySectionsJSON)
+	}
+}
+
+// Test that settings present but empty survive a round trip, so that they
+// can be cleared with UpdatePolicy.
+func TestPolicySectionsRoundTripEmpty(t *testing.T) {
+	data := `{"authentication_methods":{"blocked_auth_list":[]},"new_user_policy":{"new_user_behavior":""}}`
+	var sections PolicySections
+	err := json.Unmarshal([]byte(data), &sections)
+	if err != nil {
+		t.Fatalf("Unexpected error decoding sections: %v", err)
+	}
+	methods := sections.AuthenticationMethods
+	if methods == nil || methods.BlockedAuthList == nil || len(*methods.BlockedAuthList) != 0 || methods.AllowedAuthList != nil {
+		t.Errorf("Unexpected authentication methods %+v", methods)
+	}
+	encoded, err := json.Marshal(sections)
+	if err != nil {
+		t.Fatalf("Unexpected error encoding sections: %v", err)
+	}
+	if string(encoded) != data {
+		t.Errorf("Round trip changed the sections:\ngot  %s\nwant %s", encoded, data)
+	}
+}
+
+func TestPolicySectionsMarshalTypedWins(t *testing.T) {
+	enabled := true
+	sections := PolicySections{
+		ScreenLock: &ScreenLockSection{
+			RequireScreenLock: &enabled,
+			Unknown: map[string]json.RawMessage{
+				"require_screen_lock": json.RawMessage(`false`),
+				"grace_period":        json.RawMessage(`30`),
+			},
+		},
+	}
+	encoded, err := json.Marshal(sections)
+	if err != nil {
+		t.Fatalf("Unexpected error encoding sections: %v", err)
+	}
+	if string(encoded) != `{"screen_lock":{"grace_period":30,"require_screen_lock":true}}` {
+		t.Errorf("Unexpected encoding %s", encoded)
+	}
+}
+
+func policyRequestBody(t *testing.T, r *http.Request) map[string]interface{} {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		t.Fatalf("Unexpected error reading request body: %v", err)
+	}
+	if len(body) == 0 {
+		return nil
+	}
+	if r.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("Expected a JSON request, but got %s", r.Header.Get("Content-Type"))
+	}
+	var params map[string]interface{}
+	err = json.Unmarshal(body, &params)
+	if err != nil {
+		t.Fatalf("Unexpected error decoding request body %s: %v", body, err)
+	}
+	return params
+}
+
+const getPolicyResponse = `{
+	"stat": "OK",
+	"response": {
+		"is_global_policy": false,
+		"policy_key": "POSTGCSD7XVSVAHNJWE3",
+		"policy_name": "Contractors",
+		"sections": {
+			"new_user_policy": {
+				"new_user_behavior": "deny"
+			},
+			"user_location": {
+				"default_action": "allow"
+			}
+		}
+	}
+}`
+
+func TestGetPolicy(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, getPolicyResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.GetPolicy("POSTGCSD7XVSVAHNJWE3")
+	if err != nil {
+		t.Errorf("Unexpected error from GetPolicy call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if result.Response.PolicyName != "Contractors" || result.Response.IsGlobalPolicy {
+		t.Errorf("Unexpected policy %+v", result.Response)
+	}
+	if result.Response.Sections.Unknown["user_location"] == nil {
+		t.Errorf("Expected the user location section to be kept")
+	}
+	if last_request.Method != http.MethodGet || last_request.URL.Path != "/admin/v2/policies/POSTGCSD7XVSVAHNJWE3" {
+		t.Errorf("Unexpected request %s %s", last_request.Method, last_request.URL.Path)
+	}
+}
+
+func TestGetGlobalPolicy(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, getPolicyResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	_, err := duo.GetGlobalPolicy()
+	if err != nil {
+		t.Errorf("Unexpected error from GetGlobalPolicy call %v", err.Error())
+	}
+	if last_request.URL.Path != "/admin/v2/policies/global" {
+		t.Errorf("Unexpected request path %s", last_request.URL.Path)
+	}
+}
+
+const getPoliciesPage1Response = `{
+	"stat": "OK",
+	"metadata": {
+		"next_offset": 1,
+		"total_objects": 2
+	},
+	"response": [{
+		"is_global_policy": true,
+		"policy_key": "POSTGCSD7XVSVAHNJWE1",
+		"policy_name": "Global Policy",
+		"sections": {}
+	}]
+}`
+
+const getPoliciesPage2Response = `{
+	"stat": "OK",
+	"metadata": {
+		"prev_offset": 0,
+		"total_objects": 2
+	},
+	"response": [{
+		"is_global_policy": false,
+		"policy_key": "POSTGCSD7XVSVAHNJWE3",
+		"policy_name": "Contractors",
+		"sections": {}
+	}]
+}`
+
+func TestGetPoliciesMultipage(t *testing.T) {
+	requests := []*http.Request{}
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(requests) == 0 {
+				fmt.Fprintln(w, getPoliciesPage1Response)
+			} else {
+				fmt.Fprintln(w, getPoliciesPage2Response)
+			}
+			requests = append(requests, r)
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.GetPolicies()
+	if err != nil {
+		t.Errorf("Expected err to be nil, found %s", err)
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("Expected two requets, found %d", len(requests))
+	}
+	if requests[0].URL.Path != "/admin/v2/policies" {
+		t.Errorf("Unexpected request path %s", requests[0].URL.Path)
+	}
+	if requests[0].URL.Query().Get("limit") != "100" || requests[1].URL.Query().Get("offset") != "1" {
+		t.Errorf("Unexpected paging parameters %v, %v", requests[0].URL.Query(), requests[1].URL.Query())
+	}
+	if len(result.Response) != 2 {
+		t.Errorf("Expected two policies in the response, found %d", len(result.Response))
+	}
+}
+
+func TestCreatePolicy(t *testing.T) {
+	var params map[string]interface{}
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			params = policyRequestBody(t, r)
+			fmt.Fprintln(w, getPolicyResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	deny := "deny"
+	result, err := duo.CreatePolicy("Contractors", PolicySections{
+		NewUserPolicy: &NewUserPolicySection{NewUserBehavior: &deny},
+		Unknown: map[string]json.RawMessage{
+			"user_location": json.RawMessage(`{"default_action":"allow"}`),
+		},
+	})
+	if err != nil {
+		t.Errorf("Unexpected error from CreatePolicy call %v", err.Error())
+	}
+	if result.Response.PolicyKey != "POSTGCSD7XVSVAHNJWE3" {
+		t.Errorf("Expected policy key POSTGCSD7XVSVAHNJWE3, but got %s", result.Response.PolicyKey)
+	}
+	if last_request.Method != http.MethodPost || last_request.URL.Path != "/admin/v2/policies" {
+		t.Errorf("Unexpected request %s %s", last_request.Method, last_request.URL.Path)
+	}
+	want := map[string]interface{}{
+		"policy_name": "Contractors",
+		"sections": map[string]interface{}{
+			"new_user_policy": map[string]interface{}{"new_user_behavior": "deny"},
+			"user_location":   map[string]interface{}{"default_action": "allow"},
+		},
+	}
+	if !reflect.DeepEqual(params, want) {
+		t.Errorf("Unexpected request body %v", params)
+	}
+}
+
+func TestUpdatePolicy(t *testing.T) {
+	var params map[string]interface{}
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			params = policyRequestBody(t, r)
+			fmt.Fprintln(w, getPolicyResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	enabled := true
+	_, err := duo.UpdatePolicy("POSTGCSD7XVSVAHNJWE3", PolicyUpdate{
+		Sections: PolicySections{
+			AuthenticationMethods: &AuthenticationMethodsSection{BlockedAuthList: &[]string{}},
+			ScreenLock:            &ScreenLockSection{RequireScreenLock: &enabled},
+		},
+		SectionsToDelete: []string{"browsers"},
+	})
+	if err != nil {
+		t.Errorf("Unexpected error from UpdatePolicy call %v", err.Error())
+	}
+	if last_request.Method != http.MethodPut || last_request.URL.Path != "/admin/v2/policies/POSTGCSD7XVSVAHNJWE3" {
+		t.Errorf("Unexpected request %s %s", last_request.Method, last_request.URL.Path)
+	}
+	want := map[string]interface{}{
+		"sections": map[string]interface{}{
+			"authentication_methods": map[string]interface{}{"blocked_auth_list": []interface{}{}},
+			"screen_lock":            map[string]interface{}{"require_screen_lock": true},
+		},
+		"sections_to_delete": []interface{}{"browsers"},
+	}
+	if !reflect.DeepEqual(params, want) {
+		t.Errorf("Unexpected request body %v", params)
+	}
+}
+
+func TestDeletePolicy(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, deleteUserResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.DeletePolicy("POSTGCSD7XVSVAHNJWE3")
+	if err != nil {
+		t.Errorf("Unexpected error from DeletePolicy call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if last_request.Method != http.MethodDelete || last_request.URL.Path != "/admin/v2/policies/POSTGCSD7XVSVAHNJWE3" {
+		t.Errorf("Unexpected request %s %s", last_request.Method, last_request.URL.Path)
+	}
+}
+
+const copyPolicyResponse = `{
+	"stat": "OK",
+	"response": [{
+		"is_global_policy": false,
+		"policy_key": "POXXXXXXXXXXXXXXXXX1",
+		"policy_name": "Contractors EU",
+		"sections": {}
+	},
+	{
+		"is_global_policy": false,
+		"policy_key": "POXXXXXXXXXXXXXXXXX2",
+		"policy_name": "Contractors US",
+		"sections": {}
+	}]
+}`
+
+func TestCopyPolicy(t *testing.T) {
+	var params map[string]interface{}
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			params = policyRequestBody(t, r)
+			fmt.Fprintln(w, copyPolicyResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.CopyPolicy("POSTGCSD7XVSVAHNJWE3", "Contractors EU", "Contractors US")
+	if err != nil {
+		t.Errorf("Unexpected error from CopyPolicy call %v", err.Error())
+	}
+	if len(result.Response) != 2 || result.Response[1].PolicyName != "Contractors US" {
+		t.Errorf("Unexpected copies %+v", result.Response)
+	}
+	if last_request.Method != http.MethodPost || last_request.URL.Path != "/admin/v2/policies/copy" {
+		t.Errorf("Unexpected request %s %s", last_request.Method, last_request.URL.Path)
+	}
+	want := map[string]interface{}{
+		"policy_key":            "POSTGCSD7XVSVAHNJWE3",
+		"new_policy_names_list": []interface{}{"Contractors EU", "Contractors US"},
+	}
+	if !reflect.DeepEqual(params, want) {
+		t.Errorf("Unexpected request body %v", params)
+	}
+}
+
+func TestApplyPolicyToApplications(t *testing.T) {
+	var params map[string]interface{}
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			params = policyRequestBody(t, r)
+			fmt.Fprintln(w, deleteUserResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.ApplyPolicyToApplications("POSTGCSD7XVSVAHNJWE3",
+		[]string{"DIXXXXXXXXXXXXXXXXX1", "DIXXXXXXXXXXXXXXXXX2"}, nil)
+	if err != nil {
+		t.Errorf("Unexpected error from ApplyPolicyToApplications call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if last_request.Method != http.MethodPost || last_request.URL.Path != "/admin/v2/policies/POSTGCSD7XVSVAHNJWE3/apply" {
+		t.Errorf("Unexpected request %s %s", last_request.Method, last_request.URL.Path)
+	}
+	want := map[string]interface{}{
+		"apply_to_apps": map[string]interface{}{
+			"apply_list": []interface{}{"DIXXXXXXXXXXXXXXXXX1", "DIXXXXXXXXXXXXXXXXX2"},
+		},
+	}
+	if !reflect.DeepEqual(params, want) {
+		t.Errorf("Unexpected request body %v", params)
+	}
+}
@@ -0,0 +1,121 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	duoapi "github.com/duosecurity/duo_api_golang"
+)
+
+// Settings models the global settings of a Duo account.
+type Settings struct {
+	CallerID                         string `json:"caller_id"`
+	EmailActivityNotificationEnabled Flag   `json:"email_activity_notification_enabled"`
+	FraudEmail                       string `json:"fraud_email"`
+	FraudEmailEnabled                Flag   `json:"fraud_email_enabled"`
+	// HelpdeskBypass is "allow", "limit" or "deny".
+	HelpdeskBypass             string `json:"helpdesk_bypass"`
+	HelpdeskBypassExpiration   int    `json:"helpdesk_bypass_expiration"`
+	HelpdeskCanSendEnrollEmail Flag   `json:"helpdesk_can_send_enroll_email"`
+	// InactiveUserExpiration is in days, or nil when inactive users are kept.
+	InactiveUserExpiration *int   `json:"inactive_user_expiration"`
+	KeypressConfirm        string `json:"keypress_confirm"`
+	KeypressFraud          string `json:"keypress_fraud"`
+	Language               string `json:"language"`
+	// LockoutExpireDuration is in minutes, or nil when locked out users stay
+	// locked out until unlocked by an administrator.
+	LockoutExpireDuration      *int    `json:"lockout_expire_duration"`
+	LockoutThreshold           int     `json:"lockout_threshold"`
+	LogRetentionDays           *int    `json:"log_retention_days"`
+	MinimumPasswordLength      int     `json:"minimum_password_length"`
+	Name                       string  `json:"name"`
+	PasswordRequiresLowerAlpha Flag    `json:"password_requires_lower_alpha"`
+	PasswordRequiresNumeric    Flag    `json:"password_requires_numeric"`
+	PasswordRequiresSpecial    Flag    `json:"password_requires_special"`
+	PasswordRequiresUpperAlpha Flag    `json:"password_requires_upper_alpha"`
+	SMSBatch                   int     `json:"sms_batch"`
+	SMSExpiration              *int    `json:"sms_expiration"`
+	SMSMessage                 string  `json:"sms_message"`
+	SMSRefresh                 Flag    `json:"sms_refresh"`
+	TelephonyWarningMin        int     `json:"telephony_warning_min"`
+	Timezone                   string  `json:"timezone"`
+	UserTelephonyCostMax       float64 `json:"user_telephony_cost_max"`
+}
+
+// SettingsUpdate holds the settings to change with ModifySettings. Only
+// non-nil fields are sent, so settings left nil keep their current value.
+// Boolean settings are Flags, sent as "1" or "0" like the other Admin API
+// parameters.
+type SettingsUpdate struct {
+	CallerID                         *string  `url:"caller_id"`
+	EmailActivityNotificationEnabled *Flag    `url:"email_activity_notification_enabled"`
+	FraudEmail                       *string  `url:"fraud_email"`
+	FraudEmailEnabled                *Flag    `url:"fraud_email_enabled"`
+	HelpdeskBypass                   *string  `url:"helpdesk_bypass"`
+	HelpdeskBypassExpiration         *int     `url:"helpdesk_bypass_expiration"`
+	HelpdeskCanSendEnrollEmail       *Flag    `url:"helpdesk_can_send_enroll_email"`
+	InactiveUserExpiration           *int     `url:"inactive_user_expiration"`
+	KeypressConfirm                  *string  `url:"keypress_confirm"`
+	KeypressFraud                    *string  `url:"keypress_fraud"`
+	Language                         *string  `url:"language"`
+	LockoutExpireDuration            *int     `url:"lockout_expire_duration"`
+	LockoutThreshold                 *int     `url:"lockout_threshold"`
+	LogRetentionDays                 *int     `url:"log_retention_days"`
+	MinimumPasswordLength            *int     `url:"minimum_password_length"`
+	Name                             *string  `url:"name"`
+	PasswordRequiresLowerAlpha       *Flag    `url:"password_requires_lower_alpha"`
+	PasswordRequiresNumeric          *Flag    `url:"password_requires_numeric"`
+	PasswordRequiresSpecial          *Flag    `url:"password_requires_special"`
+	PasswordRequiresUpperAlpha       *Flag    `url:"password_requires_upper_alpha"`
+	SMSBatch                         *int     `url:"sms_batch"`
+	SMSExpiration                    *int     `url:"sms_expiration"`
+	SMSMessage                       *string  `url:"sms_message"`
+	SMSRefresh                       *Flag    `url:"sms_refresh"`
+	TelephonyWarningMin              *int     `url:"telephony_warning_min"`
+	Timezone                         *string  `url:"timezone"`
+	UserTelephonyCostMax             *float64 `url:"user_telephony_cost_max"`
+}
+
+// URLValues transforms a SettingsUpdate into url.Values, skipping nil fields.
+func (s *SettingsUpdate) URLValues() url.Values {
+	return structURLValues(s)
+}
+
+// GetSettingsResult models responses containing the account settings.
+type GetSettingsResult struct {
+	duoapi.StatResult
+	Response Settings
+}
+
+// GetSettings calls GET /admin/v1/settings
+// See https://duo.com/docs/adminapi#retrieve-settings
+func (c *Client) GetSettings() (*GetSettingsResult, error) {
+	_, body, err := c.SignedCall(http.MethodGet, "/admin/v1/settings", nil, duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GetSettingsResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ModifySettings calls POST /admin/v1/settings
+// See https://duo.com/docs/adminapi#modify-settings
+func (c *Client) ModifySettings(update SettingsUpdate) (*GetSettingsResult, error) {
+	_, body, err := c.SignedCall(http.MethodPost, "/admin/v1/settings", update.URLValues(), duoapi.UseTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GetSettingsResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,171 @@
+package admin
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+const getSettingsResponse = `{
+	"stat": "OK",
+	"response": {
+		"caller_id": "+15035551000",
+		"email_activity_notification_enabled": false,
+		"fraud_email": "security@example.com",
+		"fraud_email_enabled": true,
+		"helpdesk_bypass": "limit",
+		"helpdesk_bypass_expiration": 60,
+		"helpdesk_can_send_enroll_email": true,
+		"inactive_user_expiration": 30,
+		"keypress_confirm": "#",
+		"keypress_fraud": "*",
+		"language": "EN",
+		"lockout_expire_duration": null,
+		"lockout_threshold": 10,
+		"log_retention_days": null,
+		"minimum_password_length": 12,
+		"name": "Acme Corp",
+		"password_requires_lower_alpha": true,
+		"password_requires_numeric": true,
+		"password_requires_special": false,
+		"password_requires_upper_alpha": true,
+		"sms_batch": 1,
+		"sms_expiration": 0,
+		"sms_message": "",
+		"sms_refresh": 0,
+		"telephony_warning_min": 100,
+		"timezone": "US/Eastern",
+		"user_telephony_cost_max": 20.5
+	}
+}`
+
+func TestGetSettings(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, getSettingsResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	result, err := duo.GetSettings()
+	if err != nil {
+		t.Errorf("Unexpected error from GetSettings call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	settings := result.Response
+	if settings.Name != "Acme Corp" || settings.HelpdeskBypass != "limit" || settings.LockoutThreshold != 10 {
+		t.Errorf("Unexpected settings %+v", settings)
+	}
+	if !settings.FraudEmailEnabled || settings.SMSRefresh || settings.PasswordRequiresSpecial {
+		t.Errorf("Unexpected boolean settings %+v", settings)
+	}
+	if settings.LockoutExpireDuration != nil || settings.InactiveUserExpiration == nil || *settings.InactiveUserExpiration != 30 {
+		t.Errorf("Unexpected nullable settings %+v", settings)
+	}
+	if settings.UserTelephonyCostMax != 20.5 {
+		t.Errorf("Expected a telephony cost max of 20.5, but got %v", settings.UserTelephonyCostMax)
+	}
+	if last_request.Method != http.MethodGet || last_request.URL.Path != "/admin/v1/settings" {
+		t.Errorf("Unexpected request %s %s", last_request.Method, last_request.URL.Path)
+	}
+}
+
+func TestSettingsUpdate_URLValues(t *testing.T) {
+	threshold := 0
+	enabled := Flag(false)
+	refresh := Flag(true)
+	numeric := Flag(true)
+	cost := 7.5
+	update := SettingsUpdate{
+		LockoutThreshold:        &threshold,
+		FraudEmailEnabled:       &enabled,
+		SMSRefresh:              &refresh,
+		UserTelephonyCostMax:    &cost,
+		PasswordRequiresNumeric: &numeric,
+	}
+	want := url.Values{
+		"lockout_threshold":         []string{"0"},
+		"fraud_email_enabled":       []string{"0"},
+		"sms_refresh":               []string{"1"},
+		"password_requires_numeric": []string{"1"},
+		"user_telephony_cost_max":   []string{"7.5"},
+	}
+	if got := update.URLValues(); !reflect.DeepEqual(got, want) {
+		t.Errorf("SettingsUpdate.URLValues() = %v, want %v", got, want)
+	}
+
+	if got := (&SettingsUpdate{}).URLValues(); len(got) != 0 {
+		t.Errorf("Expected no values for an empty update, but got %v", got)
+	}
+}
+
+// Test that every boolean setting is encoded the same way, as "1" or "0".
+func TestSettingsUpdate_URLValuesBooleans(t *testing.T) {
+	enabled := Flag(true)
+	update := SettingsUpdate{}
+	booleans := 0
+	v := reflect.ValueOf(&update).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.Type.Elem().Kind() != reflect.Bool {
+			continue
+		}
+		if field.Type != reflect.TypeOf(&enabled) {
+			t.Errorf("Expected %s to be a *Flag, but got %s", field.Name, field.Type)
+			continue
+		}
+		v.Field(i).Set(reflect.ValueOf(&enabled))
+		booleans++
+	}
+
+	params := update.URLValues()
+	if booleans == 0 || len(params) != booleans {
+		t.Errorf("Expected %d boolean settings, but got %v", booleans, params)
+	}
+	for key := range params {
+		if params.Get(key) != "1" {
+			t.Errorf("Expected %s to be sent as 1, but got %q", key, params.Get(key))
+		}
+	}
+}
+
+func TestModifySettings(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			fmt.Fprintln(w, getSettingsResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	timezone := "US/Eastern"
+	result, err := duo.ModifySettings(SettingsUpdate{Timezone: &timezone})
+	if err != nil {
+		t.Errorf("Unexpected error from ModifySettings call %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if result.Response.Timezone != timezone {
+		t.Errorf("Expected timezone %s, but got %s", timezone, result.Response.Timezone)
+	}
+	if last_request.Method != http.MethodPost || last_request.URL.Path != "/admin/v1/settings" {
+		t.Errorf("Unexpected request %s %s", last_request.Method, last_request.URL.Path)
+	}
+	if len(last_request.Form) != 1 || last_request.Form.Get("timezone") != timezone {
+		t.Errorf("Expected only timezone in request, but got %v", last_request.Form)
+	}
+}
@@ -0,0 +1,204 @@
+package admin
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// TokenImportOptions controls how ImportTokens creates tokens.
+type TokenImportOptions struct {
+	// Type is the token type used for rows without a type column, such as
+	// TokenTypeHOTP6.
+	Type string
+	// TOTPStep is the time step used for TOTP rows without a totp_step
+	// column. Zero leaves the Duo default of 30 seconds.
+	TOTPStep int
+	// Progress, if set, is called once for every row after it is processed.
+	Progress func(TokenImportResult)
+}
+
+// TokenImportResult reports the outcome of importing one CSV row.
+type TokenImportResult struct {
+	// Row is the 1-based number of the data row, not counting the header.
+	Row    int
+	Serial string
+	// TokenID is set once the token has been created, even if associating
+	// it with a user failed afterwards.
+	TokenID string
+	UserID  string
+	Err     error
+}
+
+// tokenImportColumns lists the CSV columns understood by ImportTokens.
+var tokenImportColumns = map[string]bool{
+	"serial":     true,
+	"type":       true,
+	"secret":     true,
+	"counter":    true,
+	"totp_step":  true,
+	"private_id": true,
+	"aes_key":    true,
+	"username":   true,
+	"user_id":    true,
+}
+
+// ImportTokens creates a hardware token for every row of a vendor seed CSV
+// read from r, and associates it with a user when the row names one.
+//
+// The first row is a header naming the columns; only serial is required.
+// The type, secret (hex), counter, totp_step, private_id and aes_key columns
+// map onto TokenCreate, and a username or user_id column selects the user to
+// associate the token with. Lines starting with '#' are ignored.
+//
+// A failure on one row is recorded in its result and does not stop the
+// import. The returned error is only set when the CSV itself cannot be read,
+// in which case the results of the rows processed so far are returned too.
+func (c *Client) ImportTokens(r io.Reader, options TokenImportOptions) ([]TokenImportResult, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("token import: missing CSV header")
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !tokenImportColumns[name] {
+			return nil, fmt.Errorf("token import: unknown column %q", name)
+		}
+		columns[name] = i
+	}
+	if _, ok := columns["serial"]; !ok {
+		return nil, errors.New("token import: missing serial column")
+	}
+
+	var results []TokenImportResult
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return results, nil
+		}
+		if err != nil {
+			return results, err
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		result := c.importToken(field, options)
+		result.Row = row
+		results = append(results, result)
+		if options.Progress != nil {
+			options.Progress(result)
+		}
+	}
+}
+
+func (c *Client) importToken(field func(string) string, options TokenImportOptions) TokenImportResult {
+	result := TokenImportResult{Serial: field("serial")}
+
+	token, err := tokenFromFields(field, options)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	userID := field("user_id")
+	if userID == "" && field("username") != "" {
+		userID, err = c.lookupUserID(field("username"))
+		if err != nil {
+			result.Err = err
+			return result
+		}
+	}
+
+	created, err := c.CreateToken(token)
+	if err == nil {
+		err = created.Err()
+	}
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.TokenID = created.Response.TokenID
+
+	if userID == "" {
+		return result
+	}
+	associated, err := c.AssociateUserToken(userID, result.TokenID)
+	if err == nil {
+		err = associated.Err()
+	}
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.UserID = userID
+	return result
+}
+
+func tokenFromFields(field func(string) string, options TokenImportOptions) (TokenCreate, error) {
+	token := TokenCreate{
+		Type:      field("type"),
+		Serial:    field("serial"),
+		Secret:    field("secret"),
+		PrivateID: field("private_id"),
+		AESKey:    field("aes_key"),
+	}
+	if token.Serial == "" {
+		return token, errors.New("missing serial")
+	}
+	if token.Type == "" {
+		token.Type = options.Type
+	}
+	if token.Type == "" {
+		return token, errors.New("missing token type")
+	}
+
+	if counter := field("counter"); counter != "" {
+		n, err := strconv.ParseUint(counter, 10, 64)
+		if err != nil {
+			return token, fmt.Errorf("invalid counter %q", counter)
+		}
+		token.Counter = &n
+	}
+
+	step := options.TOTPStep
+	if value := field("totp_step"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return token, fmt.Errorf("invalid totp_step %q", value)
+		}
+		step = n
+	}
+	if step > 0 && (token.Type == TokenTypeTOTP6 || token.Type == TokenTypeTOTP8) {
+		token.TOTPStep = &step
+	}
+	return token, nil
+}
+
+func (c *Client) lookupUserID(username string) (string, error) {
+	users, err := c.GetUsers(GetUsersUsername(username))
+	if err != nil {
+		return "", err
+	}
+	if err := users.Err(); err != nil {
+		return "", err
+	}
+	if len(users.Response) == 0 {
+		return "", fmt.Errorf("no user named %q", username)
+	}
+	return users.Response[0].UserID, nil
+}
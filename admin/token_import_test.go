@@ -0,0 +1,164 @@
+package admin
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newTokenImportServer fakes the token, user and association endpoints used
+// by ImportTokens. Creating a token with serial "dup" fails, and only the
+// user "jsmith" exists.
+func newTokenImportServer(t *testing.T, created *[]string, associated *[]string) *httptest.Server {
+	return httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			switch {
+			case r.Method == http.MethodPost && r.URL.Path == "/admin/v1/tokens":
+				serial := r.Form.Get("serial")
+				if serial == "dup" {
+					fmt.Fprintln(w, `{"stat": "FAIL", "code": 40003, "message": "Duplicate resource"}`)
+					return
+				}
+				*created = append(*created, r.Form.Encode())
+				fmt.Fprintf(w, `{"stat": "OK", "response": {"token_id": "DH%s", "serial": %q, "type": %q}}`, serial, serial, r.Form.Get("type"))
+			case r.Method == http.MethodGet && r.URL.Path == "/admin/v1/users":
+				if r.Form.Get("username") != "jsmith" {
+					fmt.Fprintln(w, `{"stat": "OK", "metadata": {}, "response": []}`)
+					return
+				}
+				fmt.Fprintln(w, `{"stat": "OK", "metadata": {}, "response": [{"user_id": "DUJSMITH", "username": "jsmith"}]}`)
+			case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/tokens"):
+				*associated = append(*associated, r.URL.Path+" "+r.Form.Get("token_id"))
+				fmt.Fprintln(w, `{"stat": "OK", "response": ""}`)
+			default:
+				t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+				http.NotFound(w, r)
+			}
+		}),
+	)
+}
+
+func TestImportTokens(t *testing.T) {
+	var created, associated []string
+	ts := newTokenImportServer(t, &created, &associated)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	const seeds = `Serial, Secret, Counter, Username
+# exported from the vendor portal
+1001,3132333435363738393031323334353637383930,5,jsmith
+1002,3132333435363738393031323334353637383930,,
+dup,3132333435363738393031323334353637383930,,
+1003,3132333435363738393031323334353637383930,x,
+1004,3132333435363738393031323334353637383930,,nobody
+`
+	var progress []int
+	results, err := duo.ImportTokens(strings.NewReader(seeds), TokenImportOptions{
+		Type:     TokenTypeHOTP6,
+		Progress: func(r TokenImportResult) { progress = append(progress, r.Row) },
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error from ImportTokens call %v", err)
+	}
+	if len(results) != 5 {
+		t.Fatalf("Expected 5 results, but got %d", len(results))
+	}
+	if len(progress) != 5 || progress[0] != 1 || progress[4] != 5 {
+		t.Errorf("Unexpected progress rows %v", progress)
+	}
+
+	if results[0].Err != nil || results[0].TokenID != "DH1001" || results[0].UserID != "DUJSMITH" {
+		t.Errorf("Unexpected result for row 1: %+v", results[0])
+	}
+	if results[1].Err != nil || results[1].TokenID != "DH1002" || results[1].UserID != "" {
+		t.Errorf("Unexpected result for row 2: %+v", results[1])
+	}
+	if results[2].Err == nil || !strings.Contains(results[2].Err.Error(), "Duplicate resource") {
+		t.Errorf("Expected a duplicate error for row 3, but got %v", results[2].Err)
+	}
+	if results[3].Err == nil || !strings.Contains(results[3].Err.Error(), "invalid counter") {
+		t.Errorf("Expected an invalid counter error for row 4, but got %v", results[3].Err)
+	}
+	if results[4].Err == nil || results[4].TokenID != "" {
+		t.Errorf("Expected an unknown user error for row 5, but got %+v", results[4])
+	}
+
+	if len(created) != 2 {
+		t.Fatalf("Expected 2 tokens to be created, but got %v", created)
+	}
+	if created[0] != "counter=5&secret=3132333435363738393031323334353637383930&serial=1001&type=h6" {
+		t.Errorf("Unexpected create parameters %s", created[0])
+	}
+	if len(associated) != 1 || associated[0] != "/admin/v1/users/DUJSMITH/tokens DH1001" {
+		t.Errorf("Unexpected associations %v", associated)
+	}
+}
+
+func TestImportTokensTOTPStep(t *testing.T) {
+	var created, associated []string
+	ts := newTokenImportServer(t, &created, &associated)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	const seeds = "serial,type,secret,totp_step\n" +
+		"2001,t6,3132333435363738393031323334353637383930,\n" +
+		"2002,t8,3132333435363738393031323334353637383930,30\n" +
+		"2003,h6,3132333435363738393031323334353637383930,\n"
+	results, err := duo.ImportTokens(strings.NewReader(seeds), TokenImportOptions{TOTPStep: 60})
+	if err != nil {
+		t.Fatalf("Unexpected error from ImportTokens call %v", err)
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			t.Errorf("Unexpected error for row %d: %v", result.Row, result.Err)
+		}
+	}
+	if len(created) != 3 {
+		t.Fatalf("Expected 3 tokens to be created, but got %v", created)
+	}
+	if !strings.Contains(created[0], "totp_step=60") {
+		t.Errorf("Expected the default totp_step, but got %s", created[0])
+	}
+	if !strings.Contains(created[1], "totp_step=30") {
+		t.Errorf("Expected the row's totp_step, but got %s", created[1])
+	}
+	if strings.Contains(created[2], "totp_step") {
+		t.Errorf("Expected no totp_step for an HOTP token, but got %s", created[2])
+	}
+}
+
+func TestImportTokensBadHeader(t *testing.T) {
+	duo := buildAdminClient("https://127.0.0.1:1", nil)
+
+	tests := map[string]string{
+		"empty":          "",
+		"unknown column": "serial,colour\n1,red\n",
+		"missing serial": "secret\nabcd\n",
+	}
+	for name, input := range tests {
+		results, err := duo.ImportTokens(strings.NewReader(input), TokenImportOptions{Type: TokenTypeHOTP6})
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+		if len(results) != 0 {
+			t.Errorf("%s: expected no results, but got %v", name, results)
+		}
+	}
+}
+
+func TestImportTokensMissingType(t *testing.T) {
+	duo := buildAdminClient("https://127.0.0.1:1", nil)
+
+	results, err := duo.ImportTokens(strings.NewReader("serial\n1\n"), TokenImportOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error from ImportTokens call %v", err)
+	}
+	if len(results) != 1 || results[0].Err == nil || results[0].Serial != "1" {
+		t.Errorf("Expected a missing type error, but got %+v", results)
+	}
+}
@@ -0,0 +1,133 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	duoapi "github.com/duosecurity/duo_api_golang"
+)
+
+// Trust Monitor event types, for use with TrustMonitorEventType.
+const (
+	TrustMonitorEventTypeAuth                = "auth"
+	TrustMonitorEventTypeBypassStatusEnabled = "bypass_status_enabled"
+	TrustMonitorEventTypeDeviceRegistration  = "device_registration"
+)
+
+// TrustMonitorEventType sets the optional type parameter for a
+// GetTrustMonitorEvents request, so only events of that type are returned.
+func TrustMonitorEventType(typ string) func(*url.Values) {
+	return func(opts *url.Values) {
+		opts.Set("type", typ)
+	}
+}
+
+// TrustMonitorMetadata holds pagination metadata for the Trust Monitor events endpoint.
+type TrustMonitorMetadata struct {
+	NextOffset string `json:"next_offset"`
+}
+
+// GetNextOffset uses response metadata to return an option that will configure a request to fetch the next page of events. It returns nil when no more events can be fetched.
+func (metadata TrustMonitorMetadata) GetNextOffset() func(params *url.Values) {
+	if metadata.NextOffset == "" {
+		return nil
+	}
+	return func(params *url.Values) {
+		params.Set("offset", metadata.NextOffset)
+	}
+}
+
+// TrustMonitorExplanation describes why an event was surfaced.
+type TrustMonitorExplanation struct {
+	Summary string `json:"summary"`
+	Type    string `json:"type"`
+}
+
+// TrustMonitorPriorityReason describes why an event was prioritized.
+type TrustMonitorPriorityReason struct {
+	Label string `json:"label"`
+	Type  string `json:"type"`
+}
+
+// TrustMonitorEvent models an event surfaced by Duo Trust Monitor.
+type TrustMonitorEvent struct {
+	Explanations       []TrustMonitorExplanation    `json:"explanations"`
+	FromCommonNetblock bool                         `json:"from_common_netblock"`
+	FromNewUser        bool                         `json:"from_new_user"`
+	LowRiskIP          bool                         `json:"low_risk_ip"`
+	PriorityEvent      bool                         `json:"priority_event"`
+	PriorityReasons    []TrustMonitorPriorityReason `json:"priority_reasons"`
+	SEKey              string                       `json:"sekey"`
+	// State is "new" or "processed".
+	State                 string `json:"state"`
+	StateUpdatedTimestamp *int64 `json:"state_updated_timestamp"`
+	// SurfacedAuth is the authentication that was surfaced, for events of
+	// type TrustMonitorEventTypeAuth.
+	SurfacedAuth *AuthLog `json:"surfaced_auth"`
+	// SurfacedTimestamp is in milliseconds since the Unix epoch.
+	SurfacedTimestamp      int64  `json:"surfaced_timestamp"`
+	TriagedAsInteresting   bool   `json:"triaged_as_interesting"`
+	TriageEventURI         string `json:"triage_event_uri"`
+	TriageUpdatedTimestamp *int64 `json:"triage_updated_timestamp"`
+	Type                   string `json:"type"`
+}
+
+// Surfaced returns the time the event was surfaced.
+func (event TrustMonitorEvent) Surfaced() time.Time {
+	return time.Unix(0, event.SurfacedTimestamp*int64(time.Millisecond))
+}
+
+// TrustMonitorEventList holds retrieved events and the metadata used for pagination.
+type TrustMonitorEventList struct {
+	Metadata TrustMonitorMetadata `json:"metadata"`
+	Events   []TrustMonitorEvent  `json:"events"`
+}
+
+// TrustMonitorEventsResult is the structured JSON result of GetTrustMonitorEvents.
+type TrustMonitorEventsResult struct {
+	duoapi.StatResult
+	Response TrustMonitorEventList `json:"response"`
+}
+
+// GetTrustMonitorEvents retrieves a page of Trust Monitor events surfaced between mintime and maxtime. It relies on the option provided by TrustMonitorEventsResult.Response.Metadata.GetNextOffset() for pagination.
+// Calls GET /admin/v1/trust_monitor/events
+// See https://duo.com/docs/adminapi#retrieve-events
+func (c *Client) GetTrustMonitorEvents(mintime, maxtime time.Time, options ...func(*url.Values)) (*TrustMonitorEventsResult, error) {
+	// Format mintime & maxtime parameters
+	minMs := mintime.UnixNano() / int64(time.Millisecond)
+	maxMs := maxtime.UnixNano() / int64(time.Millisecond)
+
+	// Request defaults
+	params := url.Values{
+		"mintime": []string{strconv.FormatInt(minMs, 10)},
+		"maxtime": []string{strconv.FormatInt(maxMs, 10)},
+	}
+
+	// Configure request with additional options
+	for _, opt := range options {
+		opt(&params)
+	}
+
+	resp, body, err := c.SignedCall(
+		http.MethodGet,
+		"/admin/v1/trust_monitor/events",
+		params,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("invalid HTTP response code from Duo API: [%d] %s", resp.StatusCode, resp.Status)
+	}
+
+	result := &TrustMonitorEventsResult{}
+	if err = json.Unmarshal(body, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,162 @@
+package admin
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// TestTrustMonitorMetadata ensures that the next offset is properly configured based on known metadata.
+func TestTrustMonitorMetadata(t *testing.T) {
+	page := TrustMonitorMetadata{NextOffset: "31229"}
+	nextOffset := page.GetNextOffset()
+	if nextOffset == nil {
+		t.Fatalf("Expected option to configure next offset, got nil")
+	}
+	params := url.Values{}
+	nextOffset(&params)
+	if offset := params.Get("offset"); offset != "31229" {
+		t.Fatalf("Expected option to configure offset to be '31229', got %q", offset)
+	}
+
+	lastPage := TrustMonitorMetadata{}
+	if lastPage.GetNextOffset() != nil {
+		t.Errorf("Expected nil option to represent no more available events, got a non-nil option")
+	}
+}
+
+// getTrustMonitorEventsResponse is an example response from the Duo API documentation example: https://duo.com/docs/adminapi#retrieve-events
+const getTrustMonitorEventsResponse = `{
+	"stat": "OK",
+	"response": {
+		"events": [
+			{
+				"explanations": [
+					{
+						"summary": "amanda_tucker has not logged in from this location recently.",
+						"type": "NEW_COUNTRY_CODE"
+					}
+				],
+				"from_common_netblock": true,
+				"from_new_user": false,
+				"low_risk_ip": false,
+				"priority_event": true,
+				"priority_reasons": [
+					{
+						"label": "CN",
+						"type": "country"
+					}
+				],
+				"sekey": "SEDOR9BP00L23C6YUH5",
+				"state": "new",
+				"state_updated_timestamp": null,
+				"surfaced_auth": {
+					"application": {
+						"key": "DIUD2X62LHMPDP00LXS3",
+						"name": "Microsoft Azure Active Directory"
+					},
+					"factor": "not_available",
+					"reason": "location_restricted",
+					"result": "denied",
+					"timestamp": 1675893605,
+					"txid": "2f5d3a9c-91e5-4f3c-9e1d-b3e4b9a2d9f1",
+					"user": {
+						"key": "DUN73JE5M92DP00L4ZYS",
+						"name": "amanda_tucker"
+					}
+				},
+				"surfaced_timestamp": 1675893605269,
+				"triaged_as_interesting": false,
+				"type": "auth"
+			}
+		],
+		"metadata": {
+			"next_offset": "31229"
+		}
+	}
+}`
+
+func TestGetTrustMonitorEvents(t *testing.T) {
+	var last_request *http.Request
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, getTrustMonitorEventsResponse)
+			last_request = r
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	mintime := time.Unix(1675800000, 0)
+	maxtime := time.Unix(1675900000, 0)
+	lastMetadata := TrustMonitorMetadata{NextOffset: "31000"}
+	result, err := duo.GetTrustMonitorEvents(
+		mintime,
+		maxtime,
+		TrustMonitorEventType(TrustMonitorEventTypeAuth),
+		lastMetadata.GetNextOffset(),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error from GetTrustMonitorEvents call: %v", err.Error())
+	}
+	if result.Stat != "OK" {
+		t.Errorf("Expected OK, but got %s", result.Stat)
+	}
+	if length := len(result.Response.Events); length != 1 {
+		t.Fatalf("Expected 1 event, but got %d", length)
+	}
+	event := result.Response.Events[0]
+	if event.Type != TrustMonitorEventTypeAuth || event.State != "new" || !event.PriorityEvent {
+		t.Errorf("Unexpected event %+v", event)
+	}
+	if len(event.PriorityReasons) != 1 || event.PriorityReasons[0].Label != "CN" {
+		t.Errorf("Unexpected priority reasons %+v", event.PriorityReasons)
+	}
+	if len(event.Explanations) != 1 || event.Explanations[0].Type != "NEW_COUNTRY_CODE" {
+		t.Errorf("Unexpected explanations %+v", event.Explanations)
+	}
+	if event.StateUpdatedTimestamp != nil {
+		t.Errorf("Expected no state update timestamp, but got %v", *event.StateUpdatedTimestamp)
+	}
+	if event.SurfacedAuth == nil || event.SurfacedAuth.TxID != "2f5d3a9c-91e5-4f3c-9e1d-b3e4b9a2d9f1" {
+		t.Errorf("Expected surfaced auth txid '2f5d3a9c-91e5-4f3c-9e1d-b3e4b9a2d9f1', but got %+v", event.SurfacedAuth)
+	}
+	if want := time.Unix(1675893605, 269000000); !event.Surfaced().Equal(want) {
+		t.Errorf("Expected surfaced time %v, but got %v", want, event.Surfaced())
+	}
+	if next := result.Response.Metadata.GetNextOffset(); next == nil {
+		t.Errorf("Expected metadata.GetNextOffset option to configure pagination for next request, got nil")
+	}
+
+	if last_request.URL.Path != "/admin/v1/trust_monitor/events" {
+		t.Errorf("Unexpected request path %s", last_request.URL.Path)
+	}
+	query := last_request.URL.Query()
+	if query.Get("mintime") != "1675800000000" || query.Get("maxtime") != "1675900000000" {
+		t.Errorf("Expected millisecond time range in request, but got %v", query)
+	}
+	if query.Get("type") != "auth" || query.Get("offset") != "31000" {
+		t.Errorf("Expected type and offset in request, but got %v", query)
+	}
+}
+
+func TestGetTrustMonitorEventsHTTPError(t *testing.T) {
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintln(w, `{"stat": "FAIL", "code": 40002, "message": "Invalid request parameters"}`)
+		}),
+	)
+	defer ts.Close()
+
+	duo := buildAdminClient(ts.URL, nil)
+
+	_, err := duo.GetTrustMonitorEvents(time.Now().Add(-time.Hour), time.Now())
+	if err == nil {
+		t.Errorf("Expected an error for a non-200 response, but got nil")
+	}
+}
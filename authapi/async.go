@@ -0,0 +1,158 @@
+package authapi
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// AsyncAuth tracks an authentication started with StartAuth.
+type AsyncAuth struct {
+	// Txid is the transaction ID returned by the Auth call.
+	Txid string
+
+	updates chan *AuthStatusResult
+	cancel  context.CancelFunc
+
+	mu  sync.Mutex
+	err error
+}
+
+// Updates returns a channel that receives an AuthStatusResult each time the
+// status of the authentication changes, for example from "pushed" to
+// "answered". The channel is closed after the final result ("allow" or
+// "deny") has been sent, or when polling stops because of an error,
+// cancellation or the context's deadline. Use Err to tell these apart.
+func (a *AsyncAuth) Updates() <-chan *AuthStatusResult {
+	return a.updates
+}
+
+// Err returns the reason polling stopped early, or nil if the authentication
+// reached a final result. It should be called after the Updates channel is closed.
+func (a *AsyncAuth) Err() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.err
+}
+
+// Cancel stops polling. The Updates channel is closed and Err returns
+// context.Canceled. Cancelling does not cancel the authentication at Duo.
+func (a *AsyncAuth) Cancel() {
+	a.cancel()
+}
+
+// Wait consumes the remaining updates and returns the final result.
+// If polling stopped before a final result was received, the last result
+// seen (possibly nil) is returned along with the error.
+func (a *AsyncAuth) Wait() (*AuthStatusResult, error) {
+	var last *AuthStatusResult
+	for result := range a.updates {
+		last = result
+	}
+	return last, a.Err()
+}
+
+func (a *AsyncAuth) fail(err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.err = err
+}
+
+// Default time to wait between AuthStatus calls made by StartAuth.
+const defaultAuthPollInterval = time.Second
+
+// StartAuth begins an asynchronous authentication and polls AuthStatus in the
+// background until it completes.
+// ctx bounds the whole authentication. Use context.WithTimeout to set an
+// overall deadline, or AsyncAuth.Cancel to stop polling early.
+// interval is the time to wait between AuthStatus calls. Zero uses one second.
+// factor and options are the same as for the Auth method; AuthAsync is added
+// automatically.
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+//	defer cancel()
+//	auth, err := api.StartAuth(ctx, 0, "push", AuthUsername("jsmith"), AuthDevice("auto"))
+//	if err != nil {
+//		return err
+//	}
+//	for status := range auth.Updates() {
+//		fmt.Println(status.Response.Status_Msg)
+//	}
+//	if err := auth.Err(); err != nil {
+//		return err
+//	}
+func (api *AuthApi) StartAuth(ctx context.Context,
+	interval time.Duration,
+	factor string,
+	options ...func(*url.Values)) (*AsyncAuth, error) {
+	if interval <= 0 {
+		interval = defaultAuthPollInterval
+	}
+
+	result, err := api.Auth(factor, append(options, AuthAsync())...)
+	if err != nil {
+		return nil, err
+	}
+	if err = result.Err(); err != nil {
+		return nil, err
+	}
+	if result.Response.Txid == "" {
+		return nil, errors.New("duo auth response did not include a txid")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	auth := &AsyncAuth{
+		Txid:    result.Response.Txid,
+		updates: make(chan *AuthStatusResult),
+		cancel:  cancel,
+	}
+	go api.pollAuthStatus(ctx, interval, auth)
+	return auth, nil
+}
+
+// pollAuthStatus sends each status transition of auth until it completes.
+func (api *AuthApi) pollAuthStatus(ctx context.Context, interval time.Duration, auth *AsyncAuth) {
+	defer close(auth.updates)
+	defer auth.cancel()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	lastStatus := ""
+	for {
+		select {
+		case <-ctx.Done():
+			auth.fail(ctx.Err())
+			return
+		case <-timer.C:
+		}
+
+		result, err := api.AuthStatus(auth.Txid)
+		if err == nil {
+			err = result.Err()
+		}
+		if err != nil {
+			auth.fail(err)
+			return
+		}
+
+		final := result.Response.Result != "waiting"
+		if final || result.Response.Status != lastStatus {
+			lastStatus = result.Response.Status
+			select {
+			case auth.updates <- result:
+			case <-ctx.Done():
+				auth.fail(ctx.Err())
+				return
+			}
+		}
+		if final {
+			return
+		}
+		timer.Reset(interval)
+	}
+}
@@ -0,0 +1,138 @@
+package authapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// asyncServer answers /auth/v2/auth with a txid and /auth/v2/auth_status
+// with each of statuses in turn, repeating the last one.
+func asyncServer(t *testing.T, statuses ...string) *httptest.Server {
+	var mu sync.Mutex
+	return httptest.NewTLSServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				switch r.URL.Path {
+				case "/auth/v2/auth":
+					req_params, err := getBodyParams(r)
+					if err != nil {
+						t.Error("Failed to retrieve body parameters")
+					}
+					if req_params.Get("async") != "1" {
+						t.Error("StartAuth did not set the 'async' parameter")
+					}
+					fmt.Fprintln(w, `{"stat": "OK", "response": {"txid": "45f7c92b-f45f-4862-8545-e0f58e78075a"}}`)
+				case "/auth/v2/auth_status":
+					if r.FormValue("txid") != "45f7c92b-f45f-4862-8545-e0f58e78075a" {
+						t.Error("Unexpected txid: " + r.FormValue("txid"))
+					}
+					fmt.Fprintln(w, statuses[0])
+					if len(statuses) > 1 {
+						statuses = statuses[1:]
+					}
+				}
+			}))
+}
+
+const (
+	statusPushed   = `{"stat": "OK", "response": {"result": "waiting", "status": "pushed", "status_msg": "Pushed a login request to your phone..."}}`
+	statusAnswered = `{"stat": "OK", "response": {"result": "waiting", "status": "answered", "status_msg": "Answered"}}`
+	statusAllow    = `{"stat": "OK", "response": {"result": "allow", "status": "allow", "status_msg": "Success. Logging you in..."}}`
+)
+
+// Test that each status transition is sent once, ending with the final result.
+func TestStartAuth(t *testing.T) {
+	ts := asyncServer(t, statusPushed, statusPushed, statusAnswered, statusAllow)
+	defer ts.Close()
+
+	duo := buildAuthApi(ts.URL, nil)
+
+	auth, err := duo.StartAuth(context.Background(), time.Millisecond, "push", AuthUsername("jsmith"))
+	if err != nil {
+		t.Fatal("Failed TestStartAuth: " + err.Error())
+	}
+	if auth.Txid != "45f7c92b-f45f-4862-8545-e0f58e78075a" {
+		t.Error("Unexpected txid: " + auth.Txid)
+	}
+
+	var statuses []string
+	for result := range auth.Updates() {
+		statuses = append(statuses, result.Response.Status)
+	}
+	if fmt.Sprint(statuses) != "[pushed answered allow]" {
+		t.Errorf("Unexpected status transitions: %v", statuses)
+	}
+	if err := auth.Err(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+// Test that polling stops at the context's deadline.
+func TestStartAuthDeadline(t *testing.T) {
+	ts := asyncServer(t, statusPushed)
+	defer ts.Close()
+
+	duo := buildAuthApi(ts.URL, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	auth, err := duo.StartAuth(ctx, time.Millisecond, "push", AuthUsername("jsmith"))
+	if err != nil {
+		t.Fatal("Failed TestStartAuthDeadline: " + err.Error())
+	}
+	last, err := auth.Wait()
+	if err != context.DeadlineExceeded {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if last == nil || last.Response.Status != "pushed" {
+		t.Errorf("Expected last status to be pushed, got %v", last)
+	}
+}
+
+// Test that Cancel stops polling.
+func TestStartAuthCancel(t *testing.T) {
+	ts := asyncServer(t, statusPushed)
+	defer ts.Close()
+
+	duo := buildAuthApi(ts.URL, nil)
+
+	auth, err := duo.StartAuth(context.Background(), time.Hour, "push", AuthUsername("jsmith"))
+	if err != nil {
+		t.Fatal("Failed TestStartAuthCancel: " + err.Error())
+	}
+	if result := <-auth.Updates(); result == nil || result.Response.Status != "pushed" {
+		t.Errorf("Expected pushed status, got %v", result)
+	}
+	auth.Cancel()
+	if _, err := auth.Wait(); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+// Test that a failed Auth call is reported by StartAuth.
+func TestStartAuthFail(t *testing.T) {
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(400)
+				fmt.Fprintln(w, `{"stat": "FAIL", "code": 40002, "message": "Invalid request parameters", "message_detail": "username"}`)
+			}))
+	defer ts.Close()
+
+	duo := buildAuthApi(ts.URL, nil)
+
+	auth, err := duo.StartAuth(context.Background(), 0, "push")
+	if err == nil {
+		t.Fatal("Expected an error from StartAuth")
+	}
+	if auth != nil {
+		t.Error("Expected no AsyncAuth on error")
+	}
+}
@@ -0,0 +1,319 @@
+package authapi
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+)
+
+// AuthRequest is a typed request for Duo's Auth method, validated before it is
+// signed and sent. Use PushAuthRequest, PasscodeAuthRequest, SMSAuthRequest,
+// PhoneAuthRequest or AutoAuthRequest, and send it with AuthWith.
+type AuthRequest interface {
+	// Factor returns the value of the factor parameter.
+	Factor() string
+	// Validate reports missing or conflicting parameters as an *AuthRequestError.
+	Validate() error
+	// Options returns the request as options for the Auth method.
+	Options() []func(*url.Values)
+}
+
+// AuthRequestError describes why an AuthRequest is invalid.
+type AuthRequestError struct {
+	// Factor is the factor of the invalid request.
+	Factor string
+	// Param is the Duo parameter at fault, such as "passcode".
+	Param string
+	// Reason describes the problem.
+	Reason string
+}
+
+func (e *AuthRequestError) Error() string {
+	return fmt.Sprintf("invalid %s auth request: %s %s", e.Factor, e.Param, e.Reason)
+}
+
+// AuthUser identifies the user to authenticate. Exactly one of UserId and
+// Username must be set.
+type AuthUser struct {
+	UserId   string
+	Username string
+}
+
+func (u AuthUser) validate(factor string) error {
+	if u.UserId == "" && u.Username == "" {
+		return &AuthRequestError{factor, "user_id/username", "is required"}
+	}
+	if u.UserId != "" && u.Username != "" {
+		return &AuthRequestError{factor, "user_id/username", "must not both be set"}
+	}
+	return nil
+}
+
+func (u AuthUser) options() []func(*url.Values) {
+	if u.UserId != "" {
+		return []func(*url.Values){AuthUserId(u.UserId)}
+	}
+	return []func(*url.Values){AuthUsername(u.Username)}
+}
+
+func (u AuthUser) preauthOption() func(*url.Values) {
+	if u.UserId != "" {
+		return PreauthUserId(u.UserId)
+	}
+	return PreauthUsername(u.Username)
+}
+
+// key identifies the user in a TrustedDeviceStore.
+func (u AuthUser) key() string {
+	if u.UserId != "" {
+		return u.UserId
+	}
+	return u.Username
+}
+
+// authCommon holds the parameters shared by every factor.
+type authCommon struct {
+	factor string
+	user   AuthUser
+	ipAddr string
+	async  bool
+}
+
+func (c authCommon) validate() error {
+	if err := c.user.validate(c.factor); err != nil {
+		return err
+	}
+	if c.ipAddr != "" && net.ParseIP(c.ipAddr) == nil {
+		return &AuthRequestError{c.factor, "ipaddr", "is not an IP address"}
+	}
+	return nil
+}
+
+func (c authCommon) options() []func(*url.Values) {
+	options := c.user.options()
+	if c.ipAddr != "" {
+		options = append(options, AuthIpAddr(c.ipAddr))
+	}
+	if c.async {
+		options = append(options, AuthAsync())
+	}
+	return options
+}
+
+func validateDevice(factor, device string) error {
+	if device == "" {
+		return &AuthRequestError{factor, "device", `is required (use "auto" for the user's default device)`}
+	}
+	return nil
+}
+
+// PushAuthRequest sends a Duo Push to the user's device.
+type PushAuthRequest struct {
+	AuthUser
+	// IpAddr is the optional IP address of the user.
+	IpAddr string
+	// Async returns a txid immediately instead of waiting for the user.
+	Async bool
+	// Device is the ID of the device to push to, or "auto". Required.
+	Device string
+	// Type optionally replaces "Login" in the push notification text.
+	Type string
+	// DisplayUsername optionally replaces the username shown in the push.
+	DisplayUsername string
+	// PushInfo is optional key/value pairs shown in the push.
+	PushInfo PushInfo
+}
+
+// Factor implements AuthRequest.
+func (r PushAuthRequest) Factor() string { return "push" }
+
+// Validate implements AuthRequest.
+func (r PushAuthRequest) Validate() error {
+	if err := r.common().validate(); err != nil {
+		return err
+	}
+	if err := validateDevice(r.Factor(), r.Device); err != nil {
+		return err
+	}
+	return validatePushInfo(r.Factor(), r.PushInfo)
+}
+
+// Options implements AuthRequest.
+func (r PushAuthRequest) Options() []func(*url.Values) {
+	options := append(r.common().options(), AuthDevice(r.Device))
+	return append(options, pushOptions(r.Type, r.DisplayUsername, r.PushInfo)...)
+}
+
+func (r PushAuthRequest) common() authCommon {
+	return authCommon{r.Factor(), r.AuthUser, r.IpAddr, r.Async}
+}
+
+func validatePushInfo(factor string, pushinfo PushInfo) error {
+	if err := pushinfo.Validate(); err != nil {
+		return &AuthRequestError{factor, "pushinfo", err.Error()}
+	}
+	return nil
+}
+
+func pushOptions(type_, displayUsername string, pushinfo PushInfo) []func(*url.Values) {
+	var options []func(*url.Values)
+	if type_ != "" {
+		options = append(options, AuthType(type_))
+	}
+	if displayUsername != "" {
+		options = append(options, AuthDisplayUsername(displayUsername))
+	}
+	if len(pushinfo) > 0 {
+		options = append(options, AuthPushInfo(pushinfo))
+	}
+	return options
+}
+
+// PasscodeAuthRequest checks a passcode entered by the user.
+type PasscodeAuthRequest struct {
+	AuthUser
+	// IpAddr is the optional IP address of the user.
+	IpAddr string
+	// Passcode is the passcode entered by the user. Required.
+	Passcode string
+}
+
+// Factor implements AuthRequest.
+func (r PasscodeAuthRequest) Factor() string { return "passcode" }
+
+// Validate implements AuthRequest.
+func (r PasscodeAuthRequest) Validate() error {
+	if err := r.common().validate(); err != nil {
+		return err
+	}
+	if r.Passcode == "" {
+		return &AuthRequestError{r.Factor(), "passcode", "is required"}
+	}
+	return nil
+}
+
+// Options implements AuthRequest.
+func (r PasscodeAuthRequest) Options() []func(*url.Values) {
+	return append(r.common().options(), AuthPasscode(r.Passcode))
+}
+
+func (r PasscodeAuthRequest) common() authCommon {
+	return authCommon{r.Factor(), r.AuthUser, r.IpAddr, false}
+}
+
+// SMSAuthRequest sends a new batch of SMS passcodes to the user's phone.
+// The authentication itself is always denied; prompt the user for one of the
+// passcodes and send a PasscodeAuthRequest.
+type SMSAuthRequest struct {
+	AuthUser
+	// IpAddr is the optional IP address of the user.
+	IpAddr string
+	// Async returns a txid immediately instead of waiting for delivery.
+	Async bool
+	// Device is the ID of the phone to send passcodes to, or "auto". Required.
+	Device string
+}
+
+// Factor implements AuthRequest.
+func (r SMSAuthRequest) Factor() string { return "sms" }
+
+// Validate implements AuthRequest.
+func (r SMSAuthRequest) Validate() error {
+	if err := r.common().validate(); err != nil {
+		return err
+	}
+	return validateDevice(r.Factor(), r.Device)
+}
+
+// Options implements AuthRequest.
+func (r SMSAuthRequest) Options() []func(*url.Values) {
+	return append(r.common().options(), AuthDevice(r.Device))
+}
+
+func (r SMSAuthRequest) common() authCommon {
+	return authCommon{r.Factor(), r.AuthUser, r.IpAddr, r.Async}
+}
+
+// PhoneAuthRequest calls the user's phone.
+type PhoneAuthRequest struct {
+	AuthUser
+	// IpAddr is the optional IP address of the user.
+	IpAddr string
+	// Async returns a txid immediately instead of waiting for the user.
+	Async bool
+	// Device is the ID of the phone to call, or "auto". Required.
+	Device string
+}
+
+// Factor implements AuthRequest.
+func (r PhoneAuthRequest) Factor() string { return "phone" }
+
+// Validate implements AuthRequest.
+func (r PhoneAuthRequest) Validate() error {
+	if err := r.common().validate(); err != nil {
+		return err
+	}
+	return validateDevice(r.Factor(), r.Device)
+}
+
+// Options implements AuthRequest.
+func (r PhoneAuthRequest) Options() []func(*url.Values) {
+	return append(r.common().options(), AuthDevice(r.Device))
+}
+
+func (r PhoneAuthRequest) common() authCommon {
+	return authCommon{r.Factor(), r.AuthUser, r.IpAddr, r.Async}
+}
+
+// AutoAuthRequest lets Duo choose between push and phone for the device.
+// The push parameters are used if Duo sends a push.
+type AutoAuthRequest struct {
+	AuthUser
+	// IpAddr is the optional IP address of the user.
+	IpAddr string
+	// Async returns a txid immediately instead of waiting for the user.
+	Async bool
+	// Device is the ID of the device to use, or "auto". Required.
+	Device string
+	// Type optionally replaces "Login" in the push notification text.
+	Type string
+	// DisplayUsername optionally replaces the username shown in the push.
+	DisplayUsername string
+	// PushInfo is optional key/value pairs shown in the push.
+	PushInfo PushInfo
+}
+
+// Factor implements AuthRequest.
+func (r AutoAuthRequest) Factor() string { return "auto" }
+
+// Validate implements AuthRequest.
+func (r AutoAuthRequest) Validate() error {
+	if err := r.common().validate(); err != nil {
+		return err
+	}
+	if err := validateDevice(r.Factor(), r.Device); err != nil {
+		return err
+	}
+	return validatePushInfo(r.Factor(), r.PushInfo)
+}
+
+// Options implements AuthRequest.
+func (r AutoAuthRequest) Options() []func(*url.Values) {
+	options := append(r.common().options(), AuthDevice(r.Device))
+	return append(options, pushOptions(r.Type, r.DisplayUsername, r.PushInfo)...)
+}
+
+func (r AutoAuthRequest) common() authCommon {
+	return authCommon{r.Factor(), r.AuthUser, r.IpAddr, r.Async}
+}
+
+// AuthWith validates req and, if it is valid, sends it using Duo's Auth method.
+// Invalid requests are never signed or sent; an *AuthRequestError is returned.
+//
+// Example: api.AuthWith(authapi.PushAuthRequest{AuthUser: authapi.AuthUser{Username: "jsmith"}, Device: "auto"})
+func (api *AuthApi) AuthWith(req AuthRequest) (*AuthResult, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	return api.Auth(req.Factor(), req.Options()...)
+}
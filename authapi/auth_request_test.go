@@ -0,0 +1,129 @@
+package authapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Test that invalid typed requests are rejected with precise errors.
+func TestAuthRequestValidate(t *testing.T) {
+	jsmith := AuthUser{Username: "jsmith"}
+	tests := []struct {
+		name  string
+		req   AuthRequest
+		param string
+	}{
+		{"valid push", PushAuthRequest{AuthUser: jsmith, Device: "auto"}, ""},
+		{"valid passcode", PasscodeAuthRequest{AuthUser: AuthUser{UserId: "DU94SWSN4ADHHJHF2HXT"}, Passcode: "123456"}, ""},
+		{"valid sms", SMSAuthRequest{AuthUser: jsmith, Device: "DPFZRS9FB0D46QFTM891"}, ""},
+		{"valid phone", PhoneAuthRequest{AuthUser: jsmith, Device: "auto", IpAddr: "40.40.40.10"}, ""},
+		{"valid auto", AutoAuthRequest{AuthUser: jsmith, Device: "auto", Async: true}, ""},
+		{"no user", PushAuthRequest{Device: "auto"}, "user_id/username"},
+		{"both users", PasscodeAuthRequest{AuthUser: AuthUser{UserId: "DU94SWSN4ADHHJHF2HXT", Username: "jsmith"}, Passcode: "123456"}, "user_id/username"},
+		{"no passcode", PasscodeAuthRequest{AuthUser: jsmith}, "passcode"},
+		{"no push device", PushAuthRequest{AuthUser: jsmith}, "device"},
+		{"no sms device", SMSAuthRequest{AuthUser: jsmith}, "device"},
+		{"no phone device", PhoneAuthRequest{AuthUser: jsmith}, "device"},
+		{"no auto device", AutoAuthRequest{AuthUser: jsmith}, "device"},
+		{"empty pushinfo key", PushAuthRequest{AuthUser: jsmith, Device: "auto", PushInfo: PushInfo{}.Add("", "value")}, "pushinfo"},
+		{"long pushinfo", AutoAuthRequest{AuthUser: jsmith, Device: "auto", PushInfo: PushInfo{}.Add("key", strings.Repeat("x", MaxPushInfoLength))}, "pushinfo"},
+		{"bad ip", PhoneAuthRequest{AuthUser: jsmith, Device: "auto", IpAddr: "localhost"}, "ipaddr"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.param == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			reqErr, ok := err.(*AuthRequestError)
+			if !ok {
+				t.Fatalf("Expected *AuthRequestError, got %#v", err)
+			}
+			if reqErr.Param != tt.param || reqErr.Factor != tt.req.Factor() {
+				t.Errorf("Expected error for %s %s, got %v", tt.req.Factor(), tt.param, reqErr)
+			}
+		})
+	}
+}
+
+// Test that AuthWith sends exactly the parameters of the typed request.
+func TestAuthWith(t *testing.T) {
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				req_params, err := getBodyParams(r)
+				if err != nil {
+					t.Error("Failed to retrieve body parameters")
+				}
+				expected := map[string]string{
+					"username":         "jsmith",
+					"factor":           "push",
+					"ipaddr":           "40.40.40.10",
+					"device":           "DPFZRS9FB0D46QFTM891",
+					"type":             "Transfer",
+					"display_username": "Joe Smith",
+					"pushinfo":         "from=mysite.com&amount=%2410",
+				}
+				for key, value := range expected {
+					if req_params.Get(key) != value {
+						t.Errorf("TestAuthWith failed to set '%s' query parameter: %q", key, req_params.Get(key))
+					}
+				}
+				if len(req_params) != len(expected) {
+					t.Errorf("TestAuthWith sent unexpected parameters: %v", req_params)
+				}
+				fmt.Fprintln(w, `
+                {
+                    "stat": "OK",
+                    "response": {
+                      "result": "allow",
+                      "status": "allow",
+                      "status_msg": "Success. Logging you in..."
+                    }
+                  }`)
+			}))
+	defer ts.Close()
+
+	duo := buildAuthApi(ts.URL, nil)
+
+	res, err := duo.AuthWith(PushAuthRequest{
+		AuthUser:        AuthUser{Username: "jsmith"},
+		IpAddr:          "40.40.40.10",
+		Device:          "DPFZRS9FB0D46QFTM891",
+		Type:            "Transfer",
+		DisplayUsername: "Joe Smith",
+		PushInfo:        PushInfo{}.Add("from", "mysite.com").Add("amount", "$10"),
+	})
+	if err != nil {
+		t.Fatal("Failed TestAuthWith: " + err.Error())
+	}
+	if res.Response.Result != "allow" {
+		t.Error("Unexpected response result: " + res.Response.Result)
+	}
+}
+
+// Test that an invalid request never reaches Duo.
+func TestAuthWithInvalid(t *testing.T) {
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				t.Error("Invalid request was sent to Duo")
+			}))
+	defer ts.Close()
+
+	duo := buildAuthApi(ts.URL, nil)
+
+	res, err := duo.AuthWith(PasscodeAuthRequest{AuthUser: AuthUser{Username: "jsmith"}})
+	if err == nil {
+		t.Error("Expected validation error")
+	}
+	if res != nil {
+		t.Error("Expected no result for an invalid request")
+	}
+}
@@ -1,7 +1,14 @@
 package authapi
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"mime"
+	"net/http"
 	"net/url"
 	"strconv"
 
@@ -77,21 +84,58 @@ func (api *AuthApi) Check() (*CheckResult, error) {
 	return ret, nil
 }
 
+// pngSignature starts every PNG file.
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
 // Return object for the 'Logo' API call.
 type LogoResult struct {
 	duoapi.StatResult
-	png *[]byte
+	png         *[]byte
+	notModified bool
+}
+
+// PNG returns the logo image data, or nil if the call failed.
+func (l *LogoResult) PNG() []byte {
+	if l.png == nil {
+		return nil
+	}
+	return *l.png
+}
+
+// Image decodes the logo.
+func (l *LogoResult) Image() (image.Image, error) {
+	if l.png == nil {
+		return nil, errors.New("logo result contains no image")
+	}
+	return png.Decode(bytes.NewReader(*l.png))
 }
 
 // Duo's Logo method. https://www.duosecurity.com/docs/authapi#/logo
 // If the API call is successful, the configured logo png is returned.  Othwerwise,
 // error information is returned in the LogoResult return value.
+// An error is returned if Duo responds with something other than a PNG image.
 func (api *AuthApi) Logo() (*LogoResult, error) {
-	resp, body, err := api.SignedCall("GET", "/auth/v2/logo", nil, duoapi.UseTimeout)
+	return api.logo(duoapi.UseTimeout)
+}
+
+// logo calls the Logo method with options, such as a conditional request
+// header; a 304 Not Modified response is a successful result without a PNG.
+func (api *AuthApi) logo(options ...duoapi.DuoApiOption) (*LogoResult, error) {
+	resp, body, err := api.SignedCall("GET", "/auth/v2/logo", nil, options...)
 	if err != nil {
 		return nil, err
 	}
+	if resp.StatusCode == http.StatusNotModified {
+		return &LogoResult{StatResult: duoapi.StatResult{Stat: "OK"}, notModified: true}, nil
+	}
 	if resp.StatusCode == 200 {
+		contentType := resp.Header.Get("Content-Type")
+		if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType != "image/png" {
+			return nil, fmt.Errorf("unexpected logo content type %q", contentType)
+		}
+		if !bytes.HasPrefix(body, pngSignature) {
+			return nil, errors.New("logo is not a valid PNG image")
+		}
 		ret := &LogoResult{StatResult: duoapi.StatResult{Stat: "OK"},
 			png: &body}
 		return ret, nil
@@ -189,13 +233,7 @@ type PreauthResult struct {
 		Result            string
 		Status_Msg        string
 		Enroll_Portal_Url string
-		Devices           []struct {
-			Device       string
-			Type         string
-			Name         string
-			Number       string
-			Capabilities []string
-		}
+		Devices           []Device
 	}
 }
 
@@ -328,12 +366,14 @@ type AuthResult struct {
 // When using factor 'push', use AuthType to display some extra auth text to the user.
 // When using factor 'push', use AuthDisplayUsername to display some extra text
 // to the user.
-// When using factor 'push', use AuthPushInfo to include some URL-encoded key/value
-// pairs to display to the user.
+// When using factor 'push', use AuthPushInfo to include some key/value pairs
+// to display to the user, or AuthPushinfo if they are already URL-encoded.
 // When using factor 'passcode', use AuthPasscode to specify the passcode entered
 // by the user.
 // When using factor 'sms' or 'phone', use AuthDevice to specify which device
 // should receive the SMS or phone call.
+// Auth does not check that the options suit the factor; use AuthWith with a
+// typed AuthRequest to have the parameters validated before the call.
 func (api *AuthApi) Auth(factor string, options ...func(*url.Values)) (*AuthResult, error) {
 	params := url.Values{}
 	for _, o := range options {
@@ -197,9 +197,43 @@ func TestLogo(t *testing.T) {
 
 	duo := buildAuthApi(ts.URL, nil)
 
-	_, err := duo.Logo()
+	res, err := duo.Logo()
 	if err != nil {
-		t.Error("Failed TestCheck: " + err.Error())
+		t.Fatal("Failed TestLogo: " + err.Error())
+	}
+	if res.Stat != "OK" {
+		t.Error("Expected OK, but got " + res.Stat)
+	}
+	if len(res.PNG()) != 67 {
+		t.Errorf("Unexpected logo length: %d", len(res.PNG()))
+	}
+	img, err := res.Image()
+	if err != nil {
+		t.Fatal("Failed to decode logo: " + err.Error())
+	}
+	if bounds := img.Bounds(); bounds.Dx() != 1 || bounds.Dy() != 1 {
+		t.Errorf("Unexpected logo size: %v", bounds)
+	}
+}
+
+// Test that a logo response which is not a PNG is rejected.
+func TestLogoContentType(t *testing.T) {
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html")
+				fmt.Fprintln(w, "<html>Captive portal</html>")
+			}))
+	defer ts.Close()
+
+	duo := buildAuthApi(ts.URL, nil)
+
+	res, err := duo.Logo()
+	if err == nil {
+		t.Error("Expected error for non-PNG logo")
+	}
+	if res != nil {
+		t.Error("Expected no result for non-PNG logo")
 	}
 }
 
@@ -229,6 +263,12 @@ func TestLogoError(t *testing.T) {
 	if res.Stat != "FAIL" {
 		t.Error("Expected FAIL, but got " + res.Stat)
 	}
+	if res.PNG() != nil {
+		t.Error("Expected no logo data")
+	}
+	if _, err := res.Image(); err == nil {
+		t.Error("Expected error decoding missing logo")
+	}
 	if res.Code == nil || *res.Code != 40002 {
 		t.Error("Unexpected response code.")
 	}
@@ -0,0 +1,136 @@
+package authapi
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Capability is an authentication method supported by a Device.
+type Capability string
+
+// Capabilities reported by Preauth.
+const (
+	// CapabilityAuto means the device can be used with the 'auto' factor.
+	CapabilityAuto Capability = "auto"
+	// CapabilityPush means the device can receive Duo Push.
+	CapabilityPush Capability = "push"
+	// CapabilitySMS means the device can receive SMS passcodes.
+	CapabilitySMS Capability = "sms"
+	// CapabilityPhone means the device can receive phone calls.
+	CapabilityPhone Capability = "phone"
+	// CapabilityMobileOTP means the device can generate passcodes in Duo Mobile.
+	CapabilityMobileOTP Capability = "mobile_otp"
+)
+
+// Device is one of the user's devices, as returned by Preauth.
+type Device struct {
+	// Device is the device ID, used with AuthDevice.
+	Device string
+	// Type is "phone" or "token".
+	Type         string
+	Name         string
+	Number       string
+	Display_Name string
+	Capabilities []Capability
+	// Sms_Nextcode is the first digit of the next SMS passcode, if passcodes
+	// have already been sent to the device.
+	Sms_Nextcode string
+}
+
+// Supports reports whether the device has the given capability.
+func (d Device) Supports(c Capability) bool {
+	for _, capability := range d.Capabilities {
+		if capability == c {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsPush reports whether the device can receive Duo Push.
+func (d Device) SupportsPush() bool { return d.Supports(CapabilityPush) }
+
+// SupportsSMS reports whether the device can receive SMS passcodes.
+func (d Device) SupportsSMS() bool { return d.Supports(CapabilitySMS) }
+
+// SupportsPhone reports whether the device can receive phone calls.
+func (d Device) SupportsPhone() bool { return d.Supports(CapabilityPhone) }
+
+// SupportsMobileOTP reports whether the device can generate passcodes.
+func (d Device) SupportsMobileOTP() bool { return d.Supports(CapabilityMobileOTP) }
+
+// ProvidesPasscodes reports whether the user can read a passcode from the
+// device: a hardware token, Duo Mobile, or a phone receiving SMS passcodes.
+func (d Device) ProvidesPasscodes() bool {
+	return d.Type == "token" || d.SupportsMobileOTP() || d.SupportsSMS()
+}
+
+// BestFactor returns the Auth factor that is most convenient for the user of
+// this device: "push", then "phone", then "sms", then "passcode". It returns ""
+// if the device supports none of them.
+func (d Device) BestFactor() string {
+	switch {
+	case d.SupportsPush():
+		return "push"
+	case d.SupportsPhone():
+		return "phone"
+	case d.SupportsSMS():
+		return "sms"
+	case d.ProvidesPasscodes():
+		return "passcode"
+	}
+	return ""
+}
+
+// FactorPolicy lists capabilities in order of preference, and is used by
+// PreauthResult.RecommendedAuth to choose how to authenticate the user.
+type FactorPolicy []Capability
+
+var (
+	// PreferPush uses push, then a phone call, then SMS, then a passcode.
+	PreferPush = FactorPolicy{CapabilityPush, CapabilityPhone, CapabilitySMS, CapabilityMobileOTP}
+	// NoTelephony never uses phone calls or SMS, which cost telephony credits.
+	NoTelephony = FactorPolicy{CapabilityPush, CapabilityMobileOTP}
+	// PasscodeOnly asks the user for a passcode, for clients that cannot wait
+	// for a push or phone call.
+	PasscodeOnly = FactorPolicy{CapabilityMobileOTP}
+)
+
+// ErrNoSuitableDevice is returned by RecommendedAuth when none of the user's
+// devices supports a capability allowed by the policy.
+var ErrNoSuitableDevice = errors.New("no device supports a factor allowed by the policy")
+
+// RecommendedAuth returns the Auth request to make for user, following policy.
+// Capabilities are tried in the policy's order, and for each one the devices
+// in the order returned by Duo.
+// CapabilityMobileOTP in the policy allows a passcode from any device that
+// ProvidesPasscodes. A PasscodeAuthRequest is then returned without a
+// Passcode; prompt the user for one before sending it.
+// An error is returned if the preauth result is not "auth", since the user is
+// then allowed, denied or must enroll without a second factor.
+func (r *PreauthResult) RecommendedAuth(user AuthUser, policy FactorPolicy) (AuthRequest, error) {
+	if r.Response.Result != "auth" {
+		return nil, fmt.Errorf("preauth result is %q, not \"auth\"", r.Response.Result)
+	}
+	for _, capability := range policy {
+		for _, device := range r.Response.Devices {
+			if capability == CapabilityMobileOTP && device.ProvidesPasscodes() {
+				return PasscodeAuthRequest{AuthUser: user}, nil
+			}
+			if !device.Supports(capability) {
+				continue
+			}
+			switch capability {
+			case CapabilityPush:
+				return PushAuthRequest{AuthUser: user, Device: device.Device}, nil
+			case CapabilityPhone:
+				return PhoneAuthRequest{AuthUser: user, Device: device.Device}, nil
+			case CapabilitySMS:
+				return SMSAuthRequest{AuthUser: user, Device: device.Device}, nil
+			case CapabilityAuto:
+				return AutoAuthRequest{AuthUser: user, Device: device.Device}, nil
+			}
+		}
+	}
+	return nil, ErrNoSuitableDevice
+}
@@ -0,0 +1,117 @@
+package authapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const preauthDevicesResponse = `{
+  "stat": "OK",
+  "response": {
+    "result": "auth",
+    "status_msg": "Account is active",
+    "devices": [
+      {
+        "device": "DPFZRS9FB0D46QFTM890",
+        "type": "phone",
+        "number": "XXX-XXX-0100",
+        "name": "",
+        "display_name": "Landline (XXX-XXX-0100)",
+        "capabilities": ["phone"]
+      },
+      {
+        "device": "DPFZRS9FB0D46QFTM891",
+        "type": "phone",
+        "number": "XXX-XXX-0101",
+        "name": "",
+        "sms_nextcode": "2",
+        "capabilities": ["auto", "push", "sms", "phone", "mobile_otp"]
+      },
+      {
+        "device": "DHEKH0JJIYC1LX3AZWO4",
+        "type": "token",
+        "name": "0"
+      }
+    ]
+  }
+}`
+
+func TestDeviceCapabilities(t *testing.T) {
+	result := &PreauthResult{}
+	if err := json.Unmarshal([]byte(preauthDevicesResponse), result); err != nil {
+		t.Fatalf("Failed to unmarshal preauth response: %v", err)
+	}
+	landline, mobile, token := result.Response.Devices[0], result.Response.Devices[1], result.Response.Devices[2]
+
+	if landline.Display_Name != "Landline (XXX-XXX-0100)" {
+		t.Errorf("Unexpected display name: %q", landline.Display_Name)
+	}
+	if mobile.Sms_Nextcode != "2" {
+		t.Errorf("Unexpected sms_nextcode: %q", mobile.Sms_Nextcode)
+	}
+	if !mobile.SupportsPush() || !mobile.SupportsSMS() || !mobile.SupportsPhone() || !mobile.SupportsMobileOTP() || !mobile.Supports(CapabilityAuto) {
+		t.Errorf("Expected mobile to support every capability: %v", mobile.Capabilities)
+	}
+	if landline.SupportsPush() || landline.SupportsSMS() || !landline.SupportsPhone() || landline.ProvidesPasscodes() {
+		t.Errorf("Expected landline to support phone calls only: %v", landline.Capabilities)
+	}
+	if !token.ProvidesPasscodes() {
+		t.Error("Expected token to provide passcodes")
+	}
+
+	if factor := landline.BestFactor(); factor != "phone" {
+		t.Errorf("Expected best factor phone for landline, got %q", factor)
+	}
+	if factor := mobile.BestFactor(); factor != "push" {
+		t.Errorf("Expected best factor push for mobile, got %q", factor)
+	}
+	if factor := token.BestFactor(); factor != "passcode" {
+		t.Errorf("Expected best factor passcode for token, got %q", factor)
+	}
+	if factor := (Device{Type: "phone"}).BestFactor(); factor != "" {
+		t.Errorf("Expected no factor for a device without capabilities, got %q", factor)
+	}
+}
+
+func TestRecommendedAuth(t *testing.T) {
+	result := &PreauthResult{}
+	if err := json.Unmarshal([]byte(preauthDevicesResponse), result); err != nil {
+		t.Fatalf("Failed to unmarshal preauth response: %v", err)
+	}
+	user := AuthUser{Username: "jsmith"}
+
+	tests := []struct {
+		name     string
+		policy   FactorPolicy
+		expected AuthRequest
+	}{
+		{"prefer push", PreferPush, PushAuthRequest{AuthUser: user, Device: "DPFZRS9FB0D46QFTM891"}},
+		{"phone first", FactorPolicy{CapabilityPhone, CapabilityPush}, PhoneAuthRequest{AuthUser: user, Device: "DPFZRS9FB0D46QFTM890"}},
+		{"sms", FactorPolicy{CapabilitySMS}, SMSAuthRequest{AuthUser: user, Device: "DPFZRS9FB0D46QFTM891"}},
+		{"auto", FactorPolicy{CapabilityAuto}, AutoAuthRequest{AuthUser: user, Device: "DPFZRS9FB0D46QFTM891"}},
+		{"passcode only", PasscodeOnly, PasscodeAuthRequest{AuthUser: user}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := result.RecommendedAuth(user, tt.policy)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(req, tt.expected) {
+				t.Errorf("Expected %#v, got %#v", tt.expected, req)
+			}
+		})
+	}
+
+	// Only the landline is left, and the policy forbids phone calls.
+	result.Response.Devices = result.Response.Devices[:1]
+	if _, err := result.RecommendedAuth(user, NoTelephony); err != ErrNoSuitableDevice {
+		t.Errorf("Expected ErrNoSuitableDevice, got %v", err)
+	}
+
+	result.Response.Result = "allow"
+	if _, err := result.RecommendedAuth(user, PreferPush); err == nil {
+		t.Error("Expected error when no second factor is required")
+	}
+}
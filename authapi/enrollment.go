@@ -0,0 +1,195 @@
+package authapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"net/url"
+	"time"
+
+	"github.com/duosecurity/duo_api_golang/internal/qrcode"
+)
+
+// barcodeQuietZone is the width, in modules, of the light border required
+// around a QR code.
+const barcodeQuietZone = 4
+
+// ExpiresAt returns the time after which the activation code can no longer
+// be used, or the zero time if the response did not include one.
+func (r *EnrollResult) ExpiresAt() time.Time {
+	if r.Response.Expiration == 0 {
+		return time.Time{}
+	}
+	return time.Unix(r.Response.Expiration, 0)
+}
+
+// barcode encodes the same data as the Activation_Barcode image: the value
+// parameter of its URL, which is the activation code without the duo:// scheme.
+func (r *EnrollResult) barcode(scale int) (*qrcode.Code, error) {
+	if scale < 1 {
+		return nil, fmt.Errorf("invalid barcode scale %d", scale)
+	}
+	barcodeURL, err := url.Parse(r.Response.Activation_Barcode)
+	if err != nil {
+		return nil, fmt.Errorf("invalid activation barcode URL: %v", err)
+	}
+	value := barcodeURL.Query().Get("value")
+	if value == "" {
+		return nil, errors.New("enroll response has no activation barcode")
+	}
+	return qrcode.Encode([]byte(value))
+}
+
+// BarcodePNG renders the activation barcode as a QR code in PNG format, for
+// the user to scan with Duo Mobile. Unlike the Activation_Barcode image, it is
+// generated locally and needs no request to Duo.
+// scale is the width of each module in pixels; 4 suits most screens.
+func (r *EnrollResult) BarcodePNG(scale int) ([]byte, error) {
+	code, err := r.barcode(scale)
+	if err != nil {
+		return nil, err
+	}
+	size := (code.Size + 2*barcodeQuietZone) * scale
+	img := image.NewPaletted(image.Rect(0, 0, size, size),
+		color.Palette{color.White, color.Black})
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if code.Black(x/scale-barcodeQuietZone, y/scale-barcodeQuietZone) {
+				img.SetColorIndex(x, y, 1)
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err = png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// BarcodeSVG renders the activation barcode as a QR code in SVG format, which
+// can be embedded directly in an HTML page.
+// scale is the width of each module in pixels.
+func (r *EnrollResult) BarcodeSVG(scale int) ([]byte, error) {
+	code, err := r.barcode(scale)
+	if err != nil {
+		return nil, err
+	}
+	modules := code.Size + 2*barcodeQuietZone
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		modules*scale, modules*scale, modules, modules)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, modules, modules)
+	for y := 0; y < code.Size; y++ {
+		for x := 0; x < code.Size; x++ {
+			if code.Black(x, y) {
+				fmt.Fprintf(&buf, "M%d %dh1v1h-1z", x+barcodeQuietZone, y+barcodeQuietZone)
+			}
+		}
+	}
+	buf.WriteString(`"/></svg>`)
+	return buf.Bytes(), nil
+}
+
+// EnrollProgress is reported by WaitForEnrollment after each EnrollStatus call.
+type EnrollProgress struct {
+	// Status is the response of EnrollStatus: "waiting", "success" or "invalid".
+	Status string
+	// Polls is the number of EnrollStatus calls made so far.
+	Polls int
+	// Remaining is the time left before the activation code expires, or zero
+	// if it has no expiration.
+	Remaining time.Duration
+}
+
+var (
+	// ErrEnrollmentInvalid is returned by WaitForEnrollment when Duo reports
+	// the activation code as invalid.
+	ErrEnrollmentInvalid = errors.New("enrollment activation code is invalid")
+	// ErrEnrollmentExpired is returned by WaitForEnrollment when the
+	// activation code expires before the user completes enrollment.
+	ErrEnrollmentExpired = errors.New("enrollment activation code expired")
+)
+
+// Default time to wait between EnrollStatus calls made by WaitForEnrollment.
+const defaultEnrollPollInterval = 3 * time.Second
+
+// WaitForEnrollment polls EnrollStatus for an enrollment started with Enroll
+// until the user activates Duo Mobile, the activation code becomes invalid,
+// or it expires.
+// ctx can cancel the wait early.
+// interval is the time to wait between EnrollStatus calls. Zero uses three seconds.
+// progress, if not nil, is called after each EnrollStatus call.
+// It returns nil once enrollment succeeds, ErrEnrollmentInvalid or
+// ErrEnrollmentExpired if it cannot, or the error that stopped polling.
+//
+// Example:
+//
+//	enrollment, err := api.Enroll(authapi.EnrollUsername("jsmith"))
+//	...
+//	png, err := enrollment.BarcodePNG(4)
+//	...
+//	err = api.WaitForEnrollment(ctx, enrollment, 0, func(p authapi.EnrollProgress) {
+//		log.Printf("enrollment %s, %s left", p.Status, p.Remaining)
+//	})
+func (api *AuthApi) WaitForEnrollment(ctx context.Context,
+	enrollment *EnrollResult,
+	interval time.Duration,
+	progress func(EnrollProgress)) error {
+	if interval <= 0 {
+		interval = defaultEnrollPollInterval
+	}
+	expires := enrollment.ExpiresAt()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for polls := 1; ; polls++ {
+		var remaining time.Duration
+		if !expires.IsZero() {
+			remaining = time.Until(expires)
+			if remaining <= 0 {
+				return ErrEnrollmentExpired
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		result, err := api.EnrollStatus(enrollment.Response.User_Id, enrollment.Response.Activation_Code)
+		if err == nil {
+			err = result.Err()
+		}
+		if err != nil {
+			return err
+		}
+		if progress != nil {
+			if !expires.IsZero() {
+				remaining = time.Until(expires)
+				if remaining < 0 {
+					remaining = 0
+				}
+			}
+			progress(EnrollProgress{Status: result.Response, Polls: polls, Remaining: remaining})
+		}
+
+		switch result.Response {
+		case "success":
+			return nil
+		case "invalid":
+			return ErrEnrollmentInvalid
+		}
+
+		wait := interval
+		if !expires.IsZero() && time.Until(expires) < wait {
+			// Stop waiting as soon as the code expires.
+			wait = time.Until(expires)
+		}
+		timer.Reset(wait)
+	}
+}
@@ -0,0 +1,169 @@
+package authapi
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/duosecurity/duo_api_golang/internal/qrcode"
+)
+
+func testEnrollment(expiration int64) *EnrollResult {
+	enrollment := &EnrollResult{}
+	enrollment.Stat = "OK"
+	enrollment.Response.Activation_Barcode = "https://api-eval.duosecurity.com/frame/qr?value=8LIRa5danrICkhHtkLxi-cKLu2DWzDYCmBwBHY2YzW5ZYnYaRxA"
+	enrollment.Response.Activation_Code = "duo://8LIRa5danrICkhHtkLxi-cKLu2DWzDYCmBwBHY2YzW5ZYnYaRxA"
+	enrollment.Response.Expiration = expiration
+	enrollment.Response.User_Id = "DU94SWSN4ADHHJHF2HXT"
+	return enrollment
+}
+
+func TestBarcodePNG(t *testing.T) {
+	data, err := testEnrollment(0).BarcodePNG(2)
+	if err != nil {
+		t.Fatal("Failed to render barcode: " + err.Error())
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal("Failed to decode barcode: " + err.Error())
+	}
+	// The activation barcode fits in a version 4 (33x33) code, plus the quiet
+	// zone.
+	if size := img.Bounds().Dx(); size != (33+8)*2 || img.Bounds().Dy() != size {
+		t.Fatalf("Unexpected barcode size: %v", img.Bounds())
+	}
+	isBlack := func(x, y int) bool {
+		r, _, _, _ := img.At(x, y).RGBA()
+		return r == 0
+	}
+	if isBlack(7, 7) || !isBlack(8, 8) || !isBlack(9, 9) {
+		t.Error("Expected the finder pattern after the quiet zone")
+	}
+	// Like Duo's image, the code holds the value parameter of the barcode URL.
+	code, err := qrcode.Encode([]byte("8LIRa5danrICkhHtkLxi-cKLu2DWzDYCmBwBHY2YzW5ZYnYaRxA"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for y := 0; y < code.Size; y++ {
+		for x := 0; x < code.Size; x++ {
+			if isBlack((x+4)*2, (y+4)*2) != code.Black(x, y) {
+				t.Fatalf("Module (%d, %d) does not encode the barcode value", x, y)
+			}
+		}
+	}
+
+	if _, err := testEnrollment(0).BarcodePNG(0); err == nil {
+		t.Error("Expected error for scale 0")
+	}
+	if _, err := (&EnrollResult{}).BarcodePNG(4); err == nil {
+		t.Error("Expected error without an activation barcode")
+	}
+}
+
+func TestBarcodeSVG(t *testing.T) {
+	data, err := testEnrollment(0).BarcodeSVG(3)
+	if err != nil {
+		t.Fatal("Failed to render barcode: " + err.Error())
+	}
+	svg := string(data)
+	if !strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="123" height="123" viewBox="0 0 41 41"`) {
+		t.Errorf("Unexpected SVG header: %.120s", svg)
+	}
+	if !strings.Contains(svg, "M4 4h1v1h-1z") || !strings.HasSuffix(svg, "</svg>") {
+		t.Errorf("Unexpected SVG: %s", svg)
+	}
+}
+
+// enrollStatusServer answers EnrollStatus with statuses, in order.
+func enrollStatusServer(t *testing.T, statuses ...string) *httptest.Server {
+	return httptest.NewTLSServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				params, err := getBodyParams(r)
+				if err != nil {
+					t.Error("Failed to retrieve body parameters")
+				}
+				if params.Get("user_id") != "DU94SWSN4ADHHJHF2HXT" {
+					t.Error("Unexpected user_id: " + params.Get("user_id"))
+				}
+				status := statuses[0]
+				if len(statuses) > 1 {
+					statuses = statuses[1:]
+				}
+				fmt.Fprintf(w, `{"stat": "OK", "response": %q}`, status)
+			}))
+}
+
+func TestWaitForEnrollment(t *testing.T) {
+	ts := enrollStatusServer(t, "waiting", "waiting", "success")
+	defer ts.Close()
+	duo := buildAuthApi(ts.URL, nil)
+
+	var updates []EnrollProgress
+	enrollment := testEnrollment(time.Now().Add(time.Hour).Unix())
+	err := duo.WaitForEnrollment(context.Background(), enrollment, time.Millisecond, func(p EnrollProgress) {
+		updates = append(updates, p)
+	})
+	if err != nil {
+		t.Fatal("Failed to wait for enrollment: " + err.Error())
+	}
+	if len(updates) != 3 || updates[0].Status != "waiting" || updates[2].Status != "success" || updates[2].Polls != 3 {
+		t.Errorf("Unexpected progress: %v", updates)
+	}
+	if updates[0].Remaining <= 59*time.Minute || updates[0].Remaining > time.Hour {
+		t.Errorf("Unexpected remaining time: %s", updates[0].Remaining)
+	}
+}
+
+func TestWaitForEnrollmentInvalid(t *testing.T) {
+	ts := enrollStatusServer(t, "waiting", "invalid")
+	defer ts.Close()
+	duo := buildAuthApi(ts.URL, nil)
+
+	err := duo.WaitForEnrollment(context.Background(), testEnrollment(0), time.Millisecond, nil)
+	if err != ErrEnrollmentInvalid {
+		t.Errorf("Expected ErrEnrollmentInvalid, got %v", err)
+	}
+}
+
+func TestWaitForEnrollmentExpired(t *testing.T) {
+	ts := enrollStatusServer(t, "waiting")
+	defer ts.Close()
+	duo := buildAuthApi(ts.URL, nil)
+
+	polls := 0
+	enrollment := testEnrollment(time.Now().Add(2 * time.Second).Unix())
+	err := duo.WaitForEnrollment(context.Background(), enrollment, 100*time.Millisecond, func(p EnrollProgress) {
+		polls = p.Polls
+	})
+	if err != ErrEnrollmentExpired {
+		t.Errorf("Expected ErrEnrollmentExpired, got %v", err)
+	}
+	if polls == 0 {
+		t.Error("Expected EnrollStatus to be polled before expiring")
+	}
+
+	expired := testEnrollment(time.Now().Add(-time.Minute).Unix())
+	if err := duo.WaitForEnrollment(context.Background(), expired, time.Millisecond, nil); err != ErrEnrollmentExpired {
+		t.Errorf("Expected ErrEnrollmentExpired, got %v", err)
+	}
+}
+
+func TestWaitForEnrollmentCancel(t *testing.T) {
+	ts := enrollStatusServer(t, "waiting")
+	defer ts.Close()
+	duo := buildAuthApi(ts.URL, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := duo.WaitForEnrollment(ctx, testEnrollment(0), 10*time.Millisecond, nil)
+	if err != context.DeadlineExceeded {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
@@ -0,0 +1,193 @@
+package authapi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	duoapi "github.com/duosecurity/duo_api_golang"
+)
+
+// logoCacheFile is the name of the logo file kept in a LogoCache's directory.
+const logoCacheFile = "duo_logo.png"
+
+// LogoCache keeps a copy of the account logo so that login pages can show it
+// without calling Duo on every render. It also serves the logo over HTTP with
+// an ETag, so browsers revalidate instead of downloading it again.
+type LogoCache struct {
+	api *AuthApi
+	ttl time.Duration
+	dir string
+	now func() time.Time
+
+	mu      sync.Mutex
+	png     []byte
+	etag    string
+	fetched time.Time
+	// fetching is closed when the call to Duo in flight, if any, returns.
+	fetching chan struct{}
+}
+
+// Optional parameter for NewLogoCache, used to also keep the logo in dir so
+// that it survives restarts. The directory must exist and be writable.
+func SetLogoCacheDir(dir string) func(*LogoCache) {
+	return func(c *LogoCache) {
+		c.dir = dir
+	}
+}
+
+// NewLogoCache returns a LogoCache that fetches the logo with api, and
+// revalidates it with Duo when it is older than ttl.
+//
+// Example: http.Handle("/logo.png", authapi.NewLogoCache(api, time.Hour))
+func NewLogoCache(api *AuthApi, ttl time.Duration, options ...func(*LogoCache)) *LogoCache {
+	c := &LogoCache{api: api, ttl: ttl, now: time.Now}
+	for _, o := range options {
+		o(c)
+	}
+	return c
+}
+
+// Get returns a copy of the logo and its ETag, calling Duo only if the cached
+// copy is older than the cache's ttl. Revalidation is a conditional request,
+// so an unchanged logo is not downloaded again, and only one call is made at a
+// time; meanwhile, other callers get the stale copy. If Duo cannot be reached,
+// a stale copy is returned rather than an error.
+func (c *LogoCache) Get() ([]byte, string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.png == nil && c.dir != "" {
+		c.load()
+	}
+	for c.png == nil && c.fetching != nil {
+		// Nothing to serve yet; wait for the call in flight.
+		fetching := c.fetching
+		c.mu.Unlock()
+		<-fetching
+		c.mu.Lock()
+	}
+	if c.png != nil && (c.fetching != nil || c.now().Sub(c.fetched) < c.ttl) {
+		return c.copy()
+	}
+
+	fetching := make(chan struct{})
+	c.fetching = fetching
+	options := []duoapi.DuoApiOption{duoapi.UseTimeout}
+	if c.png != nil {
+		options = append(options, duoapi.UseHeader("If-Modified-Since", c.fetched.UTC().Format(http.TimeFormat)))
+	}
+	c.mu.Unlock()
+	result, err := c.api.logo(options...)
+	c.mu.Lock()
+	c.fetching = nil
+	close(fetching)
+
+	if err == nil {
+		err = result.Err()
+	}
+	if err != nil {
+		if c.png != nil {
+			return c.copy()
+		}
+		return nil, "", err
+	}
+
+	if result.notModified || logoETag(result.PNG()) == c.etag {
+		// Unchanged; only extend the copy's lifetime.
+		c.fetched = c.now()
+		c.touch()
+	} else {
+		c.png = result.PNG()
+		c.etag = logoETag(c.png)
+		c.fetched = c.now()
+		c.store()
+	}
+	return c.copy()
+}
+
+// copy returns a copy of the cached logo, so that callers cannot modify it,
+// and its ETag. The caller must hold c.mu.
+func (c *LogoCache) copy() ([]byte, string, error) {
+	return append([]byte(nil), c.png...), c.etag, nil
+}
+
+// ServeHTTP serves the logo, answering conditional requests with
+// 304 Not Modified when the browser's copy is current.
+func (c *LogoCache) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	png, etag, err := c.Get()
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "max-age="+strconv.Itoa(int(c.ttl/time.Second)))
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Content-Length", strconv.Itoa(len(png)))
+	w.Write(png)
+}
+
+// load reads the logo from the cache directory, if present.
+// The caller must hold c.mu.
+func (c *LogoCache) load() {
+	path := filepath.Join(c.dir, logoCacheFile)
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	png, err := ioutil.ReadFile(path)
+	if err != nil {
+		return
+	}
+	c.png = png
+	c.etag = logoETag(png)
+	c.fetched = info.ModTime()
+}
+
+// store writes the logo to the cache directory. Failures only cost a call to
+// Duo after a restart, so they are ignored. The caller must hold c.mu.
+func (c *LogoCache) store() {
+	if c.dir == "" {
+		return
+	}
+	tmp, err := ioutil.TempFile(c.dir, logoCacheFile)
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(c.png)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), filepath.Join(c.dir, logoCacheFile))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	c.touch()
+}
+
+// touch records the fetch time as the modification time of the cached file.
+// The caller must hold c.mu.
+func (c *LogoCache) touch() {
+	if c.dir == "" {
+		return
+	}
+	os.Chtimes(filepath.Join(c.dir, logoCacheFile), c.fetched, c.fetched)
+}
+
+func logoETag(png []byte) string {
+	sum := sha256.Sum256(png)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
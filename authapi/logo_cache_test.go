@@ -0,0 +1,199 @@
+package authapi
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testLogo = "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00" +
+	"\x00\x00\x01\x00\x00\x00\x01\x08\x06\x00" +
+	"\x00\x00\x1f\x15\xc4\x89\x00\x00\x00\nIDATx" +
+	"\x9cc\x00\x01\x00\x00\x05\x00\x01\r\n-\xb4\x00" +
+	"\x00\x00\x00IEND\xaeB`\x82"
+
+// logoServer serves testLogo and counts the calls made to it. It answers
+// conditional requests with 304 Not Modified, and waits for release, if set,
+// before answering.
+type logoServer struct {
+	*httptest.Server
+	mu            sync.Mutex
+	calls         int
+	fail          bool
+	modifiedSince string
+	release       chan struct{}
+}
+
+func newLogoServer() *logoServer {
+	s := &logoServer{}
+	s.Server = httptest.NewTLSServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				s.mu.Lock()
+				s.calls++
+				s.modifiedSince = r.Header.Get("If-Modified-Since")
+				fail, release := s.fail, s.release
+				s.mu.Unlock()
+				if release != nil {
+					<-release
+				}
+				if fail {
+					w.WriteHeader(500)
+					return
+				}
+				if r.Header.Get("If-Modified-Since") != "" {
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+				w.Header().Set("Content-Type", "image/png")
+				w.Write([]byte(testLogo))
+			}))
+	return s
+}
+
+func (s *logoServer) callCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+func TestLogoCache(t *testing.T) {
+	ts := newLogoServer()
+	defer ts.Close()
+
+	now := time.Unix(1357020061, 0)
+	cache := NewLogoCache(buildAuthApi(ts.URL, nil), time.Hour)
+	cache.now = func() time.Time { return now }
+
+	png, etag, err := cache.Get()
+	if err != nil {
+		t.Fatal("Failed to get logo: " + err.Error())
+	}
+	if string(png) != testLogo || etag == "" {
+		t.Errorf("Unexpected logo %q with etag %q", png, etag)
+	}
+
+	// Within the ttl, Duo is not called again.
+	now = now.Add(30 * time.Minute)
+	if _, again, _ := cache.Get(); again != etag || ts.callCount() != 1 {
+		t.Errorf("Expected cached logo, got etag %q after %d calls", again, ts.callCount())
+	}
+
+	// Once stale, the logo is revalidated with Duo.
+	now = now.Add(time.Hour)
+	if png, again, _ := cache.Get(); string(png) != testLogo || again != etag || ts.callCount() != 2 {
+		t.Errorf("Expected revalidated logo, got etag %q after %d calls", again, ts.callCount())
+	}
+	if ts.modifiedSince != "Tue, 01 Jan 2013 06:01:01 GMT" {
+		t.Errorf("Expected a conditional request, got If-Modified-Since %q", ts.modifiedSince)
+	}
+
+	// Callers get their own copy of the logo.
+	png[0] = 0
+	if again, _, _ := cache.Get(); string(again) != testLogo {
+		t.Errorf("Expected an unmodified logo, got %q", again)
+	}
+
+	// A stale copy is preferred to an error when Duo is unavailable.
+	ts.mu.Lock()
+	ts.fail = true
+	ts.mu.Unlock()
+	now = now.Add(2 * time.Hour)
+	if png, _, err := cache.Get(); err != nil || string(png) != testLogo {
+		t.Errorf("Expected stale logo when Duo fails, got %v", err)
+	}
+}
+
+// Test that callers are not blocked by a revalidation in flight, and that
+// only one call is made to Duo.
+func TestLogoCacheConcurrentRevalidation(t *testing.T) {
+	ts := newLogoServer()
+	defer ts.Close()
+
+	now := time.Unix(1357020061, 0)
+	cache := NewLogoCache(buildAuthApi(ts.URL, nil), time.Hour)
+	cache.now = func() time.Time { return now }
+	if _, _, err := cache.Get(); err != nil {
+		t.Fatal("Failed to get logo: " + err.Error())
+	}
+
+	ts.mu.Lock()
+	ts.release = make(chan struct{})
+	ts.mu.Unlock()
+	now = now.Add(2 * time.Hour)
+	done := make(chan struct{})
+	go func() {
+		cache.Get()
+		close(done)
+	}()
+	for ts.callCount() != 2 {
+		time.Sleep(time.Millisecond)
+	}
+	if png, _, err := cache.Get(); err != nil || string(png) != testLogo {
+		t.Errorf("Expected stale logo during revalidation, got %v", err)
+	}
+	close(ts.release)
+	<-done
+	if ts.callCount() != 2 {
+		t.Errorf("Expected a single revalidation, got %d calls", ts.callCount())
+	}
+}
+
+func TestLogoCacheServeHTTP(t *testing.T) {
+	ts := newLogoServer()
+	defer ts.Close()
+
+	cache := NewLogoCache(buildAuthApi(ts.URL, nil), time.Hour)
+
+	rec := httptest.NewRecorder()
+	cache.ServeHTTP(rec, httptest.NewRequest("GET", "/logo.png", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != testLogo {
+		t.Fatalf("Expected logo, got %d", rec.Code)
+	}
+	if contentType := rec.Header().Get("Content-Type"); contentType != "image/png" {
+		t.Errorf("Unexpected content type: %q", contentType)
+	}
+	etag := rec.Header().Get("ETag")
+
+	req := httptest.NewRequest("GET", "/logo.png", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	cache.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Errorf("Expected 304 Not Modified, got %d", rec.Code)
+	}
+	if ts.callCount() != 1 {
+		t.Errorf("Expected a single call to Duo, got %d", ts.callCount())
+	}
+}
+
+func TestLogoCacheDir(t *testing.T) {
+	ts := newLogoServer()
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "logocache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	first := NewLogoCache(buildAuthApi(ts.URL, nil), time.Hour, SetLogoCacheDir(dir))
+	_, etag, err := first.Get()
+	if err != nil {
+		t.Fatal("Failed to get logo: " + err.Error())
+	}
+
+	// A new cache, as after a restart, uses the copy on disk.
+	second := NewLogoCache(buildAuthApi(ts.URL, nil), time.Hour, SetLogoCacheDir(dir))
+	png, again, err := second.Get()
+	if err != nil || string(png) != testLogo || again != etag {
+		t.Errorf("Expected logo from disk, got etag %q, %v", again, err)
+	}
+	if ts.callCount() != 1 {
+		t.Errorf("Expected a single call to Duo, got %d", ts.callCount())
+	}
+}
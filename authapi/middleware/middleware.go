@@ -0,0 +1,451 @@
+// Package middleware provides a net/http handler that requires users to complete
+// Duo second-factor authentication, using the Auth API, before they can reach
+// the wrapped handler.
+//
+// The middleware does not perform primary authentication. An IdentityFunc
+// supplied by the application reports who the user is, usually by inspecting
+// the application's own login session, and the middleware then drives the
+// Preauth, Auth and AuthStatus calls for that user.
+package middleware
+
+import (
+	"crypto/subtle"
+	"errors"
+	"html/template"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	duoapi "github.com/duosecurity/duo_api_golang"
+	"github.com/duosecurity/duo_api_golang/authapi"
+)
+
+// IdentityFunc returns the primary identity (the Duo username) of the user
+// making the request. An empty username means the user has not completed
+// primary authentication.
+type IdentityFunc func(r *http.Request) (username string, err error)
+
+// Middleware is an http.Handler enforcing Duo second-factor authentication in
+// front of another http.Handler.
+type Middleware struct {
+	api      *authapi.AuthApi
+	identity IdentityFunc
+	next     http.Handler
+
+	path            string
+	store           SessionStore
+	templates       *template.Template
+	unauthenticated http.Handler
+	clientIP        func(r *http.Request) string
+	failOpen        bool
+	trustedStore    func(w http.ResponseWriter, r *http.Request) authapi.TrustedDeviceStore
+	fingerprint     func(w http.ResponseWriter, r *http.Request) string
+	trustedLifetime time.Duration
+	logger          *log.Logger
+}
+
+// defaultPath is the URL path the prompt form is posted to.
+const defaultPath = "/duo"
+
+// defaultTrustedCookie stores the trusted device token returned by Duo,
+// unless SetTrustedDeviceStore or SetTrustedDeviceCookies is used.
+const defaultTrustedCookie = "duo_trusted_device"
+
+// Optional parameter for New, used to change the URL path of the prompt.
+// The path is handled by the middleware and never reaches the wrapped handler.
+func SetPath(path string) func(*Middleware) {
+	return func(m *Middleware) {
+		m.path = path
+	}
+}
+
+// Optional parameter for New, used to configure where sessions are stored.
+// The default is a MemoryStore with a 12 hour lifetime.
+func SetSessionStore(store SessionStore) func(*Middleware) {
+	return func(m *Middleware) {
+		m.store = store
+	}
+}
+
+// Optional parameter for New, used to replace the built-in pages. The
+// templates must define PromptTemplate, WaitingTemplate, DenyTemplate,
+// EnrollTemplate and ErrorTemplate, and are executed with a PageData.
+func SetTemplates(templates *template.Template) func(*Middleware) {
+	return func(m *Middleware) {
+		m.templates = templates
+	}
+}
+
+// Optional parameter for New, called when the IdentityFunc reports no user.
+// The default responds with 401 Unauthorized; applications usually redirect
+// to their login page instead.
+func SetUnauthenticatedHandler(handler http.Handler) func(*Middleware) {
+	return func(m *Middleware) {
+		m.unauthenticated = handler
+	}
+}
+
+// Optional parameter for New, used to determine the client IP address sent to
+// Duo. The default uses the host part of the request's RemoteAddr, which is
+// wrong behind a reverse proxy.
+func SetClientIP(clientIP func(r *http.Request) string) func(*Middleware) {
+	return func(m *Middleware) {
+		m.clientIP = clientIP
+	}
+}
+
+// Optional parameter for New. When Duo cannot be reached, or reports an
+// internal error, let users through instead of showing the error page.
+func SetFailOpen() func(*Middleware) {
+	return func(m *Middleware) {
+		m.failOpen = true
+	}
+}
+
+// Optional parameter for New, used to log failed Duo calls, trusted device
+// store failures and template errors. The default is the standard logger of
+// package log.
+func SetLogger(logger *log.Logger) func(*Middleware) {
+	return func(m *Middleware) {
+		m.logger = logger
+	}
+}
+
+// New builds a Middleware protecting next.
+// api is used to make the Duo Auth API calls.
+// identity returns the primary identity of the user making each request.
+// options are optional parameters, such as SetSessionStore and SetTemplates.
+//
+// Example: middleware.New(api, lookupUser, mux, middleware.SetPath("/2fa"))
+func New(api *authapi.AuthApi,
+	identity IdentityFunc,
+	next http.Handler,
+	options ...func(*Middleware)) *Middleware {
+	m := &Middleware{
+		api:       api,
+		identity:  identity,
+		next:      next,
+		path:      defaultPath,
+		store:     NewMemoryStore(12 * time.Hour),
+		templates: defaultTemplates,
+		clientIP:  remoteHost,
+		trustedStore: func(w http.ResponseWriter, r *http.Request) authapi.TrustedDeviceStore {
+			return &plainCookieStore{name: defaultTrustedCookie, w: w, r: r}
+		},
+		fingerprint: noFingerprint,
+		unauthenticated: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		}),
+	}
+	for _, o := range options {
+		o(m)
+	}
+	return m
+}
+
+// ServeHTTP implements http.Handler.
+func (m *Middleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	username, err := m.identity(r)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if username == "" {
+		m.unauthenticated.ServeHTTP(w, r)
+		return
+	}
+
+	session, err := m.store.Load(r)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if session.Username != username {
+		// Never carry second-factor state over to a different user.
+		session = &Session{Username: username}
+	}
+	if session.CSRFToken == "" {
+		if session.CSRFToken, err = randomToken(); err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	switch {
+	case session.Verified && r.URL.Path == m.path:
+		m.redirect(w, r, session)
+	case session.Verified:
+		m.next.ServeHTTP(w, r)
+	case r.URL.Path == m.path && r.Method == http.MethodPost:
+		m.submit(w, r, session)
+	case r.URL.Path == m.path && session.Txid != "":
+		m.status(w, r, session)
+	default:
+		m.preauth(w, r, session)
+	}
+}
+
+// preauth determines whether the user may log in, and shows the prompt if a
+// second factor is required.
+func (m *Middleware) preauth(w http.ResponseWriter, r *http.Request, session *Session) {
+	if r.URL.Path != m.path {
+		session.ReturnTo = r.URL.RequestURI()
+	}
+
+	devices, fingerprint := m.trustedDevices(w, r)
+	result, err := devices.Preauth(authapi.AuthUser{Username: session.Username}, fingerprint,
+		authapi.PreauthIpAddr(m.clientIP(r)))
+	if err = m.storeError(err); err != nil {
+		m.fail(w, r, session, err, nil)
+		return
+	}
+	if result.Stat != "OK" {
+		m.fail(w, r, session, nil, &result.StatResult)
+		return
+	}
+
+	data := m.pageData(session, result.Response.Status_Msg)
+	switch result.Response.Result {
+	case "allow":
+		if m.verified(w, r, session) {
+			m.next.ServeHTTP(w, r)
+		}
+	case "enroll":
+		data.EnrollPortalURL = result.Response.Enroll_Portal_Url
+		m.render(w, r, session, http.StatusForbidden, EnrollTemplate, data)
+	case "auth":
+		data.Preauth = result
+		m.render(w, r, session, http.StatusOK, PromptTemplate, data)
+	default:
+		m.render(w, r, session, http.StatusForbidden, DenyTemplate, data)
+	}
+}
+
+// submit handles a posted prompt form, starting the chosen factor.
+func (m *Middleware) submit(w http.ResponseWriter, r *http.Request, session *Session) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	token := r.PostForm.Get("csrf_token")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(session.CSRFToken)) != 1 {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	factor := r.PostForm.Get("factor")
+	options := []func(*url.Values){
+		authapi.AuthIpAddr(m.clientIP(r)),
+	}
+	switch factor {
+	case "passcode":
+		options = append(options, authapi.AuthPasscode(r.PostForm.Get("passcode")))
+	case "push", "phone":
+		options = append(options, authapi.AuthDevice(deviceOrAuto(r)), authapi.AuthAsync())
+	case "sms":
+		options = append(options, authapi.AuthDevice(deviceOrAuto(r)))
+	default:
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	devices, fingerprint := m.trustedDevices(w, r)
+	result, err := devices.Auth(authapi.AuthUser{Username: session.Username}, fingerprint, factor, options...)
+	if err = m.storeError(err); err != nil {
+		m.fail(w, r, session, err, nil)
+		return
+	}
+	if result.Stat != "OK" {
+		m.fail(w, r, session, nil, &result.StatResult)
+		return
+	}
+
+	if result.Response.Txid != "" {
+		session.Txid = result.Response.Txid
+		m.save(w, r, session)
+		http.Redirect(w, r, m.path, http.StatusSeeOther)
+		return
+	}
+
+	data := m.pageData(session, result.Response.Status_Msg)
+	switch {
+	case result.Response.Result == "allow":
+		if m.verified(w, r, session) {
+			m.redirect(w, r, session)
+		}
+	case factor == "sms":
+		// SMS passcodes were sent. Prompt again so the user can enter one.
+		m.reprompt(w, r, session, data)
+	default:
+		m.render(w, r, session, http.StatusForbidden, DenyTemplate, data)
+	}
+}
+
+// status polls an outstanding asynchronous authentication.
+func (m *Middleware) status(w http.ResponseWriter, r *http.Request, session *Session) {
+	devices, fingerprint := m.trustedDevices(w, r)
+	result, err := devices.AuthStatus(authapi.AuthUser{Username: session.Username}, fingerprint, session.Txid)
+	if err = m.storeError(err); err != nil {
+		session.Txid = ""
+		m.fail(w, r, session, err, nil)
+		return
+	}
+	if result.Stat != "OK" {
+		session.Txid = ""
+		m.fail(w, r, session, nil, &result.StatResult)
+		return
+	}
+
+	data := m.pageData(session, result.Response.Status_Msg)
+	switch result.Response.Result {
+	case "waiting":
+		m.render(w, r, session, http.StatusOK, WaitingTemplate, data)
+	case "allow":
+		session.Txid = ""
+		if m.verified(w, r, session) {
+			m.redirect(w, r, session)
+		}
+	default:
+		session.Txid = ""
+		m.render(w, r, session, http.StatusForbidden, DenyTemplate, data)
+	}
+}
+
+// reprompt runs preauth again to show the device list alongside a status
+// message. It goes through the trusted devices like the first preauth, so a
+// remembered device is still let through.
+func (m *Middleware) reprompt(w http.ResponseWriter, r *http.Request, session *Session, data PageData) {
+	devices, fingerprint := m.trustedDevices(w, r)
+	result, err := devices.Preauth(authapi.AuthUser{Username: session.Username}, fingerprint,
+		authapi.PreauthIpAddr(m.clientIP(r)))
+	if err = m.storeError(err); err != nil {
+		m.fail(w, r, session, err, nil)
+		return
+	}
+	if result.Stat != "OK" {
+		m.fail(w, r, session, nil, &result.StatResult)
+		return
+	}
+	if result.Response.Result == "allow" {
+		if m.verified(w, r, session) {
+			m.redirect(w, r, session)
+		}
+		return
+	}
+	data.Preauth = result
+	m.render(w, r, session, http.StatusOK, PromptTemplate, data)
+}
+
+// fail handles a Duo call that did not succeed, with either the error it
+// returned or its failed result. Duo being unavailable (a transport error or
+// a 5xxxx error code) is handled according to the configured fail mode; a
+// rejected request denies the user, and a trusted device store failure
+// always shows the error page.
+func (m *Middleware) fail(w http.ResponseWriter,
+	r *http.Request,
+	session *Session,
+	err error,
+	stat *duoapi.StatResult) {
+	if err != nil {
+		m.logf("duo middleware: Duo call for %q failed: %v", session.Username, err)
+	} else {
+		m.logf("duo middleware: Duo call for %q failed: %v", session.Username, stat.Err())
+	}
+	var storeErr *authapi.TrustedDeviceStoreError
+	if errors.As(err, &storeErr) {
+		m.render(w, r, session, http.StatusServiceUnavailable, ErrorTemplate, m.pageData(session, ""))
+		return
+	}
+	unavailable := err != nil || (stat.Code != nil && *stat.Code >= 50000)
+	if !unavailable {
+		m.render(w, r, session, http.StatusForbidden, DenyTemplate, m.pageData(session, ""))
+		return
+	}
+	if !m.failOpen {
+		m.render(w, r, session, http.StatusServiceUnavailable, ErrorTemplate, m.pageData(session, ""))
+		return
+	}
+	if r.URL.Path == m.path {
+		m.redirect(w, r, session)
+		return
+	}
+	m.next.ServeHTTP(w, r)
+}
+
+// verified marks the session as having completed second-factor authentication.
+func (m *Middleware) verified(w http.ResponseWriter, r *http.Request, session *Session) bool {
+	session.Verified = true
+	session.Txid = ""
+	return m.save(w, r, session)
+}
+
+// redirect sends the user back to the page they originally requested.
+func (m *Middleware) redirect(w http.ResponseWriter, r *http.Request, session *Session) {
+	target := session.ReturnTo
+	// Only redirect to local paths; "//host" would leave the site.
+	if !strings.HasPrefix(target, "/") || strings.HasPrefix(target, "//") || strings.HasPrefix(target, "/\\") {
+		target = "/"
+	}
+	http.Redirect(w, r, target, http.StatusSeeOther)
+}
+
+func (m *Middleware) pageData(session *Session, statusMsg string) PageData {
+	return PageData{
+		Username:  session.Username,
+		Action:    m.path,
+		CSRFToken: session.CSRFToken,
+		StatusMsg: statusMsg,
+	}
+}
+
+func (m *Middleware) render(w http.ResponseWriter,
+	r *http.Request,
+	session *Session,
+	code int,
+	name string,
+	data PageData) {
+	if !m.save(w, r, session) {
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	if err := m.templates.ExecuteTemplate(w, name, data); err != nil {
+		m.logf("duo middleware: failed to render %s page: %v", name, err)
+	}
+}
+
+// save stores the session, responding with an error if that fails.
+func (m *Middleware) save(w http.ResponseWriter, r *http.Request, session *Session) bool {
+	if err := m.store.Save(w, r, session); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
+func (m *Middleware) logf(format string, args ...interface{}) {
+	if m.logger != nil {
+		m.logger.Printf(format, args...)
+		return
+	}
+	log.Printf(format, args...)
+}
+
+func deviceOrAuto(r *http.Request) string {
+	if device := r.PostForm.Get("device"); device != "" {
+		return device
+	}
+	return "auto"
+}
+
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
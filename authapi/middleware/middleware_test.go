@@ -0,0 +1,484 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	duoapi "github.com/duosecurity/duo_api_golang"
+	"github.com/duosecurity/duo_api_golang/authapi"
+)
+
+func buildAuthApi(url string) *authapi.AuthApi {
+	host := strings.Split(url, "//")[1]
+	return authapi.NewAuthApi(*duoapi.NewDuoApi("eyekey",
+		"esskey",
+		host,
+		"GoTestClient",
+		duoapi.SetTimeout(1*time.Second),
+		duoapi.SetInsecure()))
+}
+
+const preauthAuthResponse = `{
+	"stat": "OK",
+	"response": {
+		"result": "auth",
+		"status_msg": "Account is active",
+		"devices": [{
+			"device": "DPFZRS9FB0D46QFTM891",
+			"type": "phone",
+			"number": "XXX-XXX-0100",
+			"name": "",
+			"capabilities": ["push", "sms", "phone"]
+		}]
+	}
+}`
+
+// fakeDuo serves canned responses for each Auth API path and records the
+// parameters of the last request to each.
+type fakeDuo struct {
+	responses map[string][]string
+	params    map[string]url.Values
+}
+
+func newFakeDuo(t *testing.T, responses map[string][]string) (*fakeDuo, *httptest.Server) {
+	fake := &fakeDuo{responses: responses, params: make(map[string]url.Values)}
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("Failed to parse request: %v", err)
+		}
+		fake.params[r.URL.Path] = r.Form
+		queue := fake.responses[r.URL.Path]
+		if len(queue) == 0 {
+			t.Errorf("Unexpected request to %s", r.URL.Path)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprintln(w, queue[0])
+		if len(queue) > 1 {
+			fake.responses[r.URL.Path] = queue[1:]
+		}
+	}))
+	return fake, ts
+}
+
+// client drives the middleware, keeping cookies between requests.
+type client struct {
+	handler http.Handler
+	cookies map[string]*http.Cookie
+}
+
+func (c *client) do(method, target string, form url.Values) *httptest.ResponseRecorder {
+	var req *http.Request
+	if form != nil {
+		req = httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	} else {
+		req = httptest.NewRequest(method, target, nil)
+	}
+	for _, cookie := range c.cookies {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	c.handler.ServeHTTP(rec, req)
+	for _, cookie := range rec.Result().Cookies() {
+		c.cookies[cookie.Name] = cookie
+	}
+	return rec
+}
+
+func newClient(api *authapi.AuthApi, options ...func(*Middleware)) *client {
+	protected := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "protected content")
+	})
+	identity := func(r *http.Request) (string, error) {
+		return r.Header.Get("X-Test-User"), nil
+	}
+	m := New(api, identity, protected, options...)
+	return &client{
+		handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Header.Set("X-Test-User", "jsmith")
+			m.ServeHTTP(w, r)
+		}),
+		cookies: make(map[string]*http.Cookie),
+	}
+}
+
+func csrfToken(t *testing.T, page string) string {
+	marker := `name="csrf_token" value="`
+	start := strings.Index(page, marker)
+	if start < 0 {
+		t.Fatalf("Prompt page has no CSRF token: %s", page)
+	}
+	page = page[start+len(marker):]
+	return page[:strings.Index(page, `"`)]
+}
+
+// Test that the wrapped handler is reached without a prompt when preauth allows.
+func TestPreauthAllow(t *testing.T) {
+	fake, ts := newFakeDuo(t, map[string][]string{
+		"/auth/v2/preauth": {`{"stat": "OK", "response": {"result": "allow", "status_msg": "Allowing unknown user"}}`},
+	})
+	defer ts.Close()
+
+	c := newClient(buildAuthApi(ts.URL))
+	rec := c.do("GET", "/secret", nil)
+	if rec.Code != http.StatusOK || rec.Body.String() != "protected content" {
+		t.Errorf("Expected protected content, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if username := fake.params["/auth/v2/preauth"].Get("username"); username != "jsmith" {
+		t.Errorf("Expected preauth for jsmith, got %q", username)
+	}
+
+	// The session is verified, so Duo is not consulted again.
+	rec = c.do("GET", "/secret", nil)
+	if rec.Code != http.StatusOK || rec.Body.String() != "protected content" {
+		t.Errorf("Expected protected content, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+// Test the deny and enroll preauth results.
+func TestPreauthDenyAndEnroll(t *testing.T) {
+	_, ts := newFakeDuo(t, map[string][]string{
+		"/auth/v2/preauth": {
+			`{"stat": "OK", "response": {"result": "deny", "status_msg": "Account is locked out"}}`,
+			`{"stat": "OK", "response": {"result": "enroll", "status_msg": "Enroll an authentication device to proceed", "enroll_portal_url": "https://api-3945ef22.duosecurity.com/portal?48bac5d9393fb2c2"}}`,
+		},
+	})
+	defer ts.Close()
+
+	c := newClient(buildAuthApi(ts.URL))
+	rec := c.do("GET", "/secret", nil)
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "Account is locked out") {
+		t.Errorf("Expected deny page, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = c.do("GET", "/secret", nil)
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "https://api-3945ef22.duosecurity.com/portal?48bac5d9393fb2c2") {
+		t.Errorf("Expected enroll page, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+// Test an asynchronous push, from the prompt through AuthStatus polling.
+func TestPushFlow(t *testing.T) {
+	fake, ts := newFakeDuo(t, map[string][]string{
+		"/auth/v2/preauth": {preauthAuthResponse},
+		"/auth/v2/auth":    {`{"stat": "OK", "response": {"txid": "45f7c92b-f45f-4862-8545-e0f58e78075a"}}`},
+		"/auth/v2/auth_status": {
+			`{"stat": "OK", "response": {"result": "waiting", "status": "pushed", "status_msg": "Pushed a login request to your phone..."}}`,
+			`{"stat": "OK", "response": {"result": "allow", "status": "allow", "status_msg": "Success. Logging you in...", "trusted_device_token": "l33t"}}`,
+		},
+	})
+	defer ts.Close()
+
+	c := newClient(buildAuthApi(ts.URL))
+	rec := c.do("GET", "/secret?page=2", nil)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "DPFZRS9FB0D46QFTM891") {
+		t.Fatalf("Expected prompt page, got %d: %s", rec.Code, rec.Body.String())
+	}
+	token := csrfToken(t, rec.Body.String())
+
+	rec = c.do("POST", "/duo", url.Values{
+		"csrf_token": {token},
+		"factor":     {"push"},
+		"device":     {"DPFZRS9FB0D46QFTM891"},
+	})
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/duo" {
+		t.Fatalf("Expected redirect to status page, got %d: %s", rec.Code, rec.Header().Get("Location"))
+	}
+	authParams := fake.params["/auth/v2/auth"]
+	if authParams.Get("factor") != "push" || authParams.Get("device") != "DPFZRS9FB0D46QFTM891" || authParams.Get("async") != "1" {
+		t.Errorf("Unexpected auth parameters: %v", authParams)
+	}
+
+	rec = c.do("GET", "/duo", nil)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Pushed a login request") {
+		t.Fatalf("Expected waiting page, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = c.do("GET", "/duo", nil)
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/secret?page=2" {
+		t.Fatalf("Expected redirect to original page, got %d: %s", rec.Code, rec.Header().Get("Location"))
+	}
+	if txid := fake.params["/auth/v2/auth_status"].Get("txid"); txid != "45f7c92b-f45f-4862-8545-e0f58e78075a" {
+		t.Errorf("Unexpected txid: %q", txid)
+	}
+	if cookie := c.cookies[defaultTrustedCookie]; cookie == nil || cookie.Value != "jsmith:l33t" {
+		t.Errorf("Expected trusted device token cookie, got %v", cookie)
+	}
+
+	rec = c.do("GET", "/secret?page=2", nil)
+	if rec.Body.String() != "protected content" {
+		t.Errorf("Expected protected content, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+// Test a denied passcode, and that a trusted device token is sent to preauth.
+func TestPasscodeDeny(t *testing.T) {
+	fake, ts := newFakeDuo(t, map[string][]string{
+		"/auth/v2/preauth": {preauthAuthResponse},
+		"/auth/v2/auth":    {`{"stat": "OK", "response": {"result": "deny", "status": "deny", "status_msg": "Incorrect passcode. Please try again."}}`},
+	})
+	defer ts.Close()
+
+	c := newClient(buildAuthApi(ts.URL))
+	c.cookies[defaultTrustedCookie] = &http.Cookie{Name: defaultTrustedCookie, Value: "jsmith:l33t"}
+	rec := c.do("GET", "/secret", nil)
+	token := csrfToken(t, rec.Body.String())
+	if trusted := fake.params["/auth/v2/preauth"].Get("trusted_device_token"); trusted != "l33t" {
+		t.Errorf("Expected trusted device token in preauth, got %q", trusted)
+	}
+
+	rec = c.do("POST", "/duo", url.Values{
+		"csrf_token": {token},
+		"factor":     {"passcode"},
+		"passcode":   {"123456"},
+	})
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "Incorrect passcode") {
+		t.Errorf("Expected deny page, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if passcode := fake.params["/auth/v2/auth"].Get("passcode"); passcode != "123456" {
+		t.Errorf("Expected passcode 123456, got %q", passcode)
+	}
+}
+
+// Test that prompt submissions without the session's CSRF token are rejected.
+func TestCSRF(t *testing.T) {
+	_, ts := newFakeDuo(t, map[string][]string{
+		"/auth/v2/preauth": {preauthAuthResponse},
+	})
+	defer ts.Close()
+
+	c := newClient(buildAuthApi(ts.URL))
+	c.do("GET", "/secret", nil)
+	rec := c.do("POST", "/duo", url.Values{
+		"csrf_token": {"forged"},
+		"factor":     {"passcode"},
+		"passcode":   {"123456"},
+	})
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403, got %d", rec.Code)
+	}
+}
+
+// Test the unauthenticated handler and both fail modes.
+func TestUnauthenticatedAndFailMode(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintln(w, `{"stat": "FAIL", "code": 50000, "message": "Internal error"}`)
+	}))
+	defer ts.Close()
+	api := buildAuthApi(ts.URL)
+
+	protected := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "protected content")
+	})
+	nobody := func(r *http.Request) (string, error) { return "", nil }
+	rec := httptest.NewRecorder()
+	New(api, nobody, protected).ServeHTTP(rec, httptest.NewRequest("GET", "/secret", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a primary identity, got %d", rec.Code)
+	}
+
+	rec = newClient(api).do("GET", "/secret", nil)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 when failing closed, got %d", rec.Code)
+	}
+
+	rec = newClient(api, SetFailOpen()).do("GET", "/secret", nil)
+	if rec.Code != http.StatusOK || rec.Body.String() != "protected content" {
+		t.Errorf("Expected protected content when failing open, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+// Test that request errors deny the user even when failing open.
+func TestFailOpenRequestError(t *testing.T) {
+	_, ts := newFakeDuo(t, map[string][]string{
+		"/auth/v2/preauth": {`{"stat": "FAIL", "code": 40002, "message": "Invalid request parameters"}`},
+	})
+	defer ts.Close()
+
+	rec := newClient(buildAuthApi(ts.URL), SetFailOpen()).do("GET", "/secret", nil)
+	if rec.Code != http.StatusForbidden || strings.Contains(rec.Body.String(), "protected content") {
+		t.Errorf("Expected deny page, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+type brokenStore struct{}
+
+func (brokenStore) Get(user, device string) (string, error) { return "", errors.New("store down") }
+func (brokenStore) Put(user, device, token string, expires time.Time) error {
+	return errors.New("store down")
+}
+func (brokenStore) Delete(user, device string) error { return errors.New("store down") }
+
+// Test that a failing trusted device store neither denies nor fails open, and
+// is logged.
+func TestTrustedDeviceStoreFailure(t *testing.T) {
+	_, ts := newFakeDuo(t, map[string][]string{
+		"/auth/v2/preauth": {preauthAuthResponse},
+		"/auth/v2/auth":    {`{"stat": "OK", "response": {"result": "allow", "status": "allow", "status_msg": "Success. Logging you in...", "trusted_device_token": "l33t"}}`},
+	})
+	defer ts.Close()
+
+	var logs bytes.Buffer
+	c := newClient(buildAuthApi(ts.URL), SetTrustedDeviceStore(brokenStore{}, 24*time.Hour), SetLogger(log.New(&logs, "", 0)))
+	rec := c.do("GET", "/secret", nil)
+	if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "protected content") {
+		t.Fatalf("Expected prompt, got %d: %s", rec.Code, rec.Body.String())
+	}
+	rec = c.do("POST", "/duo", url.Values{
+		"csrf_token": {csrfToken(t, rec.Body.String())},
+		"factor":     {"passcode"},
+		"passcode":   {"123456"},
+	})
+	if rec.Code != http.StatusSeeOther {
+		t.Errorf("Expected redirect after passcode, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(logs.String(), "trusted device store: Get: store down") ||
+		!strings.Contains(logs.String(), "trusted device store: Put: store down") {
+		t.Errorf("Expected store errors to be logged, got %q", logs.String())
+	}
+}
+
+// Test that template errors are logged.
+func TestTemplateError(t *testing.T) {
+	_, ts := newFakeDuo(t, map[string][]string{
+		"/auth/v2/preauth": {preauthAuthResponse},
+	})
+	defer ts.Close()
+
+	var logs bytes.Buffer
+	templates := template.Must(template.New(PromptTemplate).Parse(`{{.Missing}}`))
+	newClient(buildAuthApi(ts.URL), SetTemplates(templates), SetLogger(log.New(&logs, "", 0))).do("GET", "/secret", nil)
+	if !strings.Contains(logs.String(), "failed to render "+PromptTemplate) {
+		t.Errorf("Expected template error to be logged, got %q", logs.String())
+	}
+}
+
+// Test that the default trusted device cookie is only used for its own user.
+func TestTrustedCookieOtherUser(t *testing.T) {
+	fake, ts := newFakeDuo(t, map[string][]string{
+		"/auth/v2/preauth": {preauthAuthResponse},
+	})
+	defer ts.Close()
+
+	c := newClient(buildAuthApi(ts.URL))
+	c.cookies[defaultTrustedCookie] = &http.Cookie{Name: defaultTrustedCookie, Value: "mallory:l33t"}
+	c.do("GET", "/secret", nil)
+	if trusted := fake.params["/auth/v2/preauth"].Get("trusted_device_token"); trusted != "" {
+		t.Errorf("Expected no trusted device token in preauth, got %q", trusted)
+	}
+}
+
+// Test that the preauth run after sending SMS passcodes still uses the
+// remembered device.
+func TestSMSRepromptTrustedDevice(t *testing.T) {
+	fake, ts := newFakeDuo(t, map[string][]string{
+		"/auth/v2/preauth": {preauthAuthResponse, `{"stat": "OK", "response": {"result": "allow", "status_msg": "Account is active"}}`},
+		"/auth/v2/auth":    {`{"stat": "OK", "response": {"result": "deny", "status": "sent", "status_msg": "New SMS passcodes sent"}}`},
+	})
+	defer ts.Close()
+
+	store := authapi.NewMemoryTrustedDeviceStore()
+	c := newClient(buildAuthApi(ts.URL), SetTrustedDeviceStore(store, 24*time.Hour))
+	rec := c.do("GET", "/secret", nil)
+	device := c.cookies[defaultDeviceCookie]
+	if device == nil {
+		t.Fatalf("Expected device cookie, got %v", c.cookies)
+	}
+	store.Put("jsmith", device.Value, "l33t", time.Now().Add(time.Hour))
+
+	rec = c.do("POST", "/duo", url.Values{
+		"csrf_token": {csrfToken(t, rec.Body.String())},
+		"factor":     {"sms"},
+	})
+	if rec.Code != http.StatusSeeOther {
+		t.Errorf("Expected redirect for remembered device, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if trusted := fake.params["/auth/v2/preauth"].Get("trusted_device_token"); trusted != "l33t" {
+		t.Errorf("Expected trusted device token in preauth, got %q", trusted)
+	}
+}
+
+// Test that a device remembered in a server-side store skips the prompt.
+func TestTrustedDeviceStore(t *testing.T) {
+	fake, ts := newFakeDuo(t, map[string][]string{
+		"/auth/v2/preauth": {preauthAuthResponse, `{"stat": "OK", "response": {"result": "allow", "status_msg": "Account is active"}}`},
+		"/auth/v2/auth":    {`{"stat": "OK", "response": {"result": "allow", "status": "allow", "status_msg": "Success. Logging you in...", "trusted_device_token": "l33t"}}`},
+	})
+	defer ts.Close()
+
+	store := authapi.NewMemoryTrustedDeviceStore()
+	c := newClient(buildAuthApi(ts.URL), SetTrustedDeviceStore(store, 24*time.Hour))
+	rec := c.do("GET", "/secret", nil)
+	rec = c.do("POST", "/duo", url.Values{
+		"csrf_token": {csrfToken(t, rec.Body.String())},
+		"factor":     {"passcode"},
+		"passcode":   {"123456"},
+	})
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("Expected redirect after passcode, got %d", rec.Code)
+	}
+	device := c.cookies[defaultDeviceCookie]
+	if device == nil || device.Value == "" {
+		t.Fatalf("Expected device cookie, got %v", c.cookies)
+	}
+	if token, _ := store.Get("jsmith", device.Value); token != "l33t" {
+		t.Errorf("Expected stored token, got %q", token)
+	}
+	if _, ok := c.cookies[defaultTrustedCookie]; ok {
+		t.Error("Expected no plain trusted device cookie")
+	}
+
+	// A new session on the same device is allowed without a prompt.
+	delete(c.cookies, defaultSessionCookie)
+	rec = c.do("GET", "/secret", nil)
+	if rec.Body.String() != "protected content" {
+		t.Errorf("Expected protected content, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if trusted := fake.params["/auth/v2/preauth"].Get("trusted_device_token"); trusted != "l33t" {
+		t.Errorf("Expected trusted device token in preauth, got %q", trusted)
+	}
+}
+
+// Test that devices can be remembered in an encrypted cookie.
+func TestTrustedDeviceCookies(t *testing.T) {
+	fake, ts := newFakeDuo(t, map[string][]string{
+		"/auth/v2/preauth": {preauthAuthResponse, `{"stat": "OK", "response": {"result": "allow", "status_msg": "Account is active"}}`},
+		"/auth/v2/auth":    {`{"stat": "OK", "response": {"result": "allow", "status": "allow", "status_msg": "Success. Logging you in...", "trusted_device_token": "l33t"}}`},
+	})
+	defer ts.Close()
+
+	store, err := authapi.NewCookieTrustedDeviceStore([]byte("0123456789abcdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newClient(buildAuthApi(ts.URL), SetTrustedDeviceCookies(store, 24*time.Hour))
+	rec := c.do("GET", "/secret", nil)
+	c.do("POST", "/duo", url.Values{
+		"csrf_token": {csrfToken(t, rec.Body.String())},
+		"factor":     {"passcode"},
+		"passcode":   {"123456"},
+	})
+	if len(c.cookies) != 2 {
+		t.Errorf("Expected session and trusted device cookies, got %v", c.cookies)
+	}
+
+	delete(c.cookies, defaultSessionCookie)
+	rec = c.do("GET", "/secret", nil)
+	if rec.Body.String() != "protected content" {
+		t.Errorf("Expected protected content, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if trusted := fake.params["/auth/v2/preauth"].Get("trusted_device_token"); trusted != "l33t" {
+		t.Errorf("Expected trusted device token in preauth, got %q", trusted)
+	}
+}
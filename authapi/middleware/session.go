@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Session holds the second-factor state of a single browser session.
+type Session struct {
+	// Username is the primary identity the session was created for.
+	Username string
+	// Verified is true once the user has completed second-factor authentication.
+	Verified bool
+	// Txid is the transaction ID of an outstanding asynchronous Auth call.
+	Txid string
+	// ReturnTo is the URI the user originally requested, restored after a
+	// successful authentication.
+	ReturnTo string
+	// CSRFToken protects the prompt form from cross-site submissions.
+	CSRFToken string
+}
+
+// SessionStore loads and saves Sessions for incoming requests.
+// Load must return an empty, non-nil Session when the request has none.
+type SessionStore interface {
+	Load(r *http.Request) (*Session, error)
+	Save(w http.ResponseWriter, r *http.Request, s *Session) error
+}
+
+// defaultSessionCookie is the cookie used by MemoryStore to identify sessions.
+const defaultSessionCookie = "duo_session"
+
+type memoryEntry struct {
+	session Session
+	expires time.Time
+}
+
+// MemoryStore is a SessionStore that keeps sessions in process memory,
+// keyed by a random identifier stored in a cookie.
+type MemoryStore struct {
+	cookieName string
+	lifetime   time.Duration
+
+	mu       sync.Mutex
+	sessions map[string]memoryEntry
+}
+
+// NewMemoryStore returns a MemoryStore whose sessions expire after lifetime.
+// Sessions are lost when the process exits, and are not shared between
+// processes.
+func NewMemoryStore(lifetime time.Duration) *MemoryStore {
+	return &MemoryStore{
+		cookieName: defaultSessionCookie,
+		lifetime:   lifetime,
+		sessions:   make(map[string]memoryEntry),
+	}
+}
+
+// Load implements SessionStore.
+func (store *MemoryStore) Load(r *http.Request) (*Session, error) {
+	cookie, err := r.Cookie(store.cookieName)
+	if err != nil {
+		return &Session{}, nil
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	entry, ok := store.sessions[cookie.Value]
+	if !ok {
+		return &Session{}, nil
+	}
+	if time.Now().After(entry.expires) {
+		delete(store.sessions, cookie.Value)
+		return &Session{}, nil
+	}
+	session := entry.session
+	return &session, nil
+}
+
+// Save implements SessionStore.
+func (store *MemoryStore) Save(w http.ResponseWriter, r *http.Request, s *Session) error {
+	id := ""
+	if cookie, err := r.Cookie(store.cookieName); err == nil {
+		id = cookie.Value
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if _, ok := store.sessions[id]; !ok {
+		var err error
+		if id, err = randomToken(); err != nil {
+			return err
+		}
+		http.SetCookie(w, &http.Cookie{
+			Name:     store.cookieName,
+			Value:    id,
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   r.TLS != nil,
+			SameSite: http.SameSiteLaxMode,
+		})
+	}
+	store.sessions[id] = memoryEntry{
+		session: *s,
+		expires: time.Now().Add(store.lifetime),
+	}
+	store.expire()
+	return nil
+}
+
+// expire removes sessions that have outlived the store's lifetime.
+// The caller must hold store.mu.
+func (store *MemoryStore) expire() {
+	now := time.Now()
+	for id, entry := range store.sessions {
+		if now.After(entry.expires) {
+			delete(store.sessions, id)
+		}
+	}
+}
+
+// randomToken returns a random, hex-encoded 128 bit value.
+func randomToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
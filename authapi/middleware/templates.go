@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"html/template"
+
+	"github.com/duosecurity/duo_api_golang/authapi"
+)
+
+// Names of the templates executed by the middleware. Custom templates passed
+// to SetTemplates must define all of them.
+const (
+	// PromptTemplate lets the user pick a device and factor, or enter a passcode.
+	PromptTemplate = "prompt"
+	// WaitingTemplate is shown while an asynchronous push or phone call is pending.
+	// It should reload the page periodically.
+	WaitingTemplate = "waiting"
+	// DenyTemplate is shown when Duo denies the user access.
+	DenyTemplate = "deny"
+	// EnrollTemplate is shown when the user must enroll before authenticating.
+	EnrollTemplate = "enroll"
+	// ErrorTemplate is shown when the Duo service could not be reached.
+	ErrorTemplate = "error"
+)
+
+// PageData is passed to every template the middleware executes.
+type PageData struct {
+	// Username is the primary identity of the user.
+	Username string
+	// Action is the URL that the prompt form must be posted to.
+	Action string
+	// CSRFToken must be submitted with the prompt form in the "csrf_token" field.
+	CSRFToken string
+	// StatusMsg is the most recent status message returned by Duo.
+	StatusMsg string
+	// EnrollPortalURL is set when the user must enroll.
+	EnrollPortalURL string
+	// Preauth is the result of the preauth call, used to list the user's devices.
+	Preauth *authapi.PreauthResult
+}
+
+// defaultTemplates is a minimal set of pages used when SetTemplates is not given.
+var defaultTemplates = template.Must(template.New("duo").Parse(`
+{{define "prompt"}}<!DOCTYPE html>
+<html><head><title>Two-factor authentication</title></head>
+<body>
+<h1>Two-factor authentication</h1>
+{{with .StatusMsg}}<p>{{.}}</p>{{end}}
+{{$csrf := .CSRFToken}}{{$action := .Action}}
+{{range .Preauth.Response.Devices}}
+<form method="post" action="{{$action}}">
+<input type="hidden" name="csrf_token" value="{{$csrf}}">
+<input type="hidden" name="device" value="{{.Device}}">
+<fieldset><legend>{{if .Name}}{{.Name}}{{else}}{{.Type}}{{end}} {{.Number}}</legend>
+{{range .Capabilities}}{{if eq . "push" "phone" "sms"}}<button type="submit" name="factor" value="{{.}}">{{.}}</button>{{end}}{{end}}
+</fieldset>
+</form>
+{{end}}
+<form method="post" action="{{.Action}}">
+<input type="hidden" name="csrf_token" value="{{.CSRFToken}}">
+<input type="hidden" name="factor" value="passcode">
+<label>Passcode <input type="text" name="passcode" autocomplete="one-time-code"></label>
+<button type="submit">Verify</button>
+</form>
+</body></html>
+{{end}}
+
+{{define "waiting"}}<!DOCTYPE html>
+<html><head><title>Two-factor authentication</title>
+<meta http-equiv="refresh" content="2"></head>
+<body><p>{{with .StatusMsg}}{{.}}{{else}}Waiting for approval...{{end}}</p></body></html>
+{{end}}
+
+{{define "deny"}}<!DOCTYPE html>
+<html><head><title>Access denied</title></head>
+<body><p>{{with .StatusMsg}}{{.}}{{else}}Access denied.{{end}}</p></body></html>
+{{end}}
+
+{{define "enroll"}}<!DOCTYPE html>
+<html><head><title>Enrollment required</title></head>
+<body><p>{{.StatusMsg}}</p><p><a href="{{.EnrollPortalURL}}">Enroll a device</a></p></body></html>
+{{end}}
+
+{{define "error"}}<!DOCTYPE html>
+<html><head><title>Two-factor authentication unavailable</title></head>
+<body><p>Two-factor authentication is temporarily unavailable. Please try again later.</p></body></html>
+{{end}}
+`))
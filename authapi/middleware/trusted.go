@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/duosecurity/duo_api_golang/authapi"
+)
+
+// defaultDeviceCookie holds the random identifier used as the device
+// fingerprint with a server-side TrustedDeviceStore.
+const defaultDeviceCookie = "duo_device"
+
+// deviceCookieLifetime is how long the device identifier cookie is kept.
+const deviceCookieLifetime = 365 * 24 * time.Hour
+
+// Optional parameter for New, used to remember devices in a server-side
+// store, such as an authapi.FileTrustedDeviceStore. Browsers are identified
+// by a random identifier kept in a cookie. lifetime should match the
+// remembered devices period of the Duo policy.
+func SetTrustedDeviceStore(store authapi.TrustedDeviceStore, lifetime time.Duration) func(*Middleware) {
+	return func(m *Middleware) {
+		m.trustedStore = func(w http.ResponseWriter, r *http.Request) authapi.TrustedDeviceStore {
+			return store
+		}
+		m.fingerprint = deviceID
+		m.trustedLifetime = lifetime
+	}
+}
+
+// Optional parameter for New, used to remember devices in an encrypted
+// cookie. lifetime should match the remembered devices period of the Duo
+// policy.
+func SetTrustedDeviceCookies(store *authapi.CookieTrustedDeviceStore, lifetime time.Duration) func(*Middleware) {
+	return func(m *Middleware) {
+		m.trustedStore = store.Bind
+		m.fingerprint = noFingerprint
+		m.trustedLifetime = lifetime
+	}
+}
+
+// trustedDevices returns the TrustedDevices to use for a request.
+func (m *Middleware) trustedDevices(w http.ResponseWriter, r *http.Request) (*authapi.TrustedDevices, string) {
+	devices := authapi.NewTrustedDevices(m.api, m.trustedStore(w, r), m.trustedLifetime)
+	return devices, m.fingerprint(w, r)
+}
+
+// storeError logs and drops an error of the trusted device store, which
+// leaves the result of the Duo call intact; the device is simply not
+// remembered.
+func (m *Middleware) storeError(err error) error {
+	var storeErr *authapi.TrustedDeviceStoreError
+	if errors.As(err, &storeErr) {
+		m.logf("duo middleware: %v", err)
+		return nil
+	}
+	return err
+}
+
+// deviceID returns the browser's identifier, assigning one if needed.
+func deviceID(w http.ResponseWriter, r *http.Request) string {
+	if cookie, err := r.Cookie(defaultDeviceCookie); err == nil && cookie.Value != "" {
+		return cookie.Value
+	}
+	id, err := randomToken()
+	if err != nil {
+		// Without an identifier the device is simply not remembered.
+		return ""
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     defaultDeviceCookie,
+		Value:    id,
+		Path:     "/",
+		Expires:  time.Now().Add(deviceCookieLifetime),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return id
+}
+
+// noFingerprint is used when the store already lives in the browser.
+func noFingerprint(w http.ResponseWriter, r *http.Request) string {
+	return ""
+}
+
+// plainCookieStore is the default TrustedDeviceStore, keeping the last
+// trusted device token as is in a session cookie. The token is stored with
+// the user it was issued to, and is only sent to Duo for that user.
+type plainCookieStore struct {
+	name string
+	w    http.ResponseWriter
+	r    *http.Request
+}
+
+func (s *plainCookieStore) Get(user, fingerprint string) (string, error) {
+	cookie, err := s.r.Cookie(s.name)
+	if err != nil {
+		return "", nil
+	}
+	owner := url.QueryEscape(user) + ":"
+	if !strings.HasPrefix(cookie.Value, owner) {
+		return "", nil
+	}
+	return cookie.Value[len(owner):], nil
+}
+
+func (s *plainCookieStore) Put(user, fingerprint, token string, expires time.Time) error {
+	http.SetCookie(s.w, &http.Cookie{
+		Name:     s.name,
+		Value:    url.QueryEscape(user) + ":" + token,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   s.r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nil
+}
+
+func (s *plainCookieStore) Delete(user, fingerprint string) error {
+	http.SetCookie(s.w, &http.Cookie{
+		Name:   s.name,
+		Path:   "/",
+		MaxAge: -1,
+	})
+	return nil
+}
@@ -0,0 +1,124 @@
+package authapi
+
+import (
+	"net/url"
+	"sync"
+	"time"
+)
+
+// PreauthManyOptions configures PreauthMany.
+type PreauthManyOptions struct {
+	// Concurrency is the maximum number of Preauth calls in flight.
+	// Zero uses 4.
+	Concurrency int
+	// RateLimit is the maximum number of Preauth calls started per second,
+	// shared by all workers. Zero means no limit besides Concurrency; calls
+	// rejected by Duo with 429 are still retried with backoff.
+	RateLimit float64
+	// Options are added to every Preauth call, for example PreauthIpAddr.
+	Options []func(*url.Values)
+}
+
+// PreauthManyResult is the outcome of the Preauth call for one user.
+type PreauthManyResult struct {
+	Username string
+	// Result is the Preauth response. It is nil if Err is a network or
+	// decoding error.
+	Result *PreauthResult
+	// Err is the error of the call, or a *duoapi.StatError if Duo returned
+	// a FAIL response.
+	Err error
+}
+
+// Default number of concurrent calls made by PreauthMany.
+const defaultPreauthConcurrency = 4
+
+// PreauthMany calls Preauth for each username, with at most
+// opts.Concurrency calls in flight, and returns the results in the order of
+// usernames. A failure for one user does not stop the others.
+//
+// Example:
+//
+//	results := api.PreauthMany(usernames, authapi.PreauthManyOptions{Concurrency: 8, RateLimit: 20})
+//	for _, r := range results {
+//		if r.Err == nil {
+//			fmt.Println(r.Username, r.Result.Response.Result)
+//		}
+//	}
+func (api *AuthApi) PreauthMany(usernames []string, opts PreauthManyOptions) []PreauthManyResult {
+	return api.preauthMany(usernames, opts, newRateLimiter(opts.RateLimit))
+}
+
+func (api *AuthApi) preauthMany(usernames []string, opts PreauthManyOptions, limiter *rateLimiter) []PreauthManyResult {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultPreauthConcurrency
+	}
+	if concurrency > len(usernames) {
+		concurrency = len(usernames)
+	}
+	results := make([]PreauthManyResult, len(usernames))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				limiter.wait()
+				results[i] = api.preauthOne(usernames[i], opts.Options)
+			}
+		}()
+	}
+	for i := range usernames {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return results
+}
+
+func (api *AuthApi) preauthOne(username string, options []func(*url.Values)) PreauthManyResult {
+	all := append([]func(*url.Values){PreauthUsername(username)}, options...)
+	result, err := api.Preauth(all...)
+	if err == nil {
+		err = result.Err()
+	}
+	return PreauthManyResult{Username: username, Result: result, Err: err}
+}
+
+// rateLimiter spaces out calls shared between goroutines.
+type rateLimiter struct {
+	interval time.Duration
+	now      func() time.Time
+	sleep    func(time.Duration)
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// newRateLimiter returns a limiter allowing perSecond calls per second, or
+// no limit if perSecond is not positive.
+func newRateLimiter(perSecond float64) *rateLimiter {
+	l := &rateLimiter{now: time.Now, sleep: time.Sleep}
+	if perSecond > 0 {
+		l.interval = time.Duration(float64(time.Second) / perSecond)
+	}
+	return l
+}
+
+// wait blocks until the caller may make its call.
+func (l *rateLimiter) wait() {
+	if l.interval == 0 {
+		return
+	}
+	l.mu.Lock()
+	now := l.now()
+	start := l.next
+	if start.Before(now) {
+		start = now
+	}
+	l.next = start.Add(l.interval)
+	l.mu.Unlock()
+	l.sleep(start.Sub(now))
+}
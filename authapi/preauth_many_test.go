@@ -0,0 +1,155 @@
+package authapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	duoapi "github.com/duosecurity/duo_api_golang"
+)
+
+// preauthManyServer answers preauth for each user after a delay that makes
+// responses arrive out of order, and records the peak number of calls in
+// flight.
+type preauthManyServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	inFlight int
+	peak     int
+}
+
+// maxInFlight returns the peak number of calls in flight.
+func (s *preauthManyServer) maxInFlight() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.peak
+}
+
+func newPreauthManyServer(t *testing.T) *preauthManyServer {
+	s := &preauthManyServer{}
+	s.Server = httptest.NewTLSServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				params, err := getBodyParams(r)
+				if err != nil {
+					t.Error("Failed to retrieve body parameters")
+				}
+				if params.Get("ipaddr") != "192.0.2.10" {
+					t.Error("Expected common options in every call")
+				}
+				s.mu.Lock()
+				s.inFlight++
+				if s.inFlight > s.peak {
+					s.peak = s.inFlight
+				}
+				s.mu.Unlock()
+
+				username := params.Get("username")
+				delay := time.Duration(len(username)%3) * 5 * time.Millisecond
+				time.Sleep(delay)
+
+				s.mu.Lock()
+				s.inFlight--
+				s.mu.Unlock()
+				switch {
+				case username == "nobody":
+					w.WriteHeader(400)
+					fmt.Fprintln(w, `{"stat": "FAIL", "code": 40002, "message": "Invalid request parameters", "message_detail": "username"}`)
+				case strings.HasPrefix(username, "new"):
+					fmt.Fprintln(w, `{"stat": "OK", "response": {"result": "enroll", "status_msg": "Enroll an authentication device to proceed"}}`)
+				default:
+					fmt.Fprintln(w, `{"stat": "OK", "response": {"result": "auth", "status_msg": "Account is active"}}`)
+				}
+			}))
+	return s
+}
+
+func TestPreauthMany(t *testing.T) {
+	ts := newPreauthManyServer(t)
+	defer ts.Close()
+	duo := buildAuthApi(ts.URL, nil)
+
+	var usernames []string
+	for i := 0; i < 20; i++ {
+		usernames = append(usernames, fmt.Sprintf("user%s", strings.Repeat("x", i)))
+	}
+	usernames = append(usernames, "nobody", "newhire")
+
+	results := duo.PreauthMany(usernames, PreauthManyOptions{
+		Concurrency: 3,
+		Options:     []func(*url.Values){PreauthIpAddr("192.0.2.10")},
+	})
+	if len(results) != len(usernames) {
+		t.Fatalf("Expected %d results, got %d", len(usernames), len(results))
+	}
+	for i, r := range results[:20] {
+		if r.Username != usernames[i] || r.Err != nil || r.Result.Response.Result != "auth" {
+			t.Errorf("Unexpected result %d: %+v", i, r)
+		}
+	}
+	if _, ok := results[20].Err.(*duoapi.StatError); !ok || results[20].Username != "nobody" {
+		t.Errorf("Expected a StatError for nobody, got %+v", results[20])
+	}
+	if results[21].Err != nil || results[21].Result.Response.Result != "enroll" {
+		t.Errorf("Expected enroll for newhire, got %+v", results[21])
+	}
+	if peak := ts.maxInFlight(); peak > 3 {
+		t.Errorf("Expected at most 3 calls in flight, got %d", peak)
+	}
+}
+
+// fakeSleep records the delays requested from a rateLimiter without waiting,
+// while its clock stands still.
+type fakeSleep struct {
+	mu     sync.Mutex
+	delays []time.Duration
+}
+
+func (f *fakeSleep) sleep(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.delays = append(f.delays, d)
+}
+
+func TestPreauthManyRateLimit(t *testing.T) {
+	ts := newPreauthManyServer(t)
+	defer ts.Close()
+	duo := buildAuthApi(ts.URL, nil)
+
+	start := time.Now()
+	fake := &fakeSleep{}
+	limiter := newRateLimiter(50)
+	limiter.now = func() time.Time { return start }
+	limiter.sleep = fake.sleep
+
+	results := duo.preauthMany([]string{"a", "b", "c", "d", "e"}, PreauthManyOptions{
+		Concurrency: 5,
+		RateLimit:   50,
+		Options:     []func(*url.Values){PreauthIpAddr("192.0.2.10")},
+	}, limiter)
+	for _, r := range results {
+		if r.Err != nil {
+			t.Errorf("Unexpected error for %s: %v", r.Username, r.Err)
+		}
+	}
+	// At 50 per second, calls start 20ms apart.
+	sort.Slice(fake.delays, func(i, j int) bool { return fake.delays[i] < fake.delays[j] })
+	for i, delay := range fake.delays {
+		if expected := time.Duration(i) * 20 * time.Millisecond; delay != expected {
+			t.Errorf("Expected call %d to wait %s, got %s", i, expected, delay)
+		}
+	}
+	if len(fake.delays) != 5 {
+		t.Errorf("Expected 5 calls to be limited, got %d", len(fake.delays))
+	}
+
+	if results := duo.PreauthMany(nil, PreauthManyOptions{}); len(results) != 0 {
+		t.Errorf("Expected no results, got %v", results)
+	}
+}
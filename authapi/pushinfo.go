@@ -0,0 +1,103 @@
+package authapi
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// MaxPushInfoLength is the maximum length, in bytes, of the URL-encoded
+// pushinfo parameter accepted by Duo.
+const MaxPushInfoLength = 20000
+
+// PushInfoField is a single key/value pair displayed in a Duo Push.
+type PushInfoField struct {
+	Key   string
+	Value string
+}
+
+// PushInfo holds the key/value pairs displayed to the user in a Duo Push.
+// Pairs are shown in the order they were added.
+//
+// Example:
+//
+//	info := authapi.PushInfo{}.Add("from", "login.example.com").Add("domain", "example.com")
+//	api.Auth("push", authapi.AuthUsername("jsmith"), authapi.AuthDevice("auto"), authapi.AuthPushInfo(info))
+type PushInfo []PushInfoField
+
+// Add returns a copy of p with the key/value pair appended. p itself is not
+// modified, so a base PushInfo can be extended several times.
+func (p PushInfo) Add(key, value string) PushInfo {
+	info := make(PushInfo, len(p), len(p)+1)
+	copy(info, p)
+	return append(info, PushInfoField{key, value})
+}
+
+// Get returns the value of the first pair with the given key, or "".
+func (p PushInfo) Get(key string) string {
+	for _, field := range p {
+		if field.Key == key {
+			return field.Value
+		}
+	}
+	return ""
+}
+
+// Encode returns the pairs in URL-encoded form, in order, as expected by the
+// pushinfo parameter. Encode does not check Duo's limits; use Validate.
+func (p PushInfo) Encode() string {
+	pairs := make([]string, 0, len(p))
+	for _, field := range p {
+		pairs = append(pairs, url.QueryEscape(field.Key)+"="+url.QueryEscape(field.Value))
+	}
+	return strings.Join(pairs, "&")
+}
+
+// Validate reports whether Duo will accept the pairs: every key must be
+// non-empty and the encoded form must not exceed MaxPushInfoLength bytes.
+func (p PushInfo) Validate() error {
+	for i, field := range p {
+		if field.Key == "" {
+			return fmt.Errorf("pushinfo field %d has an empty key", i)
+		}
+	}
+	if length := len(p.Encode()); length > MaxPushInfoLength {
+		return fmt.Errorf("pushinfo is %d bytes when encoded, more than the maximum of %d", length, MaxPushInfoLength)
+	}
+	return nil
+}
+
+// ParsePushInfo parses a URL-encoded pushinfo string, keeping the order of the pairs.
+func ParsePushInfo(encoded string) (PushInfo, error) {
+	var p PushInfo
+	if encoded == "" {
+		return p, nil
+	}
+	for _, pair := range strings.Split(encoded, "&") {
+		i := strings.Index(pair, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("pushinfo pair %q has no value", pair)
+		}
+		key, err := url.QueryUnescape(pair[:i])
+		if err != nil {
+			return nil, err
+		}
+		if key == "" {
+			return nil, errors.New("pushinfo contains a pair with an empty key")
+		}
+		value, err := url.QueryUnescape(pair[i+1:])
+		if err != nil {
+			return nil, err
+		}
+		p = p.Add(key, value)
+	}
+	return p, nil
+}
+
+// Optional parameter for the Auth method, used with the 'push' and 'auto'
+// factors. It sets the pushinfo parameter from structured pairs, which should
+// be checked with PushInfo.Validate first.
+func AuthPushInfo(pushinfo PushInfo) func(*url.Values) {
+	return AuthPushinfo(pushinfo.Encode())
+}
@@ -0,0 +1,92 @@
+package authapi
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Test that pairs are encoded in order and parse back to the same pairs.
+func TestPushInfoRoundTrip(t *testing.T) {
+	info := PushInfo{}.
+		Add("from", "login.example.com").
+		Add("amount", "$1,000 & change").
+		Add("café", "ünïcode = fine")
+
+	encoded := info.Encode()
+	if expected := "from=login.example.com&amount=%241%2C000+%26+change&caf%C3%A9=%C3%BCn%C3%AFcode+%3D+fine"; encoded != expected {
+		t.Errorf("Expected %q, got %q", expected, encoded)
+	}
+
+	parsed, err := ParsePushInfo(encoded)
+	if err != nil {
+		t.Fatalf("Failed to parse pushinfo: %v", err)
+	}
+	if !reflect.DeepEqual(parsed, info) {
+		t.Errorf("Expected %v after round trip, got %v", info, parsed)
+	}
+	if amount := parsed.Get("amount"); amount != "$1,000 & change" {
+		t.Errorf("Unexpected amount: %q", amount)
+	}
+	if missing := parsed.Get("missing"); missing != "" {
+		t.Errorf("Expected empty value for missing key, got %q", missing)
+	}
+}
+
+// Test that extending the same PushInfo twice does not share pairs.
+func TestPushInfoAddCopies(t *testing.T) {
+	base := PushInfo{}.Add("a", "1")
+	x := base.Add("b", "2")
+	y := base.Add("c", "3")
+	if x.Get("b") != "2" || x.Get("c") != "" {
+		t.Errorf("Unexpected pairs in x: %v", x)
+	}
+	if y.Get("c") != "3" || y.Get("b") != "" {
+		t.Errorf("Unexpected pairs in y: %v", y)
+	}
+
+	spare := make(PushInfo, 1, 4)
+	spare[0] = PushInfoField{"a", "1"}
+	x = spare.Add("b", "2")
+	y = spare.Add("c", "3")
+	if x[1].Key != "b" || y[1].Key != "c" {
+		t.Errorf("Expected separate pairs, got %v and %v", x, y)
+	}
+	if len(spare) != 1 {
+		t.Errorf("Expected base to be unchanged, got %v", spare)
+	}
+}
+
+func TestParsePushInfoInvalid(t *testing.T) {
+	for _, encoded := range []string{"novalue", "=value", "key=%zz"} {
+		if _, err := ParsePushInfo(encoded); err == nil {
+			t.Errorf("Expected error parsing %q", encoded)
+		}
+	}
+	if info, err := ParsePushInfo(""); err != nil || len(info) != 0 {
+		t.Errorf("Expected no pairs for empty pushinfo, got %v, %v", info, err)
+	}
+}
+
+func TestPushInfoValidate(t *testing.T) {
+	if err := (PushInfo{}).Add("from", "mysite.com").Validate(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := (PushInfo{}).Add("", "mysite.com").Validate(); err == nil {
+		t.Error("Expected error for an empty key")
+	}
+	// Escaping counts towards the limit.
+	long := PushInfo{}.Add("k", strings.Repeat("&", MaxPushInfoLength/3+1))
+	if err := long.Validate(); err == nil {
+		t.Error("Expected error for pushinfo longer than the maximum once encoded")
+	}
+}
+
+func TestAuthPushInfo(t *testing.T) {
+	params := url.Values{}
+	AuthPushInfo(PushInfo{}.Add("b", "2").Add("a", "1"))(&params)
+	if pushinfo := params.Get("pushinfo"); pushinfo != "b=2&a=1" {
+		t.Errorf("Expected pairs in insertion order, got %q", pushinfo)
+	}
+}
@@ -0,0 +1,491 @@
+package authapi
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// TrustedDeviceStore keeps the trusted device tokens returned by Auth and
+// AuthStatus, so that later Preauth calls for the same user on the same device
+// can skip the second factor while Duo's remembered devices policy allows it.
+//
+// Tokens are keyed by user and by a device fingerprint chosen by the
+// application, such as a random identifier kept in a long-lived cookie.
+type TrustedDeviceStore interface {
+	// Get returns the token for user on the device, or "" if there is none or
+	// it has expired.
+	Get(user, fingerprint string) (string, error)
+	// Put saves a token, replacing any previous one. A zero expires means the
+	// token is kept until it is deleted or rejected by Duo.
+	Put(user, fingerprint, token string, expires time.Time) error
+	// Delete forgets the token for user on the device, if any.
+	Delete(user, fingerprint string) error
+}
+
+// trustedDevice is a stored token and its expiry.
+type trustedDevice struct {
+	Token   string
+	Expires time.Time
+}
+
+func (d trustedDevice) expired(now time.Time) bool {
+	return !d.Expires.IsZero() && !now.Before(d.Expires)
+}
+
+func trustedDeviceKey(user, fingerprint string) string {
+	return user + "\x00" + fingerprint
+}
+
+// MemoryTrustedDeviceStore is a TrustedDeviceStore keeping tokens in process
+// memory. Tokens are lost when the process exits.
+type MemoryTrustedDeviceStore struct {
+	now func() time.Time
+
+	mu      sync.Mutex
+	devices map[string]trustedDevice
+}
+
+// NewMemoryTrustedDeviceStore returns an empty MemoryTrustedDeviceStore.
+func NewMemoryTrustedDeviceStore() *MemoryTrustedDeviceStore {
+	return &MemoryTrustedDeviceStore{
+		now:     time.Now,
+		devices: make(map[string]trustedDevice),
+	}
+}
+
+// Get implements TrustedDeviceStore.
+func (s *MemoryTrustedDeviceStore) Get(user, fingerprint string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := trustedDeviceKey(user, fingerprint)
+	device, ok := s.devices[key]
+	if !ok {
+		return "", nil
+	}
+	if device.expired(s.now()) {
+		delete(s.devices, key)
+		return "", nil
+	}
+	return device.Token, nil
+}
+
+// Put implements TrustedDeviceStore.
+func (s *MemoryTrustedDeviceStore) Put(user, fingerprint, token string, expires time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.devices[trustedDeviceKey(user, fingerprint)] = trustedDevice{token, expires}
+	return nil
+}
+
+// Delete implements TrustedDeviceStore.
+func (s *MemoryTrustedDeviceStore) Delete(user, fingerprint string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.devices, trustedDeviceKey(user, fingerprint))
+	return nil
+}
+
+// FileTrustedDeviceStore is a TrustedDeviceStore keeping tokens in a JSON
+// file, so that they survive restarts. The file is only meant to be shared
+// by the goroutines of a single process.
+type FileTrustedDeviceStore struct {
+	path string
+	now  func() time.Time
+
+	mu sync.Mutex
+}
+
+// NewFileTrustedDeviceStore returns a FileTrustedDeviceStore using the file at
+// path, which is created on the first Put. The file holds credentials and is
+// written with mode 0600.
+func NewFileTrustedDeviceStore(path string) *FileTrustedDeviceStore {
+	return &FileTrustedDeviceStore{path: path, now: time.Now}
+}
+
+// Get implements TrustedDeviceStore.
+func (s *FileTrustedDeviceStore) Get(user, fingerprint string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	devices, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	device, ok := devices[trustedDeviceKey(user, fingerprint)]
+	if !ok || device.expired(s.now()) {
+		return "", nil
+	}
+	return device.Token, nil
+}
+
+// Put implements TrustedDeviceStore.
+func (s *FileTrustedDeviceStore) Put(user, fingerprint, token string, expires time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	devices, err := s.load()
+	if err != nil {
+		return err
+	}
+	devices[trustedDeviceKey(user, fingerprint)] = trustedDevice{token, expires}
+	return s.store(devices)
+}
+
+// Delete implements TrustedDeviceStore.
+func (s *FileTrustedDeviceStore) Delete(user, fingerprint string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	devices, err := s.load()
+	if err != nil {
+		return err
+	}
+	key := trustedDeviceKey(user, fingerprint)
+	if _, ok := devices[key]; !ok {
+		return nil
+	}
+	delete(devices, key)
+	return s.store(devices)
+}
+
+// load reads the file. The caller must hold s.mu.
+func (s *FileTrustedDeviceStore) load() (map[string]trustedDevice, error) {
+	devices := make(map[string]trustedDevice)
+	data, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return devices, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &devices); err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
+// store drops expired tokens and replaces the file. The caller must hold s.mu.
+func (s *FileTrustedDeviceStore) store(devices map[string]trustedDevice) error {
+	now := s.now()
+	for key, device := range devices {
+		if device.expired(now) {
+			delete(devices, key)
+		}
+	}
+	data, err := json.Marshal(devices)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path))
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// defaultTrustedDeviceCookie is the cookie used by CookieTrustedDeviceStore.
+const defaultTrustedDeviceCookie = "duo_trusted_devices"
+
+// CookieTrustedDeviceStore keeps tokens in an encrypted cookie in the user's
+// browser, so that the server needs no storage. The cookie is encrypted and
+// authenticated with AES-GCM; a cookie that cannot be decrypted, for example
+// after the key was changed, is treated as empty.
+//
+// Since the tokens live in the request, use Bind to obtain the
+// TrustedDeviceStore for each request.
+type CookieTrustedDeviceStore struct {
+	aead       cipher.AEAD
+	cookieName string
+	now        func() time.Time
+}
+
+// Optional parameter for NewCookieTrustedDeviceStore, used to change the name
+// of the cookie.
+func SetTrustedDeviceCookieName(name string) func(*CookieTrustedDeviceStore) {
+	return func(s *CookieTrustedDeviceStore) {
+		s.cookieName = name
+	}
+}
+
+// NewCookieTrustedDeviceStore returns a CookieTrustedDeviceStore encrypting
+// with key, which must be 16, 24 or 32 random bytes kept secret by the server.
+func NewCookieTrustedDeviceStore(key []byte,
+	options ...func(*CookieTrustedDeviceStore)) (*CookieTrustedDeviceStore, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	s := &CookieTrustedDeviceStore{
+		aead:       aead,
+		cookieName: defaultTrustedDeviceCookie,
+		now:        time.Now,
+	}
+	for _, o := range options {
+		o(s)
+	}
+	return s, nil
+}
+
+// Bind returns the TrustedDeviceStore for a single request: Get reads the
+// cookie sent with r, and Put and Delete set the cookie on w. The returned
+// store must not be used after the response has been written.
+func (s *CookieTrustedDeviceStore) Bind(w http.ResponseWriter, r *http.Request) TrustedDeviceStore {
+	return &boundCookieStore{store: s, w: w, r: r}
+}
+
+// boundCookieStore is the TrustedDeviceStore returned by Bind.
+type boundCookieStore struct {
+	store   *CookieTrustedDeviceStore
+	w       http.ResponseWriter
+	r       *http.Request
+	devices map[string]trustedDevice
+}
+
+// Get implements TrustedDeviceStore.
+func (b *boundCookieStore) Get(user, fingerprint string) (string, error) {
+	device, ok := b.load()[trustedDeviceKey(user, fingerprint)]
+	if !ok || device.expired(b.store.now()) {
+		return "", nil
+	}
+	return device.Token, nil
+}
+
+// Put implements TrustedDeviceStore.
+func (b *boundCookieStore) Put(user, fingerprint, token string, expires time.Time) error {
+	b.load()[trustedDeviceKey(user, fingerprint)] = trustedDevice{token, expires}
+	return b.save()
+}
+
+// Delete implements TrustedDeviceStore.
+func (b *boundCookieStore) Delete(user, fingerprint string) error {
+	key := trustedDeviceKey(user, fingerprint)
+	if _, ok := b.load()[key]; !ok {
+		return nil
+	}
+	delete(b.devices, key)
+	return b.save()
+}
+
+// load decrypts the request's cookie the first time it is needed.
+func (b *boundCookieStore) load() map[string]trustedDevice {
+	if b.devices != nil {
+		return b.devices
+	}
+	b.devices = make(map[string]trustedDevice)
+	cookie, err := b.r.Cookie(b.store.cookieName)
+	if err != nil {
+		return b.devices
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(cookie.Value)
+	nonceSize := b.store.aead.NonceSize()
+	if err != nil || len(sealed) < nonceSize {
+		return b.devices
+	}
+	data, err := b.store.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], []byte(b.store.cookieName))
+	if err != nil {
+		return b.devices
+	}
+	devices := make(map[string]trustedDevice)
+	if json.Unmarshal(data, &devices) == nil {
+		b.devices = devices
+	}
+	return b.devices
+}
+
+// save drops expired tokens and sets the encrypted cookie, which lasts as long
+// as the longest-lived token.
+func (b *boundCookieStore) save() error {
+	now := b.store.now()
+	var expires time.Time
+	session := false
+	for key, device := range b.devices {
+		switch {
+		case device.expired(now):
+			delete(b.devices, key)
+		case device.Expires.IsZero():
+			session = true
+		case device.Expires.After(expires):
+			expires = device.Expires
+		}
+	}
+
+	cookie := &http.Cookie{
+		Name:     b.store.cookieName,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   b.r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	}
+	if len(b.devices) == 0 {
+		cookie.MaxAge = -1
+		http.SetCookie(b.w, cookie)
+		return nil
+	}
+	if !session {
+		cookie.Expires = expires
+	}
+
+	data, err := json.Marshal(b.devices)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, b.store.aead.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	sealed := b.store.aead.Seal(nonce, nonce, data, []byte(b.store.cookieName))
+	cookie.Value = base64.RawURLEncoding.EncodeToString(sealed)
+	http.SetCookie(b.w, cookie)
+	return nil
+}
+
+// TrustedDevices makes Preauth, Auth and AuthStatus calls that remember
+// devices: the trusted device token returned by a successful authentication
+// is saved in a TrustedDeviceStore, and sent with the next Preauth for the
+// same user and device so that Duo can allow it without a second factor.
+//
+// Users are identified in the store by their UserId if set, otherwise by
+// their Username; use one or the other consistently.
+type TrustedDevices struct {
+	api      *AuthApi
+	store    TrustedDeviceStore
+	lifetime time.Duration
+	now      func() time.Time
+}
+
+// NewTrustedDevices returns a TrustedDevices using api and store.
+// lifetime is how long tokens are kept, and should match the remembered
+// devices period of the Duo policy; zero keeps them until Duo rejects them.
+//
+// Example: authapi.NewTrustedDevices(api, authapi.NewMemoryTrustedDeviceStore(), 7*24*time.Hour)
+func NewTrustedDevices(api *AuthApi, store TrustedDeviceStore, lifetime time.Duration) *TrustedDevices {
+	return &TrustedDevices{api: api, store: store, lifetime: lifetime, now: time.Now}
+}
+
+// TrustedDeviceStoreError reports that the TrustedDeviceStore of a
+// TrustedDevices failed. It is returned alongside the result of the Duo call,
+// which completed normally: a failure to read a token only means that the
+// device is not remembered, and a failure to save one only means that it
+// will not be remembered next time.
+type TrustedDeviceStoreError struct {
+	// Op is the store method that failed: "Get", "Put" or "Delete".
+	Op  string
+	Err error
+}
+
+func (e *TrustedDeviceStoreError) Error() string {
+	return "trusted device store: " + e.Op + ": " + e.Err.Error()
+}
+
+// Unwrap returns the error of the store.
+func (e *TrustedDeviceStoreError) Unwrap() error {
+	return e.Err
+}
+
+// Preauth calls Duo's Preauth method for user, sending the stored token for
+// the device if there is one. A token that no longer lets the user skip the
+// second factor is deleted from the store.
+// options are other Preauth parameters, such as PreauthIpAddr.
+//
+// If the store fails, Preauth is still called, without a token when it could
+// not be read, and the result is returned with a *TrustedDeviceStoreError.
+func (t *TrustedDevices) Preauth(user AuthUser, fingerprint string,
+	options ...func(*url.Values)) (*PreauthResult, error) {
+	var storeErr error
+	token, err := t.store.Get(user.key(), fingerprint)
+	if err != nil {
+		token, storeErr = "", &TrustedDeviceStoreError{"Get", err}
+	}
+	options = append(options, user.preauthOption())
+	if token != "" {
+		options = append(options, PreauthTrustedToken(token))
+	}
+
+	result, err := t.api.Preauth(options...)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" && result.Stat == "OK" && result.Response.Result != "allow" {
+		if err = t.store.Delete(user.key(), fingerprint); err != nil {
+			storeErr = &TrustedDeviceStoreError{"Delete", err}
+		}
+	}
+	return result, storeErr
+}
+
+// Auth calls Duo's Auth method for user, and stores the trusted device token
+// returned when the authentication is allowed.
+// options are other Auth parameters, such as AuthDevice.
+//
+// If the token cannot be stored, the result is still returned, with a
+// *TrustedDeviceStoreError.
+func (t *TrustedDevices) Auth(user AuthUser, fingerprint, factor string,
+	options ...func(*url.Values)) (*AuthResult, error) {
+	options = append(options, user.options()...)
+	result, err := t.api.Auth(factor, options...)
+	if err != nil {
+		return nil, err
+	}
+	if result.Stat == "OK" && result.Response.Result == "allow" {
+		return result, t.remember(user, fingerprint, result.Response.Trusted_Device_Token)
+	}
+	return result, nil
+}
+
+// AuthStatus calls Duo's AuthStatus method for an asynchronous Auth made for
+// user, and stores the trusted device token returned when the authentication
+// is allowed. Like Auth, it returns the result with a
+// *TrustedDeviceStoreError if the token cannot be stored.
+func (t *TrustedDevices) AuthStatus(user AuthUser, fingerprint, txid string) (*AuthStatusResult, error) {
+	result, err := t.api.AuthStatus(txid)
+	if err != nil {
+		return nil, err
+	}
+	if result.Stat == "OK" && result.Response.Result == "allow" {
+		return result, t.remember(user, fingerprint, result.Response.Trusted_Device_Token)
+	}
+	return result, nil
+}
+
+func (t *TrustedDevices) remember(user AuthUser, fingerprint, token string) error {
+	if token == "" {
+		return nil
+	}
+	var expires time.Time
+	if t.lifetime > 0 {
+		expires = t.now().Add(t.lifetime)
+	}
+	if err := t.store.Put(user.key(), fingerprint, token, expires); err != nil {
+		return &TrustedDeviceStoreError{"Put", err}
+	}
+	return nil
+}
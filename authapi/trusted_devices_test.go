@@ -0,0 +1,253 @@
+package authapi
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMemoryTrustedDeviceStore(t *testing.T) {
+	now := time.Unix(1357020061, 0)
+	store := NewMemoryTrustedDeviceStore()
+	store.now = func() time.Time { return now }
+
+	store.Put("jsmith", "laptop", "l33t", now.Add(time.Hour))
+	store.Put("jsmith", "phone", "f00d", time.Time{})
+	if token, _ := store.Get("jsmith", "laptop"); token != "l33t" {
+		t.Errorf("Expected l33t, got %q", token)
+	}
+	if token, _ := store.Get("jdoe", "laptop"); token != "" {
+		t.Errorf("Expected no token for another user, got %q", token)
+	}
+
+	now = now.Add(time.Hour)
+	if token, _ := store.Get("jsmith", "laptop"); token != "" {
+		t.Errorf("Expected expired token, got %q", token)
+	}
+	if token, _ := store.Get("jsmith", "phone"); token != "f00d" {
+		t.Errorf("Expected token without expiry, got %q", token)
+	}
+
+	store.Delete("jsmith", "phone")
+	if token, _ := store.Get("jsmith", "phone"); token != "" {
+		t.Errorf("Expected deleted token, got %q", token)
+	}
+}
+
+func TestFileTrustedDeviceStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "trusted")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "trusted.json")
+
+	now := time.Unix(1357020061, 0)
+	store := NewFileTrustedDeviceStore(path)
+	store.now = func() time.Time { return now }
+	if token, err := store.Get("jsmith", "laptop"); err != nil || token != "" {
+		t.Errorf("Expected no token before the file exists, got %q, %v", token, err)
+	}
+	if err := store.Put("jsmith", "laptop", "l33t", now.Add(time.Hour)); err != nil {
+		t.Fatal("Failed to put token: " + err.Error())
+	}
+	store.Put("jsmith", "phone", "f00d", now.Add(time.Minute))
+
+	// A new store, as after a restart, reads the same file.
+	reopened := NewFileTrustedDeviceStore(path)
+	reopened.now = func() time.Time { return now.Add(30 * time.Minute) }
+	if token, err := reopened.Get("jsmith", "laptop"); err != nil || token != "l33t" {
+		t.Errorf("Expected l33t, got %q, %v", token, err)
+	}
+	if token, _ := reopened.Get("jsmith", "phone"); token != "" {
+		t.Errorf("Expected expired token, got %q", token)
+	}
+
+	if err := reopened.Delete("jsmith", "laptop"); err != nil {
+		t.Fatal("Failed to delete token: " + err.Error())
+	}
+	if token, _ := store.Get("jsmith", "laptop"); token != "" {
+		t.Errorf("Expected deleted token, got %q", token)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected file with mode 0600, got %v", info)
+	}
+}
+
+func TestCookieTrustedDeviceStore(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	store, err := NewCookieTrustedDeviceStore(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewCookieTrustedDeviceStore([]byte("short")); err == nil {
+		t.Error("Expected error for an invalid key")
+	}
+
+	rec := httptest.NewRecorder()
+	bound := store.Bind(rec, httptest.NewRequest("GET", "/", nil))
+	expires := time.Now().Add(time.Hour)
+	if err := bound.Put("jsmith", "", "l33t", expires); err != nil {
+		t.Fatal("Failed to put token: " + err.Error())
+	}
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != defaultTrustedDeviceCookie || !cookies[0].HttpOnly {
+		t.Fatalf("Unexpected cookies: %v", cookies)
+	}
+	if cookies[0].Value == "" || cookies[0].Expires.Unix() != expires.Unix() {
+		t.Errorf("Unexpected cookie: %v", cookies[0])
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.AddCookie(cookies[0])
+	if token, _ := store.Bind(httptest.NewRecorder(), req).Get("jsmith", ""); token != "l33t" {
+		t.Errorf("Expected l33t, got %q", token)
+	}
+	if token, _ := store.Bind(httptest.NewRecorder(), req).Get("jdoe", ""); token != "" {
+		t.Errorf("Expected no token for another user, got %q", token)
+	}
+
+	// A cookie encrypted with another key is ignored.
+	other, _ := NewCookieTrustedDeviceStore([]byte("fedcba9876543210fedcba9876543210"))
+	if token, err := other.Bind(httptest.NewRecorder(), req).Get("jsmith", ""); err != nil || token != "" {
+		t.Errorf("Expected no token with another key, got %q, %v", token, err)
+	}
+
+	// Deleting the last token clears the cookie.
+	rec = httptest.NewRecorder()
+	store.Bind(rec, req).Delete("jsmith", "")
+	if cookies := rec.Result().Cookies(); len(cookies) != 1 || cookies[0].MaxAge >= 0 {
+		t.Errorf("Expected cookie to be cleared, got %v", cookies)
+	}
+}
+
+// trustedServer answers preauth with "allow" only when sent token, and
+// allows every auth, returning token.
+func trustedServer(t *testing.T, token string, preauths *[]string) *httptest.Server {
+	return httptest.NewTLSServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				params, err := getBodyParams(r)
+				if err != nil {
+					t.Error("Failed to read parameters: " + err.Error())
+				}
+				switch r.URL.Path {
+				case "/auth/v2/preauth":
+					sent := params.Get("trusted_device_token")
+					*preauths = append(*preauths, sent)
+					if sent == token {
+						fmt.Fprintln(w, `{"stat": "OK", "response": {"result": "allow", "status_msg": "Account is active"}}`)
+					} else {
+						fmt.Fprintln(w, `{"stat": "OK", "response": {"result": "auth", "status_msg": "Account is active"}}`)
+					}
+				case "/auth/v2/auth":
+					if params.Get("username") != "jsmith" {
+						t.Error("Unexpected username: " + params.Get("username"))
+					}
+					fmt.Fprintf(w, `{"stat": "OK", "response": {"result": "allow", "status": "allow", "trusted_device_token": %q}}`, token)
+				}
+			}))
+}
+
+func TestTrustedDevices(t *testing.T) {
+	var preauths []string
+	ts := trustedServer(t, "l33t", &preauths)
+	defer ts.Close()
+
+	store := NewMemoryTrustedDeviceStore()
+	devices := NewTrustedDevices(buildAuthApi(ts.URL, nil), store, 24*time.Hour)
+	user := AuthUser{Username: "jsmith"}
+
+	result, err := devices.Preauth(user, "laptop")
+	if err != nil || result.Response.Result != "auth" {
+		t.Fatalf("Expected auth, got %v, %v", result, err)
+	}
+	if _, err = devices.Auth(user, "laptop", "push", AuthDevice("auto")); err != nil {
+		t.Fatal("Failed to authenticate: " + err.Error())
+	}
+	if token, _ := store.Get("jsmith", "laptop"); token != "l33t" {
+		t.Errorf("Expected stored token, got %q", token)
+	}
+
+	result, err = devices.Preauth(user, "laptop")
+	if err != nil || result.Response.Result != "allow" {
+		t.Errorf("Expected remembered device to be allowed, got %v, %v", result, err)
+	}
+	result, err = devices.Preauth(user, "phone")
+	if err != nil || result.Response.Result != "auth" {
+		t.Errorf("Expected other device to authenticate, got %v, %v", result, err)
+	}
+	if len(preauths) != 3 || preauths[0] != "" || preauths[1] != "l33t" || preauths[2] != "" {
+		t.Errorf("Unexpected tokens sent to preauth: %q", preauths)
+	}
+}
+
+// Test that a token no longer accepted by Duo is forgotten.
+func TestTrustedDevicesRejected(t *testing.T) {
+	var preauths []string
+	ts := trustedServer(t, "f00d", &preauths)
+	defer ts.Close()
+
+	store := NewMemoryTrustedDeviceStore()
+	store.Put("jsmith", "laptop", "l33t", time.Time{})
+	devices := NewTrustedDevices(buildAuthApi(ts.URL, nil), store, 0)
+
+	result, err := devices.Preauth(AuthUser{Username: "jsmith"}, "laptop")
+	if err != nil || result.Response.Result != "auth" {
+		t.Fatalf("Expected auth, got %v, %v", result, err)
+	}
+	if token, _ := store.Get("jsmith", "laptop"); token != "" {
+		t.Errorf("Expected rejected token to be deleted, got %q", token)
+	}
+}
+
+// failingStore is a TrustedDeviceStore whose every call fails.
+type failingStore struct{}
+
+func (failingStore) Get(user, fingerprint string) (string, error) {
+	return "", errors.New("disk full")
+}
+
+func (failingStore) Put(user, fingerprint, token string, expires time.Time) error {
+	return errors.New("disk full")
+}
+
+func (failingStore) Delete(user, fingerprint string) error {
+	return errors.New("disk full")
+}
+
+// Test that store failures are reported without losing Duo's result.
+func TestTrustedDevicesStoreError(t *testing.T) {
+	var preauths []string
+	ts := trustedServer(t, "l33t", &preauths)
+	defer ts.Close()
+
+	devices := NewTrustedDevices(buildAuthApi(ts.URL, nil), failingStore{}, 24*time.Hour)
+	user := AuthUser{Username: "jsmith"}
+
+	var storeErr *TrustedDeviceStoreError
+	preauth, err := devices.Preauth(user, "laptop")
+	if !errors.As(err, &storeErr) || storeErr.Op != "Get" {
+		t.Errorf("Expected a store error from Get, got %v", err)
+	}
+	if preauth == nil || preauth.Response.Result != "auth" {
+		t.Errorf("Expected preauth result despite the store error, got %v", preauth)
+	}
+	if len(preauths) != 1 || preauths[0] != "" {
+		t.Errorf("Expected preauth without a token, got %q", preauths)
+	}
+
+	auth, err := devices.Auth(user, "laptop", "push", AuthDevice("auto"))
+	if !errors.As(err, &storeErr) || storeErr.Op != "Put" {
+		t.Errorf("Expected a store error from Put, got %v", err)
+	}
+	if auth == nil || auth.Response.Result != "allow" {
+		t.Errorf("Expected the allowed authentication to be returned, got %v", auth)
+	}
+}
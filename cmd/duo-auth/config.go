@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// config holds the settings read from the [duo] section of the INI file
+// shared with login_duo and pam_duo.
+type config struct {
+	ikey     string
+	skey     string
+	host     string
+	failmode string
+	autopush bool
+	prompts  int
+}
+
+// Defaults match login_duo.
+const (
+	defaultConfigPath = "/etc/duo/login_duo.conf"
+	defaultPrompts    = 3
+	failSafe          = "safe"
+	failSecure        = "secure"
+)
+
+// loadConfig reads the configuration file at path.
+func loadConfig(path string) (*config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseConfig(f)
+}
+
+// parseConfig parses an INI file. Only the [duo] section is used; other
+// sections and unknown keys are ignored, as they may belong to other tools.
+func parseConfig(r io.Reader) (*config, error) {
+	c := &config{failmode: failSafe, prompts: defaultPrompts}
+	section := ""
+	scanner := bufio.NewScanner(r)
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == ';' || line[0] == '#' {
+			continue
+		}
+		if line[0] == '[' {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: invalid section header", lineno)
+			}
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		i := strings.IndexAny(line, "=:")
+		if i < 0 {
+			return nil, fmt.Errorf("line %d: expected key = value", lineno)
+		}
+		if section != "duo" {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(line[:i]))
+		value := strings.TrimSpace(line[i+1:])
+		if err := c.set(key, value); err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineno, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if c.ikey == "" || c.skey == "" || c.host == "" {
+		return nil, fmt.Errorf("ikey, skey and host are required in the [duo] section")
+	}
+	return c, nil
+}
+
+func (c *config) set(key, value string) error {
+	switch key {
+	case "ikey":
+		c.ikey = value
+	case "skey":
+		c.skey = value
+	case "host":
+		c.host = value
+	case "failmode":
+		value = strings.ToLower(value)
+		if value != failSafe && value != failSecure {
+			return fmt.Errorf("failmode must be %q or %q", failSafe, failSecure)
+		}
+		c.failmode = value
+	case "autopush":
+		b, err := parseBool(value)
+		if err != nil {
+			return fmt.Errorf("autopush: %v", err)
+		}
+		c.autopush = b
+	case "prompts":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > 3 {
+			return fmt.Errorf("prompts must be 1, 2 or 3")
+		}
+		c.prompts = n
+	}
+	return nil
+}
+
+// parseBool accepts the boolean spellings used in Duo configuration files.
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "yes", "true", "on", "1":
+		return true, nil
+	case "no", "false", "off", "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid boolean %q", value)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseConfig(t *testing.T) {
+	c, err := parseConfig(strings.NewReader(`
+; login_duo configuration
+[duo]
+ikey = DIWJ8X6AEYOR5OMC6TQ1
+skey = Zh5eGmUq9zpfQnyUIu5OL9iWoMMv5ZNmk3zLJ4Ep
+host = api-xxxxxxxx.duosecurity.com
+failmode = Secure
+autopush = yes
+prompts = 1
+pushinfo = yes
+
+[other]
+ikey = ignored
+`))
+	if err != nil {
+		t.Fatal("Failed to parse config: " + err.Error())
+	}
+	if c.ikey != "DIWJ8X6AEYOR5OMC6TQ1" || c.skey != "Zh5eGmUq9zpfQnyUIu5OL9iWoMMv5ZNmk3zLJ4Ep" || c.host != "api-xxxxxxxx.duosecurity.com" {
+		t.Errorf("Unexpected keys: %+v", c)
+	}
+	if c.failmode != failSecure || !c.autopush || c.prompts != 1 {
+		t.Errorf("Unexpected settings: %+v", c)
+	}
+}
+
+func TestParseConfigDefaults(t *testing.T) {
+	c, err := parseConfig(strings.NewReader("[duo]\nikey=a\nskey=b\nhost=c\n"))
+	if err != nil {
+		t.Fatal("Failed to parse config: " + err.Error())
+	}
+	if c.failmode != failSafe || c.autopush || c.prompts != defaultPrompts {
+		t.Errorf("Unexpected defaults: %+v", c)
+	}
+}
+
+func TestParseConfigErrors(t *testing.T) {
+	for _, text := range []string{
+		"[duo]\nikey=a\nhost=c\n",
+		"[duo\nikey=a\nskey=b\nhost=c\n",
+		"[duo]\nikey\nskey=b\nhost=c\n",
+		"[duo]\nikey=a\nskey=b\nhost=c\nfailmode=sometimes\n",
+		"[duo]\nikey=a\nskey=b\nhost=c\nautopush=maybe\n",
+		"[duo]\nikey=a\nskey=b\nhost=c\nprompts=4\n",
+	} {
+		if _, err := parseConfig(strings.NewReader(text)); err == nil {
+			t.Errorf("Expected error for %q", text)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+// Command duo-auth performs Duo second-factor authentication for shell
+// scripts and pam_exec hooks.
+//
+// Usage:
+//
+//	duo-auth [-c /etc/duo/login_duo.conf] [-user jsmith] [-factor push] [-async]
+//
+// The ikey, skey, host, failmode, autopush and prompts settings are read
+// from the [duo] section of the configuration file, in the same format as
+// login_duo and pam_duo. The username defaults to $PAM_USER and the client IP
+// address to $PAM_RHOST, as set by pam_exec.
+//
+// Without -factor, the user is prompted to choose a factor or enter a
+// passcode when standard input is a terminal. Otherwise, or with autopush,
+// a push or phone call is sent to the user's first suitable device.
+//
+// Exit status:
+//
+//	0  the user was allowed
+//	1  the user was denied, including Duo rejecting the request and -async
+//	   timing out
+//	2  an error occurred, including Duo being unavailable with failmode=secure
+//	3  Duo was unavailable and the user was allowed because failmode=safe
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"time"
+
+	duoapi "github.com/duosecurity/duo_api_golang"
+	"github.com/duosecurity/duo_api_golang/authapi"
+)
+
+// Exit statuses.
+const (
+	exitAllow    = 0
+	exitDeny     = 1
+	exitError    = 2
+	exitFailOpen = 3
+)
+
+// errAuthTimeout reports that an asynchronous authentication did not
+// complete within -timeout.
+var errAuthTimeout = errors.New("timed out waiting for the authentication")
+
+// newAuthApi builds the Duo client; tests replace it.
+var newAuthApi = func(c *config) *authapi.AuthApi {
+	return authapi.NewAuthApi(*duoapi.NewDuoApi(c.ikey, c.skey, c.host, "duo-auth"))
+}
+
+// app is a single run of the command.
+type app struct {
+	api         *authapi.AuthApi
+	config      *config
+	user        authapi.AuthUser
+	ipaddr      string
+	device      string
+	async       bool
+	timeout     time.Duration
+	stdin       io.Reader
+	stderr      io.Writer
+	interactive bool
+}
+
+func main() {
+	fi, err := os.Stdin.Stat()
+	interactive := err == nil && fi.Mode()&os.ModeCharDevice != 0
+	os.Exit(run(os.Args[1:], os.Getenv, os.Stdin, os.Stderr, interactive))
+}
+
+// run executes the command and returns its exit status.
+func run(args []string, getenv func(string) string, stdin io.Reader, stderr io.Writer, interactive bool) int {
+	flags := flag.NewFlagSet("duo-auth", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	configPath := flags.String("c", defaultConfigPath, "configuration `file`")
+	username := flags.String("user", getenv("PAM_USER"), "Duo `username`")
+	ipaddr := flags.String("ipaddr", getenv("PAM_RHOST"), "IP `address` of the user")
+	factor := flags.String("factor", "", "`factor` to use: auto, push, phone, sms or passcode")
+	device := flags.String("device", "auto", "`device` ID for push, phone and sms")
+	passcode := flags.String("passcode", "", "`passcode` for the passcode factor")
+	async := flags.Bool("async", false, "report the status of push and phone authentications as it changes")
+	timeout := flags.Duration("timeout", time.Minute, "maximum `duration` to wait with -async")
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+	if flags.NArg() > 0 || *username == "" {
+		fmt.Fprintln(stderr, "duo-auth: a username is required (use -user or set PAM_USER)")
+		return exitError
+	}
+
+	c, err := loadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "duo-auth: %v\n", err)
+		return exitError
+	}
+	a := &app{
+		api:         newAuthApi(c),
+		config:      c,
+		user:        authapi.AuthUser{Username: *username},
+		device:      *device,
+		async:       *async,
+		timeout:     *timeout,
+		stdin:       stdin,
+		stderr:      stderr,
+		interactive: interactive,
+	}
+	if net.ParseIP(*ipaddr) != nil {
+		a.ipaddr = *ipaddr
+	}
+
+	switch *factor {
+	case "":
+		return a.authenticate()
+	case "passcode":
+		if *passcode == "" {
+			fmt.Fprintln(stderr, "duo-auth: -passcode is required with -factor passcode")
+			return exitError
+		}
+		return a.preauthThen(func(*authapi.PreauthResult) int {
+			return a.auth("passcode", authapi.AuthPasscode(*passcode))
+		})
+	case "auto", "push", "phone", "sms":
+		return a.preauthThen(func(*authapi.PreauthResult) int {
+			return a.auth(*factor, authapi.AuthDevice(a.device))
+		})
+	default:
+		fmt.Fprintf(stderr, "duo-auth: unknown factor %q\n", *factor)
+		return exitError
+	}
+}
+
+// authenticate chooses the factor from the preauth result, prompting the
+// user when possible.
+func (a *app) authenticate() int {
+	return a.preauthThen(func(preauth *authapi.PreauthResult) int {
+		if a.interactive && !a.config.autopush {
+			return a.prompt(preauth)
+		}
+		// Without a terminal, only factors needing no input can be used.
+		req, err := preauth.RecommendedAuth(a.user,
+			authapi.FactorPolicy{authapi.CapabilityPush, authapi.CapabilityPhone})
+		if err != nil {
+			fmt.Fprintf(a.stderr, "duo-auth: %v\n", err)
+			return exitDeny
+		}
+		var device string
+		switch req := req.(type) {
+		case authapi.PushAuthRequest:
+			device = req.Device
+		case authapi.PhoneAuthRequest:
+			device = req.Device
+		}
+		return a.auth(req.Factor(), authapi.AuthDevice(device))
+	})
+}
+
+// preauthThen runs preauth, and calls next if a second factor is required.
+func (a *app) preauthThen(next func(*authapi.PreauthResult) int) int {
+	options := []func(*url.Values){authapi.PreauthUsername(a.user.Username)}
+	if a.ipaddr != "" {
+		options = append(options, authapi.PreauthIpAddr(a.ipaddr))
+	}
+	result, err := a.api.Preauth(options...)
+	if err != nil {
+		return a.fail(err, nil)
+	}
+	if result.Stat != "OK" {
+		return a.fail(nil, &result.StatResult)
+	}
+
+	switch result.Response.Result {
+	case "allow":
+		fmt.Fprintln(a.stderr, result.Response.Status_Msg)
+		return exitAllow
+	case "auth":
+		return next(result)
+	case "enroll":
+		fmt.Fprintf(a.stderr, "%s\nEnroll at %s\n", result.Response.Status_Msg, result.Response.Enroll_Portal_Url)
+		return exitDeny
+	default:
+		fmt.Fprintln(a.stderr, result.Response.Status_Msg)
+		return exitDeny
+	}
+}
+
+// auth runs Auth with the given factor for the user, and prints the result.
+func (a *app) auth(factor string, options ...func(*url.Values)) int {
+	result, err := a.authResult(factor, options...)
+	if err != nil {
+		return a.fail(err, nil)
+	}
+	if result.Stat != "OK" {
+		return a.fail(nil, &result.StatResult)
+	}
+	fmt.Fprintln(a.stderr, result.Response.Status_Msg)
+	if result.Response.Result == "allow" {
+		return exitAllow
+	}
+	return exitDeny
+}
+
+// authResult calls Auth, streaming status updates with -async.
+func (a *app) authResult(factor string, options ...func(*url.Values)) (*authapi.AuthResult, error) {
+	options = append(options, authapi.AuthUsername(a.user.Username))
+	if a.ipaddr != "" {
+		options = append(options, authapi.AuthIpAddr(a.ipaddr))
+	}
+	if !a.async || factor == "passcode" || factor == "sms" {
+		return a.api.Auth(factor, options...)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+	defer cancel()
+	async, err := a.api.StartAuth(ctx, 0, factor, options...)
+	if err != nil {
+		return nil, err
+	}
+	var last *authapi.AuthStatusResult
+	for status := range async.Updates() {
+		last = status
+		if status.Response.Result == "waiting" {
+			fmt.Fprintln(a.stderr, status.Response.Status_Msg)
+		}
+	}
+	if err = async.Err(); err == context.DeadlineExceeded {
+		return nil, errAuthTimeout
+	}
+	if err != nil {
+		return nil, err
+	}
+	result := &authapi.AuthResult{StatResult: last.StatResult}
+	result.Response.Result = last.Response.Result
+	result.Response.Status = last.Response.Status
+	result.Response.Status_Msg = last.Response.Status_Msg
+	return result, nil
+}
+
+// fail handles a failed Duo call according to the fail mode. Only Duo being
+// unavailable, a transport error or a 5xxxx error code, fails open; requests
+// rejected by Duo, such as for an unknown user, and -async timing out deny
+// the user.
+func (a *app) fail(err error, stat *duoapi.StatResult) int {
+	if err == nil {
+		err = stat.Err()
+	}
+	fmt.Fprintf(a.stderr, "duo-auth: %v\n", err)
+	var statErr *duoapi.StatError
+	if errors.As(err, &statErr) {
+		stat = &statErr.StatResult
+	}
+	if err == errAuthTimeout || (stat != nil && (stat.Code == nil || *stat.Code < 50000)) {
+		return exitDeny
+	}
+	if a.config.failmode == failSafe {
+		fmt.Fprintln(a.stderr, "duo-auth: Duo is unavailable, allowing access (failmode=safe)")
+		return exitFailOpen
+	}
+	return exitError
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	duoapi "github.com/duosecurity/duo_api_golang"
+	"github.com/duosecurity/duo_api_golang/authapi"
+)
+
+const (
+	preauthAuth = `{
+		"stat": "OK",
+		"response": {
+			"result": "auth",
+			"status_msg": "Account is active",
+			"devices": [{
+				"device": "DPFZRS9FB0D46QFTM891",
+				"type": "phone",
+				"number": "XXX-XXX-0100",
+				"display_name": "iOS (XXX-XXX-0100)",
+				"capabilities": ["push", "sms", "phone"]
+			}]
+		}
+	}`
+	authAllow   = `{"stat": "OK", "response": {"result": "allow", "status": "allow", "status_msg": "Success. Logging you in..."}}`
+	authDeny    = `{"stat": "OK", "response": {"result": "deny", "status": "deny", "status_msg": "Incorrect passcode. Please try again."}}`
+	authSMSSent = `{"stat": "OK", "response": {"result": "deny", "status": "sent", "status_msg": "New SMS passcodes sent"}}`
+)
+
+// testRun runs the command against a fake Duo serving responses, and returns
+// its exit status, its output and the parameters of the last request to
+// each path.
+func testRun(t *testing.T, responses map[string][]string, settings string, stdin string, interactive bool,
+	args ...string) (int, string, map[string]url.Values) {
+	params := make(map[string]url.Values)
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		params[r.URL.Path] = r.Form
+		queue := responses[r.URL.Path]
+		if len(queue) == 0 {
+			t.Errorf("Unexpected request to %s", r.URL.Path)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if strings.Contains(queue[0], `"FAIL"`) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		fmt.Fprintln(w, queue[0])
+		if len(queue) > 1 {
+			responses[r.URL.Path] = queue[1:]
+		}
+	}))
+	defer ts.Close()
+
+	saved := newAuthApi
+	defer func() { newAuthApi = saved }()
+	newAuthApi = func(c *config) *authapi.AuthApi {
+		return authapi.NewAuthApi(*duoapi.NewDuoApi(c.ikey, c.skey, strings.Split(ts.URL, "//")[1], "GoTestClient",
+			duoapi.SetTimeout(time.Second), duoapi.SetInsecure()))
+	}
+
+	dir, err := ioutil.TempDir("", "duo-auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "login_duo.conf")
+	conf := "[duo]\nikey = eyekey\nskey = esskey\nhost = example.com\n" + settings
+	if err = ioutil.WriteFile(path, []byte(conf), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	env := map[string]string{"PAM_USER": "jsmith", "PAM_RHOST": "192.0.2.10"}
+	var stderr bytes.Buffer
+	code := run(append([]string{"-c", path}, args...), func(key string) string { return env[key] },
+		strings.NewReader(stdin), &stderr, interactive)
+	return code, stderr.String(), params
+}
+
+func TestRunAutoPush(t *testing.T) {
+	code, output, params := testRun(t, map[string][]string{
+		"/auth/v2/preauth": {preauthAuth},
+		"/auth/v2/auth":    {authAllow},
+	}, "", "", false)
+	if code != exitAllow || !strings.Contains(output, "Success. Logging you in...") {
+		t.Errorf("Expected allow, got %d: %s", code, output)
+	}
+	auth := params["/auth/v2/auth"]
+	if auth.Get("username") != "jsmith" || auth.Get("ipaddr") != "192.0.2.10" ||
+		auth.Get("factor") != "push" || auth.Get("device") != "DPFZRS9FB0D46QFTM891" {
+		t.Errorf("Unexpected auth parameters: %v", auth)
+	}
+}
+
+func TestRunPasscodeFlag(t *testing.T) {
+	code, _, params := testRun(t, map[string][]string{
+		"/auth/v2/preauth": {preauthAuth},
+		"/auth/v2/auth":    {authDeny},
+	}, "", "", false, "-user", "jdoe", "-factor", "passcode", "-passcode", "123456")
+	if code != exitDeny {
+		t.Errorf("Expected deny, got %d", code)
+	}
+	if auth := params["/auth/v2/auth"]; auth.Get("username") != "jdoe" || auth.Get("passcode") != "123456" {
+		t.Errorf("Unexpected auth parameters: %v", auth)
+	}
+}
+
+func TestRunPreauthResults(t *testing.T) {
+	code, _, _ := testRun(t, map[string][]string{
+		"/auth/v2/preauth": {`{"stat": "OK", "response": {"result": "allow", "status_msg": "Account is active"}}`},
+	}, "", "", false)
+	if code != exitAllow {
+		t.Errorf("Expected allow for a bypass user, got %d", code)
+	}
+
+	code, output, _ := testRun(t, map[string][]string{
+		"/auth/v2/preauth": {`{"stat": "OK", "response": {"result": "enroll", "status_msg": "Enroll an authentication device to proceed", "enroll_portal_url": "https://api-xxxxxxxx.duosecurity.com/portal?048bac"}}`},
+	}, "", "", false)
+	if code != exitDeny || !strings.Contains(output, "https://api-xxxxxxxx.duosecurity.com/portal?048bac") {
+		t.Errorf("Expected deny with the enrollment portal, got %d: %s", code, output)
+	}
+}
+
+func TestRunInteractive(t *testing.T) {
+	code, output, params := testRun(t, map[string][]string{
+		"/auth/v2/preauth": {preauthAuth},
+		"/auth/v2/auth":    {authSMSSent, authDeny, authAllow},
+	}, "", "3\n111111\n222222\n", true)
+	if code != exitAllow {
+		t.Errorf("Expected allow, got %d: %s", code, output)
+	}
+	for _, expected := range []string{" 1. Duo Push to iOS (XXX-XXX-0100)", " 3. SMS passcodes to iOS (XXX-XXX-0100)", "New SMS passcodes sent", "Incorrect passcode"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected %q in output: %s", expected, output)
+		}
+	}
+	if passcode := params["/auth/v2/auth"].Get("passcode"); passcode != "222222" {
+		t.Errorf("Unexpected passcode: %q", passcode)
+	}
+
+	// The number of attempts is limited by prompts.
+	code, _, _ = testRun(t, map[string][]string{
+		"/auth/v2/preauth": {preauthAuth},
+		"/auth/v2/auth":    {authDeny, authAllow},
+	}, "prompts = 1\n", "111111\n222222\n", true)
+	if code != exitDeny {
+		t.Errorf("Expected deny after one prompt, got %d", code)
+	}
+
+	// Sending SMS passcodes uses an attempt.
+	code, _, _ = testRun(t, map[string][]string{
+		"/auth/v2/preauth": {preauthAuth},
+		"/auth/v2/auth":    {authSMSSent, authSMSSent, authAllow},
+	}, "prompts = 2\n", "3\n3\n111111\n", true)
+	if code != exitDeny {
+		t.Errorf("Expected deny after two SMS prompts, got %d", code)
+	}
+}
+
+func TestRunAsync(t *testing.T) {
+	code, output, params := testRun(t, map[string][]string{
+		"/auth/v2/preauth": {preauthAuth},
+		"/auth/v2/auth":    {`{"stat": "OK", "response": {"txid": "45f7c92b-f45f-4862-8545-e0f58e78075a"}}`},
+		"/auth/v2/auth_status": {
+			`{"stat": "OK", "response": {"result": "waiting", "status": "pushed", "status_msg": "Pushed a login request to your phone..."}}`,
+			`{"stat": "OK", "response": {"result": "allow", "status": "allow", "status_msg": "Success. Logging you in..."}}`,
+		},
+	}, "", "", false, "-factor", "push", "-async")
+	if code != exitAllow {
+		t.Errorf("Expected allow, got %d: %s", code, output)
+	}
+	if !strings.Contains(output, "Pushed a login request") || !strings.Contains(output, "Success. Logging you in...") {
+		t.Errorf("Expected status updates, got %s", output)
+	}
+	if auth := params["/auth/v2/auth"]; auth.Get("async") != "1" || auth.Get("device") != "auto" {
+		t.Errorf("Unexpected auth parameters: %v", auth)
+	}
+}
+
+// Test that -async timing out or being rejected by Duo denies the user,
+// even with failmode=safe.
+func TestRunAsyncFailures(t *testing.T) {
+	started := `{"stat": "OK", "response": {"txid": "45f7c92b-f45f-4862-8545-e0f58e78075a"}}`
+	code, output, _ := testRun(t, map[string][]string{
+		"/auth/v2/preauth":     {preauthAuth},
+		"/auth/v2/auth":        {started},
+		"/auth/v2/auth_status": {`{"stat": "OK", "response": {"result": "waiting", "status": "pushed", "status_msg": "Pushed a login request to your phone..."}}`},
+	}, "", "", false, "-factor", "push", "-async", "-timeout", "100ms")
+	if code != exitDeny {
+		t.Errorf("Expected deny after timing out, got %d: %s", code, output)
+	}
+
+	code, output, _ = testRun(t, map[string][]string{
+		"/auth/v2/preauth":     {preauthAuth},
+		"/auth/v2/auth":        {started},
+		"/auth/v2/auth_status": {`{"stat": "FAIL", "code": 40002, "message": "Invalid request parameters"}`},
+	}, "", "", false, "-factor", "push", "-async")
+	if code != exitDeny {
+		t.Errorf("Expected deny for a rejected request, got %d: %s", code, output)
+	}
+}
+
+func TestRunFailMode(t *testing.T) {
+	down := map[string][]string{"/auth/v2/preauth": {`{"stat": "FAIL", "code": 50000, "message": "Internal error"}`}}
+	if code, _, _ := testRun(t, down, "", "", false); code != exitFailOpen {
+		t.Errorf("Expected fail open, got %d", code)
+	}
+	down = map[string][]string{"/auth/v2/preauth": {`{"stat": "FAIL", "code": 50000, "message": "Internal error"}`}}
+	if code, _, _ := testRun(t, down, "failmode = secure\n", "", false); code != exitError {
+		t.Errorf("Expected error with failmode=secure, got %d", code)
+	}
+
+	// Errors other than Duo being unavailable never fail open.
+	invalid := map[string][]string{"/auth/v2/preauth": {`{"stat": "FAIL", "code": 40002, "message": "Invalid request parameters"}`}}
+	if code, _, _ := testRun(t, invalid, "", "", false); code != exitDeny {
+		t.Errorf("Expected deny for an invalid request, got %d", code)
+	}
+}
+
+func TestRunUsage(t *testing.T) {
+	for _, args := range [][]string{
+		{"-factor", "bogus"},
+		{"-factor", "passcode"},
+		{"-bogus"},
+	} {
+		if code, _, _ := testRun(t, map[string][]string{}, "", "", false, args...); code != exitError {
+			t.Errorf("Expected error for %v, got %d", args, code)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/duosecurity/duo_api_golang/authapi"
+)
+
+// promptOption is a numbered choice offered by the interactive prompt.
+type promptOption struct {
+	label  string
+	factor string
+	device string
+}
+
+// promptOptions lists the factors available on the user's devices.
+func promptOptions(preauth *authapi.PreauthResult) []promptOption {
+	var options []promptOption
+	for _, device := range preauth.Response.Devices {
+		name := device.Display_Name
+		if name == "" {
+			name = device.Number
+		}
+		if name == "" {
+			name = device.Name
+		}
+		if device.SupportsPush() {
+			options = append(options, promptOption{"Duo Push to " + name, "push", device.Device})
+		}
+		if device.SupportsPhone() {
+			options = append(options, promptOption{"Phone call to " + name, "phone", device.Device})
+		}
+		if device.SupportsSMS() {
+			options = append(options, promptOption{"SMS passcodes to " + name, "sms", device.Device})
+		}
+	}
+	return options
+}
+
+// prompt asks the user for a passcode or an option, up to the configured
+// number of attempts. Sending SMS passcodes uses an attempt too.
+func (a *app) prompt(preauth *authapi.PreauthResult) int {
+	options := promptOptions(preauth)
+	reader := bufio.NewReader(a.stdin)
+	for attempt := 0; attempt < a.config.prompts; attempt++ {
+		fmt.Fprintf(a.stderr, "Duo two-factor login for %s\n\n", a.user.Username)
+		if len(options) == 0 {
+			fmt.Fprint(a.stderr, "Passcode: ")
+		} else {
+			fmt.Fprint(a.stderr, "Enter a passcode or select one of the following options:\n\n")
+			for i, option := range options {
+				fmt.Fprintf(a.stderr, " %d. %s\n", i+1, option.label)
+			}
+			fmt.Fprintf(a.stderr, "\nPasscode or option (1-%d): ", len(options))
+		}
+
+		line, err := reader.ReadString('\n')
+		line = strings.TrimSpace(line)
+		if err != nil && line == "" {
+			fmt.Fprintln(a.stderr)
+			return exitDeny
+		}
+
+		factor := "passcode"
+		device := ""
+		if n, err := strconv.Atoi(line); err == nil && n >= 1 && n <= len(options) {
+			factor, device = options[n-1].factor, options[n-1].device
+		}
+		var result *authapi.AuthResult
+		if factor == "passcode" {
+			result, err = a.authResult(factor, authapi.AuthPasscode(line))
+		} else {
+			result, err = a.authResult(factor, authapi.AuthDevice(device))
+		}
+		if err != nil {
+			return a.fail(err, nil)
+		}
+		if result.Stat != "OK" {
+			return a.fail(nil, &result.StatResult)
+		}
+		fmt.Fprintln(a.stderr, result.Response.Status_Msg)
+		if result.Response.Result == "allow" {
+			return exitAllow
+		}
+	}
+	return exitDeny
+}
@@ -0,0 +1,150 @@
+// Command duo-radius is a RADIUS server that authenticates users with Duo,
+// bridging network appliances that only speak RADIUS to the Auth API.
+//
+// Usage:
+//
+//	DUO_SKEY=... RADIUS_SECRET=... duo-radius -ikey DIXXXXXXXXXXXXXXXXXX -host api-XXXXXXXX.duosecurity.com
+//
+// The Duo secret key and the RADIUS shared secret are read from the
+// environment so that they do not appear in the process list. See package
+// radius for how the password field selects the second factor.
+//
+// With -delimiter, the password field also carries the primary password,
+// which is checked by running the -primary-command program. The program gets
+// the username in $RADIUS_USER and the password on standard input, and must
+// exit with status 0 to accept it.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	duoapi "github.com/duosecurity/duo_api_golang"
+	"github.com/duosecurity/duo_api_golang/authapi"
+	"github.com/duosecurity/duo_api_golang/radius"
+)
+
+type config struct {
+	listen         string
+	ikey           string
+	skey           string
+	host           string
+	secret         string
+	delimiter      string
+	primaryCommand string
+	defaultFactor  string
+	failOpen       bool
+	requireMsgAuth bool
+}
+
+// parseConfig reads the configuration from the command line arguments and
+// the environment.
+func parseConfig(args []string, getenv func(string) string, output io.Writer) (*config, error) {
+	c := &config{}
+	flags := flag.NewFlagSet("duo-radius", flag.ContinueOnError)
+	flags.SetOutput(output)
+	flags.StringVar(&c.listen, "listen", ":1812", "UDP `address` to listen on")
+	flags.StringVar(&c.ikey, "ikey", "", "Duo integration key")
+	flags.StringVar(&c.host, "host", "", "Duo API hostname")
+	flags.StringVar(&c.delimiter, "delimiter", "", "split the password field into a password and a factor at the last `delimiter`")
+	flags.StringVar(&c.primaryCommand, "primary-command", "", "`program` checking the password when -delimiter is set")
+	flags.StringVar(&c.defaultFactor, "factor", "push", "`factor` used when the password field does not name one: push, phone, sms or auto")
+	flags.BoolVar(&c.failOpen, "fail-open", false, "accept users when Duo cannot be reached")
+	flags.BoolVar(&c.requireMsgAuth, "require-message-authenticator", true, "ignore requests without a Message-Authenticator")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected argument %q", flags.Arg(0))
+	}
+	c.skey = getenv("DUO_SKEY")
+	c.secret = getenv("RADIUS_SECRET")
+	if c.ikey == "" || c.host == "" || c.skey == "" {
+		return nil, errors.New("-ikey, -host and DUO_SKEY are required")
+	}
+	if c.secret == "" {
+		return nil, errors.New("RADIUS_SECRET is required")
+	}
+	if (c.delimiter == "") != (c.primaryCommand == "") {
+		return nil, errors.New("-delimiter and -primary-command must be used together")
+	}
+	switch c.defaultFactor {
+	case "push", "phone", "sms", "auto":
+	default:
+		return nil, fmt.Errorf("unknown -factor %q: must be push, phone, sms or auto", c.defaultFactor)
+	}
+	return c, nil
+}
+
+// newServer builds the RADIUS server described by c.
+func newServer(c *config, logger *log.Logger) *radius.Server {
+	api := authapi.NewAuthApi(*duoapi.NewDuoApi(c.ikey, c.skey, c.host, "duo-radius"))
+	options := []func(*radius.Server){
+		radius.SetDefaultFactor(c.defaultFactor),
+		radius.SetLogger(logger),
+	}
+	if c.delimiter != "" {
+		options = append(options, radius.SetDelimiter(c.delimiter),
+			radius.SetPrimaryAuth(primaryCommand(c.primaryCommand)))
+	}
+	if c.failOpen {
+		options = append(options, radius.SetFailOpen())
+	}
+	if c.requireMsgAuth {
+		options = append(options, radius.SetRequireMessageAuthenticator())
+	}
+	return radius.NewServer(api, radius.StaticSecret([]byte(c.secret)), options...)
+}
+
+// primaryCommandTimeout bounds how long the primary password check may take.
+const primaryCommandTimeout = 10 * time.Second
+
+// primaryCommand returns a PrimaryAuthFunc running the program at path.
+// A non-zero exit status rejects the password.
+func primaryCommand(path string) radius.PrimaryAuthFunc {
+	return func(username, password string) (bool, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), primaryCommandTimeout)
+		defer cancel()
+		cmd := exec.CommandContext(ctx, path)
+		cmd.Env = append(commandEnv(), "RADIUS_USER="+username)
+		cmd.Stdin = strings.NewReader(password)
+		err := cmd.Run()
+		if _, rejected := err.(*exec.ExitError); rejected && ctx.Err() == nil {
+			return false, nil
+		}
+		return err == nil, err
+	}
+}
+
+// commandEnv returns the environment of the process without the secrets it
+// was given.
+func commandEnv() []string {
+	var env []string
+	for _, v := range os.Environ() {
+		if !strings.HasPrefix(v, "DUO_SKEY=") && !strings.HasPrefix(v, "RADIUS_SECRET=") {
+			env = append(env, v)
+		}
+	}
+	return env
+}
+
+func main() {
+	logger := log.New(os.Stderr, "", log.LstdFlags)
+	c, err := parseConfig(os.Args[1:], os.Getenv, os.Stderr)
+	if err == flag.ErrHelp {
+		os.Exit(0)
+	}
+	if err != nil {
+		logger.Fatal(err)
+	}
+	logger.Printf("listening on %s", c.listen)
+	logger.Fatal(newServer(c, logger).ListenAndServe(c.listen))
+}
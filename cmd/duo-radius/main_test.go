@@ -0,0 +1,90 @@
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseConfig(t *testing.T) {
+	env := map[string]string{"DUO_SKEY": "esskey", "RADIUS_SECRET": "s3cret"}
+	getenv := func(key string) string { return env[key] }
+
+	c, err := parseConfig([]string{"-ikey", "eyekey", "-host", "api-xxxxxxxx.duosecurity.com",
+		"-delimiter", ",", "-primary-command", "/usr/local/bin/check-password", "-fail-open"}, getenv, ioutil.Discard)
+	if err != nil {
+		t.Fatal("Failed to parse config: " + err.Error())
+	}
+	if c.listen != ":1812" || c.ikey != "eyekey" || c.skey != "esskey" || c.secret != "s3cret" {
+		t.Errorf("Unexpected config: %+v", c)
+	}
+	if c.delimiter != "," || c.primaryCommand != "/usr/local/bin/check-password" || !c.failOpen ||
+		c.defaultFactor != "push" || !c.requireMsgAuth {
+		t.Errorf("Unexpected options: %+v", c)
+	}
+	if newServer(c, log.New(ioutil.Discard, "", 0)) == nil {
+		t.Error("Expected a server")
+	}
+
+	if _, err := parseConfig([]string{"-host", "api-xxxxxxxx.duosecurity.com"}, getenv, ioutil.Discard); err == nil {
+		t.Error("Expected error without an integration key")
+	}
+	c, err = parseConfig([]string{"-ikey", "eyekey", "-host", "api-xxxxxxxx.duosecurity.com",
+		"-require-message-authenticator=false"}, getenv, ioutil.Discard)
+	if err != nil || c.requireMsgAuth {
+		t.Errorf("Expected Message-Authenticator to be optional, got %+v, %v", c, err)
+	}
+
+	c, err = parseConfig([]string{"-ikey", "eyekey", "-host", "api-xxxxxxxx.duosecurity.com",
+		"-factor", "sms"}, getenv, ioutil.Discard)
+	if err != nil || c.defaultFactor != "sms" {
+		t.Errorf("Expected the sms factor, got %+v, %v", c, err)
+	}
+	if _, err := parseConfig([]string{"-ikey", "eyekey", "-host", "api-xxxxxxxx.duosecurity.com",
+		"-factor", "passcode"}, getenv, ioutil.Discard); err == nil {
+		t.Error("Expected error for an unknown factor")
+	}
+
+	delete(env, "RADIUS_SECRET")
+	if _, err := parseConfig([]string{"-ikey", "eyekey", "-host", "api-xxxxxxxx.duosecurity.com"}, getenv, ioutil.Discard); err == nil {
+		t.Error("Expected error without a RADIUS secret")
+	}
+	if _, err := parseConfig([]string{"-ikey", "eyekey", "-host", "api-xxxxxxxx.duosecurity.com",
+		"-delimiter", ","}, getenv, ioutil.Discard); err == nil {
+		t.Error("Expected error for a delimiter without a primary command")
+	}
+	if _, err := parseConfig([]string{"-bogus"}, getenv, ioutil.Discard); err == nil {
+		t.Error("Expected error for an unknown flag")
+	}
+}
+
+func TestPrimaryCommand(t *testing.T) {
+	dir, err := ioutil.TempDir("", "duo-radius")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	script := filepath.Join(dir, "check-password")
+	err = ioutil.WriteFile(script, []byte(`#!/bin/sh
+read password
+[ "$RADIUS_USER" = jsmith ] && [ "$password" = hunter2 ] && [ -z "$DUO_SKEY" ]
+`), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("DUO_SKEY", "esskey")
+	defer os.Unsetenv("DUO_SKEY")
+
+	check := primaryCommand(script)
+	if ok, err := check("jsmith", "hunter2"); !ok || err != nil {
+		t.Errorf("Expected password to be accepted, got %v, %v", ok, err)
+	}
+	if ok, err := check("jsmith", "wrong"); ok || err != nil {
+		t.Errorf("Expected password to be rejected, got %v, %v", ok, err)
+	}
+	if ok, err := primaryCommand(filepath.Join(dir, "missing"))("jsmith", "hunter2"); ok || err == nil {
+		t.Errorf("Expected error for a missing program, got %v, %v", ok, err)
+	}
+}
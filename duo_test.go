@@ -426,3 +426,25 @@ func (svc *mockSleepService) Sleep(duration time.Duration) {
 	}
 	svc.sleepCalls = append(svc.sleepCalls, duration)
 }
+
+func TestStatResultErr(t *testing.T) {
+	ok := StatResult{Stat: "OK"}
+	if err := ok.Err(); err != nil {
+		t.Errorf("Expected nil error for stat OK, got %v", err)
+	}
+
+	code := int32(40002)
+	message := "Invalid request parameters"
+	detail := "username"
+	failed := StatResult{Stat: "FAIL", Code: &code, Message: &message, Message_Detail: &detail}
+	err := failed.Err()
+	if err == nil {
+		t.Fatal("Expected error for stat FAIL")
+	}
+	if statErr, ok := err.(*StatError); !ok || *statErr.Code != 40002 {
+		t.Errorf("Expected *StatError with code 40002, got %#v", err)
+	}
+	if expected := "duo api call failed with stat FAIL (code 40002): Invalid request parameters: username"; err.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, err.Error())
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"math/rand"
@@ -242,6 +243,7 @@ func NewDuoApi(ikey string,
 
 type requestOptions struct {
 	timeout bool
+	headers map[string]string
 }
 
 type DuoApiOption func(*requestOptions)
@@ -251,6 +253,18 @@ func UseTimeout(opts *requestOptions) {
 	opts.timeout = true
 }
 
+// Pass to Call or SignedCall to add a header to the request, such as
+// If-Modified-Since for a conditional request. It cannot replace the headers
+// set by the call itself.
+func UseHeader(name, value string) DuoApiOption {
+	return func(opts *requestOptions) {
+		if opts.headers == nil {
+			opts.headers = make(map[string]string)
+		}
+		opts.headers[name] = value
+	}
+}
+
 func (duoapi *DuoApi) buildOptions(options ...DuoApiOption) *requestOptions {
 	opts := &requestOptions{}
 	for _, o := range options {
@@ -296,6 +310,33 @@ func (s *StatResult) SyncCode() {
 	s.Code = s.Ncode.value
 }
 
+// StatError describes an API call whose Stat was not 'OK'.
+type StatError struct {
+	StatResult
+}
+
+func (e *StatError) Error() string {
+	msg := "duo api call failed with stat " + e.Stat
+	if e.Code != nil {
+		msg += fmt.Sprintf(" (code %d)", *e.Code)
+	}
+	if e.Message != nil {
+		msg += ": " + *e.Message
+	}
+	if e.Message_Detail != nil {
+		msg += ": " + *e.Message_Detail
+	}
+	return msg
+}
+
+// Err returns a *StatError when Stat is not 'OK', and nil otherwise.
+func (s *StatResult) Err() error {
+	if s.Stat == "OK" {
+		return nil
+	}
+	return &StatError{*s}
+}
+
 // SetCustomHTTPClient allows one to set a completely custom http client that
 // will be used to make network calls to the duo api
 func (duoapi *DuoApi) SetCustomHTTPClient(c *http.Client) {
@@ -459,6 +500,9 @@ func (duoapi *DuoApi) makeRetryableHttpCall(
 			return nil, nil, err
 		}
 
+		for k, v := range opts.headers {
+			request.Header.Set(k, v)
+		}
 		if headers != nil {
 			for k, v := range headers {
 				request.Header.Set(k, v)
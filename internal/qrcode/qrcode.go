@@ -0,0 +1,522 @@
+// Package qrcode encodes data as a QR code, so that activation codes can be
+// rendered locally instead of fetching an image from Duo.
+//
+// Only what the API clients need is implemented: byte mode and error
+// correction level M, in versions 1 to 40.
+package qrcode
+
+import (
+	"errors"
+)
+
+// Code is an encoded QR code, without its quiet zone.
+type Code struct {
+	// Version is the QR code version, from 1 to 40.
+	Version int
+	// Size is the width and height of the code in modules.
+	Size int
+
+	modules    [][]bool
+	isFunction [][]bool
+}
+
+// Black reports whether the module in column x and row y is dark.
+// Coordinates outside the code are light, which covers the quiet zone.
+func (c *Code) Black(x, y int) bool {
+	if x < 0 || y < 0 || x >= c.Size || y >= c.Size {
+		return false
+	}
+	return c.modules[y][x]
+}
+
+// ErrTooLong is returned by Encode when the data does not fit in a version
+// 40 code.
+var ErrTooLong = errors.New("data too long for a QR code")
+
+// Tables for error correction level M, indexed by version.
+var (
+	eccCodewordsPerBlock = [41]int{-1,
+		10, 16, 26, 18, 24, 16, 18, 22, 22, 26,
+		30, 22, 22, 24, 24, 28, 28, 26, 26, 26,
+		26, 28, 28, 28, 28, 28, 28, 28, 28, 28,
+		28, 28, 28, 28, 28, 28, 28, 28, 28, 28}
+	numErrorCorrectionBlocks = [41]int{-1,
+		1, 1, 1, 2, 2, 4, 4, 4, 5, 5,
+		5, 8, 9, 9, 10, 10, 11, 13, 14, 16,
+		17, 17, 18, 20, 21, 23, 25, 26, 28, 29,
+		31, 33, 35, 37, 38, 40, 43, 45, 47, 49}
+)
+
+// Format information bits for error correction level M.
+const eccLevelM = 0
+
+// Encode returns the smallest QR code holding data in byte mode.
+func Encode(data []byte) (*Code, error) {
+	return encode(data, -1)
+}
+
+// encode is Encode with the given mask pattern, or the one with the lowest
+// penalty if mask is -1.
+func encode(data []byte, mask int) (*Code, error) {
+	version := 0
+	for v := 1; v <= 40; v++ {
+		if 4+charCountBits(v)+8*len(data) <= 8*numDataCodewords(v) {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, ErrTooLong
+	}
+
+	var bits bitBuffer
+	bits.append(0x4, 4)
+	bits.append(len(data), charCountBits(version))
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+	capacity := 8 * numDataCodewords(version)
+	terminator := capacity - len(bits)
+	if terminator > 4 {
+		terminator = 4
+	}
+	bits.append(0, terminator)
+	bits.append(0, (8-len(bits)%8)%8)
+	codewords := bits.bytes()
+	for pad := 0xEC; len(codewords) < capacity/8; pad ^= 0xEC ^ 0x11 {
+		codewords = append(codewords, byte(pad))
+	}
+
+	c := newCode(version)
+	c.drawFunctionPatterns()
+	c.drawCodewords(addECCAndInterleave(version, codewords))
+
+	if mask < 0 {
+		mask = c.bestMask()
+	}
+	c.applyMask(mask)
+	c.drawFormatBits(mask)
+	c.isFunction = nil
+	return c, nil
+}
+
+func newCode(version int) *Code {
+	size := version*4 + 17
+	c := &Code{Version: version, Size: size}
+	c.modules = make([][]bool, size)
+	c.isFunction = make([][]bool, size)
+	for i := range c.modules {
+		c.modules[i] = make([]bool, size)
+		c.isFunction[i] = make([]bool, size)
+	}
+	return c
+}
+
+func charCountBits(version int) int {
+	if version <= 9 {
+		return 8
+	}
+	return 16
+}
+
+// numRawDataModules returns the number of modules available for data and
+// error correction codewords, including remainder bits.
+func numRawDataModules(version int) int {
+	result := (16*version+128)*version + 64
+	if version >= 2 {
+		numAlign := version/7 + 2
+		result -= (25*numAlign-10)*numAlign - 55
+		if version >= 7 {
+			result -= 36
+		}
+	}
+	return result
+}
+
+func numDataCodewords(version int) int {
+	return numRawDataModules(version)/8 -
+		eccCodewordsPerBlock[version]*numErrorCorrectionBlocks[version]
+}
+
+// alignmentPatternPositions returns the ascending row and column coordinates
+// of the centers of the alignment patterns.
+func alignmentPatternPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+	numAlign := version/7 + 2
+	step := (version*8 + numAlign*3 + 5) / (numAlign*4 - 4) * 2
+	result := make([]int, numAlign)
+	result[0] = 6
+	for i, pos := numAlign-1, version*4+17-7; i >= 1; i, pos = i-1, pos-step {
+		result[i] = pos
+	}
+	return result
+}
+
+func (c *Code) setFunction(x, y int, black bool) {
+	c.modules[y][x] = black
+	c.isFunction[y][x] = true
+}
+
+func (c *Code) drawFunctionPatterns() {
+	for i := 0; i < c.Size; i++ {
+		c.setFunction(6, i, i%2 == 0)
+		c.setFunction(i, 6, i%2 == 0)
+	}
+
+	c.drawFinderPattern(3, 3)
+	c.drawFinderPattern(c.Size-4, 3)
+	c.drawFinderPattern(3, c.Size-4)
+
+	positions := alignmentPatternPositions(c.Version)
+	last := len(positions) - 1
+	for i := range positions {
+		for j := range positions {
+			// Skip the three corners occupied by finder patterns.
+			if i == 0 && j == 0 || i == 0 && j == last || i == last && j == 0 {
+				continue
+			}
+			c.drawAlignmentPattern(positions[i], positions[j])
+		}
+	}
+
+	// Reserve the format areas; the bits are drawn once the mask is known.
+	c.drawFormatBits(0)
+	c.drawVersion()
+}
+
+// drawFinderPattern draws a finder pattern and its separator centered on (x, y).
+func (c *Code) drawFinderPattern(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || xx >= c.Size || yy < 0 || yy >= c.Size {
+				continue
+			}
+			dist := max(abs(dx), abs(dy))
+			c.setFunction(xx, yy, dist != 2 && dist != 4)
+		}
+	}
+}
+
+func (c *Code) drawAlignmentPattern(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			c.setFunction(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// formatBits returns the 15 format information bits for mask.
+func formatBits(mask int) int {
+	data := eccLevelM<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	return (data<<10 | rem) ^ 0x5412
+}
+
+func (c *Code) drawFormatBits(mask int) {
+	bits := formatBits(mask)
+
+	// First copy, around the top left finder pattern.
+	for i := 0; i <= 5; i++ {
+		c.setFunction(8, i, bit(bits, i))
+	}
+	c.setFunction(8, 7, bit(bits, 6))
+	c.setFunction(8, 8, bit(bits, 7))
+	c.setFunction(7, 8, bit(bits, 8))
+	for i := 9; i < 15; i++ {
+		c.setFunction(14-i, 8, bit(bits, i))
+	}
+
+	// Second copy, split between the other two finder patterns.
+	for i := 0; i < 8; i++ {
+		c.setFunction(c.Size-1-i, 8, bit(bits, i))
+	}
+	for i := 8; i < 15; i++ {
+		c.setFunction(8, c.Size-15+i, bit(bits, i))
+	}
+	c.setFunction(8, c.Size-8, true) // Always dark.
+}
+
+func (c *Code) drawVersion() {
+	if c.Version < 7 {
+		return
+	}
+	rem := c.Version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+	bits := c.Version<<12 | rem
+	for i := 0; i < 18; i++ {
+		a, b := c.Size-11+i%3, i/3
+		c.setFunction(a, b, bit(bits, i))
+		c.setFunction(b, a, bit(bits, i))
+	}
+}
+
+// drawCodewords places the data in the zigzag pattern, two columns at a time
+// from the bottom right corner.
+func (c *Code) drawCodewords(data []byte) {
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5 // Skip the vertical timing pattern.
+		}
+		for vert := 0; vert < c.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vert
+				if (right+1)&2 == 0 {
+					y = c.Size - 1 - vert // Upward column.
+				}
+				if !c.isFunction[y][x] && i < len(data)*8 {
+					c.modules[y][x] = bit(int(data[i>>3]), 7-i&7)
+					i++
+				}
+				// Remainder bits are left light.
+			}
+		}
+	}
+}
+
+// maskBit reports whether the data module at (x, y) is inverted by mask.
+func maskBit(mask, x, y int) bool {
+	switch mask {
+	case 0:
+		return (x+y)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (x+y)%3 == 0
+	case 4:
+		return (x/3+y/2)%2 == 0
+	case 5:
+		return x*y%2+x*y%3 == 0
+	case 6:
+		return (x*y%2+x*y%3)%2 == 0
+	default:
+		return ((x+y)%2+x*y%3)%2 == 0
+	}
+}
+
+func (c *Code) applyMask(mask int) {
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if !c.isFunction[y][x] && maskBit(mask, x, y) {
+				c.modules[y][x] = !c.modules[y][x]
+			}
+		}
+	}
+}
+
+// bestMask returns the mask pattern with the lowest penalty.
+func (c *Code) bestMask() int {
+	best, minPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		c.applyMask(mask)
+		c.drawFormatBits(mask)
+		if penalty := c.penalty(); minPenalty < 0 || penalty < minPenalty {
+			best, minPenalty = mask, penalty
+		}
+		c.applyMask(mask) // Masking twice undoes it.
+	}
+	return best
+}
+
+// Penalty weights from the QR code specification.
+const (
+	penaltyN1 = 3
+	penaltyN2 = 3
+	penaltyN3 = 40
+	penaltyN4 = 10
+)
+
+// finderLike is the 1:1:3:1:1 pattern, with four light modules on one side,
+// that scanners could mistake for a finder pattern.
+var finderLike = [2][11]bool{
+	{true, false, true, true, true, false, true, false, false, false, false},
+	{false, false, false, false, true, false, true, true, true, false, true},
+}
+
+// penalty scores the code as masked; the mask with the lowest score is used.
+func (c *Code) penalty() int {
+	result := 0
+	for i := 0; i < c.Size; i++ {
+		result += c.linePenalty(func(j int) bool { return c.modules[i][j] })
+		result += c.linePenalty(func(j int) bool { return c.modules[j][i] })
+	}
+
+	dark := 0
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.modules[y][x] {
+				dark++
+			}
+			if x+1 < c.Size && y+1 < c.Size {
+				color := c.modules[y][x]
+				if color == c.modules[y][x+1] && color == c.modules[y+1][x] && color == c.modules[y+1][x+1] {
+					result += penaltyN2
+				}
+			}
+		}
+	}
+
+	total := c.Size * c.Size
+	k := (abs(dark*20-total*10)+total-1)/total - 1
+	return result + k*penaltyN4
+}
+
+// linePenalty scores runs of the same color and finder-like patterns in a
+// single row or column.
+func (c *Code) linePenalty(module func(int) bool) int {
+	result := 0
+	run := 0
+	for j := 0; j < c.Size; j++ {
+		if j > 0 && module(j) == module(j-1) {
+			run++
+		} else {
+			run = 1
+		}
+		if run == 5 {
+			result += penaltyN1
+		} else if run > 5 {
+			result++
+		}
+	}
+
+	for j := 0; j+11 <= c.Size; j++ {
+		for _, pattern := range finderLike {
+			matches := true
+			for k, black := range pattern {
+				if module(j+k) != black {
+					matches = false
+					break
+				}
+			}
+			if matches {
+				result += penaltyN3
+			}
+		}
+	}
+	return result
+}
+
+// addECCAndInterleave splits data into blocks, appends the error correction
+// codewords of each block, and interleaves the blocks.
+func addECCAndInterleave(version int, data []byte) []byte {
+	numBlocks := numErrorCorrectionBlocks[version]
+	blockECCLen := eccCodewordsPerBlock[version]
+	rawCodewords := numRawDataModules(version) / 8
+	numShortBlocks := numBlocks - rawCodewords%numBlocks
+	shortBlockLen := rawCodewords / numBlocks
+
+	divisor := reedSolomonDivisor(blockECCLen)
+	blocks := make([][]byte, numBlocks)
+	for i, k := 0, 0; i < numBlocks; i++ {
+		n := shortBlockLen - blockECCLen
+		if i >= numShortBlocks {
+			n++
+		}
+		block := append([]byte{}, data[k:k+n]...)
+		k += n
+		ecc := reedSolomonRemainder(block, divisor)
+		if i < numShortBlocks {
+			block = append(block, 0) // Placeholder, skipped when interleaving.
+		}
+		blocks[i] = append(block, ecc...)
+	}
+
+	result := make([]byte, 0, rawCodewords)
+	for i := range blocks[0] {
+		for j, block := range blocks {
+			if i != shortBlockLen-blockECCLen || j >= numShortBlocks {
+				result = append(result, block[i])
+			}
+		}
+	}
+	return result
+}
+
+// reedSolomonDivisor returns the generator polynomial of the given degree,
+// without its leading coefficient, highest power first.
+func reedSolomonDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = reedSolomonMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = reedSolomonMultiply(root, 0x02)
+	}
+	return result
+}
+
+func reedSolomonRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i := range result {
+			result[i] ^= reedSolomonMultiply(divisor[i], factor)
+		}
+	}
+	return result
+}
+
+// reedSolomonMultiply multiplies in GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1.
+func reedSolomonMultiply(x, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int((y>>uint(i))&1) * int(x)
+	}
+	return byte(z)
+}
+
+// bitBuffer is a sequence of bits, most significant first.
+type bitBuffer []bool
+
+func (b *bitBuffer) append(value, length int) {
+	for i := length - 1; i >= 0; i-- {
+		*b = append(*b, bit(value, i))
+	}
+}
+
+func (b bitBuffer) bytes() []byte {
+	result := make([]byte, len(b)/8)
+	for i, set := range b {
+		if set {
+			result[i>>3] |= 0x80 >> uint(i&7)
+		}
+	}
+	return result
+}
+
+func bit(value, i int) bool {
+	return (value>>uint(i))&1 != 0
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,297 @@
+package qrcode
+
+import (
+	"bytes"
+	"testing"
+)
+
+// Test the error correction of the 1-M example in the QR code specification.
+func TestReedSolomon(t *testing.T) {
+	data := []byte{0x10, 0x20, 0x0C, 0x56, 0x61, 0x80, 0xEC, 0x11,
+		0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11}
+	expected := []byte{0xA5, 0x24, 0xD4, 0xC1, 0xED, 0x36, 0xC7, 0x87, 0x2C, 0x55}
+	ecc := reedSolomonRemainder(data, reedSolomonDivisor(10))
+	if !bytes.Equal(ecc, expected) {
+		t.Errorf("Expected %X, got %X", expected, ecc)
+	}
+}
+
+func TestFormatAndVersionBits(t *testing.T) {
+	if bits := formatBits(0); bits != 0x5412 {
+		t.Errorf("Unexpected format bits for M, mask 0: %015b", bits)
+	}
+	if bits := formatBits(5); bits != 0x40CE {
+		t.Errorf("Unexpected format bits for M, mask 5: %015b", bits)
+	}
+
+	c := newCode(7)
+	c.drawVersion()
+	bits := 0
+	for i := 17; i >= 0; i-- {
+		bits <<= 1
+		if c.modules[i/3][c.Size-11+i%3] {
+			bits |= 1
+		}
+	}
+	if bits != 0x07C94 {
+		t.Errorf("Unexpected version 7 bits: %018b", bits)
+	}
+}
+
+// Test version selection against the byte mode capacities for level M.
+func TestEncodeCapacity(t *testing.T) {
+	for _, tc := range []struct{ length, version int }{
+		{0, 1}, {14, 1}, {15, 2}, {62, 4}, {63, 5}, {213, 10}, {2331, 40},
+	} {
+		c, err := Encode(make([]byte, tc.length))
+		if err != nil {
+			t.Errorf("Failed to encode %d bytes: %v", tc.length, err)
+			continue
+		}
+		if c.Version != tc.version || c.Size != tc.version*4+17 {
+			t.Errorf("Expected version %d for %d bytes, got %d (size %d)", tc.version, tc.length, c.Version, c.Size)
+		}
+	}
+	if _, err := Encode(make([]byte, 2332)); err != ErrTooLong {
+		t.Errorf("Expected ErrTooLong, got %v", err)
+	}
+}
+
+// Test that encoded codes can be read back: finder patterns are in place,
+// the format bits are valid, and the unmasked data decodes to the input.
+func TestEncodeDecode(t *testing.T) {
+	for _, data := range []string{
+		"",
+		"duo://8LIRa5danrICkhHtkLxi-cKLu2DWzDYCmBwBHY2YzW5ZYnYaRxA",
+		string(bytes.Repeat([]byte("0123456789abcdef"), 40)),
+	} {
+		c, err := Encode([]byte(data))
+		if err != nil {
+			t.Fatalf("Failed to encode %q: %v", data, err)
+		}
+		for _, corner := range [][2]int{{0, 0}, {c.Size - 7, 0}, {0, c.Size - 7}} {
+			for i := 0; i < 7; i++ {
+				if !c.Black(corner[0]+i, corner[1]) || !c.Black(corner[0], corner[1]+i) {
+					t.Fatalf("Missing finder pattern at %v", corner)
+				}
+			}
+		}
+		if c.Black(-1, 0) || c.Black(0, c.Size) {
+			t.Error("Expected the quiet zone to be light")
+		}
+		if decoded := decode(t, c); decoded != data {
+			t.Errorf("Expected %q, got %q", data, decoded)
+		}
+	}
+}
+
+// decode reads the data back from c, checking its error correction.
+func decode(t *testing.T, c *Code) string {
+	// Format bits from the first copy, around the top left finder pattern.
+	format := 0
+	read := func(x, y int) {
+		format <<= 1
+		if c.Black(x, y) {
+			format |= 1
+		}
+	}
+	for x := 0; x <= 5; x++ {
+		read(x, 8)
+	}
+	read(7, 8)
+	read(8, 8)
+	read(8, 7)
+	for y := 5; y >= 0; y-- {
+		read(8, y)
+	}
+	mask := -1
+	for m := 0; m < 8; m++ {
+		if formatBits(m) == format {
+			mask = m
+		}
+	}
+	if mask < 0 {
+		t.Fatalf("Invalid format bits %015b", format)
+	}
+
+	// Read the codewords in zigzag order, skipping function patterns.
+	layout := newCode(c.Version)
+	layout.drawFunctionPatterns()
+	var bits bitBuffer
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < c.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x, y := right-j, vert
+				if (right+1)&2 == 0 {
+					y = c.Size - 1 - vert
+				}
+				if !layout.isFunction[y][x] {
+					bits = append(bits, c.Black(x, y) != maskBit(mask, x, y))
+				}
+			}
+		}
+	}
+	codewords := bits[:len(bits)/8*8].bytes()
+
+	// De-interleave the blocks and check their error correction.
+	numBlocks := numErrorCorrectionBlocks[c.Version]
+	eccLen := eccCodewordsPerBlock[c.Version]
+	numShortBlocks := numBlocks - len(codewords)%numBlocks
+	shortDataLen := len(codewords)/numBlocks - eccLen
+	blocks := make([][]byte, numBlocks)
+	k := 0
+	for i := 0; i <= shortDataLen; i++ {
+		for j := range blocks {
+			if i < shortDataLen || j >= numShortBlocks {
+				blocks[j] = append(blocks[j], codewords[k])
+				k++
+			}
+		}
+	}
+	var data []byte
+	for i := 0; i < eccLen; i++ {
+		for j := range blocks {
+			blocks[j] = append(blocks[j], codewords[k])
+			k++
+		}
+	}
+	divisor := reedSolomonDivisor(eccLen)
+	for j, block := range blocks {
+		dataLen := len(block) - eccLen
+		if ecc := reedSolomonRemainder(block[:dataLen], divisor); !bytes.Equal(ecc, block[dataLen:]) {
+			t.Fatalf("Block %d has invalid error correction", j)
+		}
+		data = append(data, block[:dataLen]...)
+	}
+
+	// Byte mode header, then the data.
+	if data[0]>>4 != 0x4 {
+		t.Fatalf("Unexpected mode %x", data[0]>>4)
+	}
+	var stream bitBuffer
+	for _, b := range data {
+		stream.append(int(b), 8)
+	}
+	stream = stream[4:]
+	length := 0
+	for _, set := range stream[:charCountBits(c.Version)] {
+		length <<= 1
+		if set {
+			length |= 1
+		}
+	}
+	stream = stream[charCountBits(c.Version):]
+	return string(stream[:length*8].bytes())
+}
+
+// Test full symbols against the output of another encoder (rsc.io/qr), with
+// '#' for dark modules. The version 4 symbol also checks the mask chosen by
+// Encode; the version 7 one has version information and several blocks.
+func TestEncodeGolden(t *testing.T) {
+	for _, test := range []struct {
+		data   string
+		mask   int
+		golden []string
+	}{
+		{"8LIRa5danrICkhHtkLxi-cKLu2DWzDYCmBwBHY2YzW5ZYnYaRxA", -1, []string{
+			"#######..#..#..##.###..#..#######",
+			"#.....#..##....#..#.##....#.....#",
+			"#.###.#.##.##.#.....#####.#.###.#",
+			"#.###.#.###.#.....##..##..#.###.#",
+			"#.###.#.###...###...#.....#.###.#",
+			"#.....#.#..##.#..###.#..#.#.....#",
+			"#######.#.#.#.#.#.#.#.#.#.#######",
+			"........#...#...#..###.##........",
+			"#.#####...##..#.#..#.####.#####..",
+			"#.#..#..#..####.####...#..#..#...",
+			"###..##..##..#####..#.#....#..##.",
+			"##.#.#.#.#..##..###..#.##.#..###.",
+			"....#.##..##..###..#.##..#..#.###",
+			".#......#......#.##...#..#.#..#..",
+			"..##..#....##.#####.##.........#.",
+			".#.##..#.#..#.##.....#...#.####..",
+			".##..###..#.#####.....#.##.###..#",
+			".#..#..#..###..##.###.#...#.....#",
+			"#.#.####.##..#.#..#..#....#..###.",
+			".##....#.##.#.###....##.##...####",
+			".###..##..#....#..##..##.#...##..",
+			"#....#..#.##..####..##.####..####",
+			"#.#..###.....##......##..###.#...",
+			"#.##.#...###..#.#.#..#.#..#######",
+			"#.###.##.###.##..#.##.#.#####.#..",
+			"........####..####.###..#...#.#..",
+			"#######.....#..#.#...#..#.#.#..#.",
+			"#.....#.#.#..#...##.#####...####.",
+			"#.###.#.#..###.##..####.#####...#",
+			"#.###.#.#.#..#.#..###.#..#..#.###",
+			"#.###.#.###..####.......####.....",
+			"#.....#..#..#.##...#.####.#..##..",
+			"#######.#..###.####..####.##..##.",
+		}},
+		{"https://api-eval.duosecurity.com/frame/qr?value=8LIRa5danrICkhHtkLxi-cKLu2DWzDYCmBwBHY2YzW5ZYnYaRxA&stage=enroll", 3, []string{
+			"#######.#####.###.##...#.#...##.#...#.#######",
+			"#.....#.##.###..#..##.....########.#..#.....#",
+			"#.###.#....#.#...#######..#.#####..#..#.###.#",
+			"#.###.#.#####...#.#.#####.....##...##.#.###.#",
+			"#.###.#..####..##.#.#####.#....######.#.###.#",
+			"#.....#...###.#.#####...##.#.##.##....#.....#",
+			"#######.#.#.#.#.#.#.#.#.#.#.#.#.#.#.#.#######",
+			"........#.#.#.##.####...##..#..##..#.........",
+			"#.##.###...###...########.#####..##...#..#.##",
+			"#..##..##.#.#.#...#.######...##....##...##.##",
+			"....#.#########...####.#.#.#..#####.##.....##",
+			".#..#...#.#.#.....##..##.##..#.##.#..#.#...#.",
+			"#.....##.#...###.###..#.##...###...#.#......#",
+			"##.#.#.#########.##.###..##....###...##.###..",
+			".#..###..#...##........#...#..##.#.####.#....",
+			"##.###.###.#..#.##.#..#.#..##.######..#.####.",
+			"..##..###...####.......##..#...######.##..#..",
+			"#.####....##.#.##.###.#.#..#.#...###....####.",
+			"#.#.#.###...##..###..#######...#.#.#..#.#..#.",
+			"...##...#.#.#.#.#..###...#.....#.#..#...#...#",
+			"..#.######.#.###.#.###########.#..########.##",
+			"##.##...#.##.###.####...####.###...##...###.#",
+			"...##.#.##.#.....#..#.#.#.#.#####.#.#.#.##.##",
+			".##.#...#.#.#.##.#.##...##.#..##..#.#...##..#",
+			"##.######...#.....#########....#.#..######.##",
+			".##.##.#####..###..#..#..##......#..#.##..#..",
+			".#....#...#..#..#..#..#######..#.........##..",
+			"##.##....#####.###..#..##..###..##.#...#.##..",
+			"###...######....#.#.##..#..#.####.###..####.#",
+			"#.##.#...#..#.#.#.###......#.#.##.#.######...",
+			"..###.#####....#.###.#..#.#.##.#..###..#..##.",
+			".#.#.#.##.#.#.##..##..#.###...#..##..........",
+			"#.###.##.##.....##..###.#.###.....#.#.###.###",
+			".#.##..#.....##.##.##..#.#....##.....#......#",
+			"....#.#....##.###......#..#.####.###.###...##",
+			".####....####.#..###.###.#.#.#.##.##.##.##..#",
+			"#..##.#.#..##.#.##########.#.###....#####...#",
+			"........##...#.#.#.##...#.##.#.#.#..#...#....",
+			"#######.###.#..###..#.#.#..#.##....##.#.#....",
+			"#.....#.#..##.#.#.###...##.##########...#####",
+			"#.###.#...##...#.#..########.#..##..#####.#.#",
+			"#.###.#.#.#####.##.##.#.#....#....#.###.##..#",
+			"#.###.#.##.......####...#.#.####.##.##.....#.",
+			"#.....#..#.###.#.#.#..#.#.#.##.#.##...###...#",
+			"#######.###.#..##....##....##.#..#...##..##..",
+		}},
+	} {
+		c, err := encode([]byte(test.data), test.mask)
+		if err != nil {
+			t.Fatalf("Failed to encode %q: %v", test.data, err)
+		}
+		if c.Size != len(test.golden) {
+			t.Fatalf("Expected size %d, got %d", len(test.golden), c.Size)
+		}
+		for y, row := range test.golden {
+			for x, module := range row {
+				if c.Black(x, y) != (module == '#') {
+					t.Errorf("Module (%d, %d) of %q differs from the golden symbol", x, y, test.data)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package radius
+
+import (
+	"crypto/rand"
+	"errors"
+	"net"
+	"time"
+)
+
+// Client sends Access-Requests to a RADIUS server. It is meant for testing
+// and health checks of a Server, not as a general purpose RADIUS client.
+type Client struct {
+	// Addr is the UDP address of the server, such as "127.0.0.1:1812".
+	Addr string
+	// Secret is the shared secret.
+	Secret []byte
+	// Timeout bounds the whole exchange. Zero means no timeout.
+	Timeout time.Duration
+}
+
+// Authenticate sends an Access-Request for username and password, with state
+// echoed from a previous Access-Challenge if not nil, and returns the
+// verified response.
+func (c *Client) Authenticate(username, password string, state []byte) (*Packet, error) {
+	request := &Packet{Code: AccessRequest}
+	if _, err := rand.Read(request.Authenticator[:]); err != nil {
+		return nil, err
+	}
+	var id [1]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, err
+	}
+	request.Identifier = id[0]
+
+	hidden, err := hidePassword([]byte(password), c.Secret, request.Authenticator)
+	if err != nil {
+		return nil, err
+	}
+	request.Add(UserName, []byte(username))
+	request.Add(UserPassword, hidden)
+	if state != nil {
+		request.Add(State, state)
+	}
+	request.Attributes = append([]Attribute{{MessageAuthenticator, make([]byte, 16)}}, request.Attributes...)
+	data, err := request.Encode()
+	if err != nil {
+		return nil, err
+	}
+	copy(data[headerLength+2:], messageAuthenticator(data, c.Secret))
+
+	conn, err := net.Dial("udp", c.Addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if c.Timeout > 0 {
+		conn.SetDeadline(time.Now().Add(c.Timeout))
+	}
+	if _, err = conn.Write(data); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, maxPacketLength)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		response, err := Parse(buf[:n])
+		if err != nil || response.Identifier != request.Identifier {
+			continue // Not a response to this request.
+		}
+		if err = verifyResponse(buf[:n], request.Authenticator, c.Secret); err != nil {
+			return nil, err
+		}
+		if response.Code != AccessAccept && response.Code != AccessReject && response.Code != AccessChallenge {
+			return nil, errors.New("unexpected response " + response.Code.String())
+		}
+		return response, nil
+	}
+}
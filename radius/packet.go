@@ -0,0 +1,265 @@
+// Package radius implements a RADIUS (RFC 2865) server that authenticates
+// users with Duo, for network appliances that cannot call the Auth API
+// themselves.
+//
+// Only what a second-factor front-end needs is implemented: Access-Request,
+// Access-Accept, Access-Reject and Access-Challenge packets, User-Password
+// hiding, and the Message-Authenticator attribute of RFC 3579.
+package radius
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Code is the type of a RADIUS packet.
+type Code byte
+
+// Packet codes used by the server.
+const (
+	AccessRequest   Code = 1
+	AccessAccept    Code = 2
+	AccessReject    Code = 3
+	AccessChallenge Code = 11
+)
+
+func (c Code) String() string {
+	switch c {
+	case AccessRequest:
+		return "Access-Request"
+	case AccessAccept:
+		return "Access-Accept"
+	case AccessReject:
+		return "Access-Reject"
+	case AccessChallenge:
+		return "Access-Challenge"
+	}
+	return fmt.Sprintf("Code(%d)", byte(c))
+}
+
+// AttributeType identifies a RADIUS attribute.
+type AttributeType byte
+
+// Attribute types used by the server.
+const (
+	UserName             AttributeType = 1
+	UserPassword         AttributeType = 2
+	NASIPAddress         AttributeType = 4
+	ReplyMessage         AttributeType = 18
+	State                AttributeType = 24
+	CallingStationID     AttributeType = 31
+	NASIdentifier        AttributeType = 32
+	MessageAuthenticator AttributeType = 80
+)
+
+// Attribute is a single attribute of a Packet.
+type Attribute struct {
+	Type  AttributeType
+	Value []byte
+}
+
+// Packet is a RADIUS packet.
+type Packet struct {
+	Code          Code
+	Identifier    byte
+	Authenticator [16]byte
+	Attributes    []Attribute
+}
+
+// Protocol limits from RFC 2865.
+const (
+	headerLength       = 20
+	maxPacketLength    = 4096
+	maxAttributeLength = 253
+	passwordBlockSize  = 16
+	maxPasswordLength  = 128
+)
+
+// ErrMalformedPacket is returned by Parse for data that is not a valid
+// RADIUS packet.
+var ErrMalformedPacket = errors.New("malformed RADIUS packet")
+
+// Parse decodes a RADIUS packet. Bytes beyond the packet's Length field are
+// ignored, as required by RFC 2865.
+func Parse(data []byte) (*Packet, error) {
+	if len(data) < headerLength {
+		return nil, ErrMalformedPacket
+	}
+	length := int(binary.BigEndian.Uint16(data[2:4]))
+	if length < headerLength || length > maxPacketLength || length > len(data) {
+		return nil, ErrMalformedPacket
+	}
+	p := &Packet{Code: Code(data[0]), Identifier: data[1]}
+	copy(p.Authenticator[:], data[4:headerLength])
+	for rest := data[headerLength:length]; len(rest) > 0; {
+		if len(rest) < 2 || rest[1] < 2 || int(rest[1]) > len(rest) {
+			return nil, ErrMalformedPacket
+		}
+		value := append([]byte{}, rest[2:rest[1]]...)
+		p.Attributes = append(p.Attributes, Attribute{AttributeType(rest[0]), value})
+		rest = rest[rest[1]:]
+	}
+	return p, nil
+}
+
+// Encode returns the packet in wire format, with the Authenticator as is.
+func (p *Packet) Encode() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte(byte(p.Code))
+	buf.WriteByte(p.Identifier)
+	buf.Write([]byte{0, 0})
+	buf.Write(p.Authenticator[:])
+	for _, attr := range p.Attributes {
+		if len(attr.Value) > maxAttributeLength {
+			return nil, fmt.Errorf("attribute %d is too long", attr.Type)
+		}
+		buf.WriteByte(byte(attr.Type))
+		buf.WriteByte(byte(len(attr.Value) + 2))
+		buf.Write(attr.Value)
+	}
+	data := buf.Bytes()
+	if len(data) > maxPacketLength {
+		return nil, errors.New("RADIUS packet is too long")
+	}
+	binary.BigEndian.PutUint16(data[2:4], uint16(len(data)))
+	return data, nil
+}
+
+// Get returns the value of the first attribute of the given type, or nil.
+func (p *Packet) Get(t AttributeType) []byte {
+	for _, attr := range p.Attributes {
+		if attr.Type == t {
+			return attr.Value
+		}
+	}
+	return nil
+}
+
+// Add appends an attribute to the packet.
+func (p *Packet) Add(t AttributeType, value []byte) {
+	p.Attributes = append(p.Attributes, Attribute{t, value})
+}
+
+// hidePassword encrypts a User-Password as described in RFC 2865 section 5.2.
+func hidePassword(password []byte, secret []byte, authenticator [16]byte) ([]byte, error) {
+	if len(password) > maxPasswordLength {
+		return nil, errors.New("password is too long")
+	}
+	padded := len(password) + (passwordBlockSize-len(password)%passwordBlockSize)%passwordBlockSize
+	if padded == 0 {
+		padded = passwordBlockSize
+	}
+	result := make([]byte, padded)
+	copy(result, password)
+	previous := authenticator[:]
+	for i := 0; i < padded; i += passwordBlockSize {
+		hash := md5.Sum(append(append([]byte{}, secret...), previous...))
+		for j := range hash {
+			result[i+j] ^= hash[j]
+		}
+		previous = result[i : i+passwordBlockSize]
+	}
+	return result, nil
+}
+
+// revealPassword decrypts a User-Password hidden by hidePassword.
+func revealPassword(hidden []byte, secret []byte, authenticator [16]byte) ([]byte, error) {
+	if len(hidden) == 0 || len(hidden)%passwordBlockSize != 0 || len(hidden) > maxPasswordLength {
+		return nil, errors.New("invalid User-Password length")
+	}
+	result := make([]byte, len(hidden))
+	previous := authenticator[:]
+	for i := 0; i < len(hidden); i += passwordBlockSize {
+		hash := md5.Sum(append(append([]byte{}, secret...), previous...))
+		for j := range hash {
+			result[i+j] = hidden[i+j] ^ hash[j]
+		}
+		previous = hidden[i : i+passwordBlockSize]
+	}
+	return bytes.TrimRight(result, "\x00"), nil
+}
+
+// messageAuthenticator computes the Message-Authenticator of a packet encoded
+// with a zeroed Message-Authenticator value, and with the request's
+// Authenticator in place of a response's.
+func messageAuthenticator(data []byte, secret []byte) []byte {
+	mac := hmac.New(md5.New, secret)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// messageAuthenticatorOffset returns the offset of the Message-Authenticator
+// value in an encoded packet, or -1 if it has none.
+func messageAuthenticatorOffset(data []byte) int {
+	for i := headerLength; i+2 <= len(data); i += int(data[i+1]) {
+		if data[i+1] < 2 {
+			return -1
+		}
+		if AttributeType(data[i]) == MessageAuthenticator && data[i+1] == 18 {
+			return i + 2
+		}
+	}
+	return -1
+}
+
+// verifyMessageAuthenticator checks the Message-Authenticator of an encoded
+// packet, if it has one. It reports whether the attribute was present.
+func verifyMessageAuthenticator(data []byte, secret []byte) (bool, error) {
+	offset := messageAuthenticatorOffset(data)
+	if offset < 0 {
+		return false, nil
+	}
+	zeroed := append([]byte{}, data...)
+	received := zeroed[offset : offset+md5.Size]
+	expected := append([]byte{}, received...)
+	for i := range received {
+		received[i] = 0
+	}
+	if !hmac.Equal(expected, messageAuthenticator(zeroed, secret)) {
+		return true, errors.New("invalid Message-Authenticator")
+	}
+	return true, nil
+}
+
+// encodeResponse encodes a response to the request with the given
+// authenticator. A Message-Authenticator is always added first, followed by
+// the Response Authenticator of RFC 2865 section 3.
+func encodeResponse(p *Packet, requestAuthenticator [16]byte, secret []byte) ([]byte, error) {
+	response := *p
+	response.Authenticator = requestAuthenticator
+	response.Attributes = append([]Attribute{{MessageAuthenticator, make([]byte, md5.Size)}}, p.Attributes...)
+	data, err := response.Encode()
+	if err != nil {
+		return nil, err
+	}
+	copy(data[headerLength+2:], messageAuthenticator(data, secret))
+	sum := md5.Sum(append(append([]byte{}, data...), secret...))
+	copy(data[4:headerLength], sum[:])
+	return data, nil
+}
+
+// verifyResponse checks the Response Authenticator and Message-Authenticator
+// of an encoded response to a request with the given authenticator.
+func verifyResponse(data []byte, requestAuthenticator [16]byte, secret []byte) error {
+	if len(data) < headerLength {
+		return ErrMalformedPacket
+	}
+	check := append([]byte{}, data...)
+	copy(check[4:headerLength], requestAuthenticator[:])
+	sum := md5.Sum(append(append([]byte{}, check...), secret...))
+	if !hmac.Equal(sum[:], data[4:headerLength]) {
+		return errors.New("invalid Response Authenticator")
+	}
+	present, err := verifyMessageAuthenticator(check, secret)
+	if err != nil {
+		return err
+	}
+	if !present {
+		return errors.New("response has no Message-Authenticator")
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package radius
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPacketEncodeParse(t *testing.T) {
+	p := &Packet{Code: AccessRequest, Identifier: 42}
+	copy(p.Authenticator[:], "0123456789abcdef")
+	p.Add(UserName, []byte("jsmith"))
+	p.Add(CallingStationID, []byte("192.0.2.10"))
+	data, err := p.Encode()
+	if err != nil {
+		t.Fatal("Failed to encode packet: " + err.Error())
+	}
+	if len(data) != 20+8+12 || data[2] != 0 || data[3] != 40 {
+		t.Errorf("Unexpected encoding: %x", data)
+	}
+
+	// Bytes after the Length are padding and must be ignored.
+	parsed, err := Parse(append(data, 0, 0, 0))
+	if err != nil {
+		t.Fatal("Failed to parse packet: " + err.Error())
+	}
+	if parsed.Code != AccessRequest || parsed.Identifier != 42 || parsed.Authenticator != p.Authenticator {
+		t.Errorf("Unexpected header: %+v", parsed)
+	}
+	if string(parsed.Get(UserName)) != "jsmith" || string(parsed.Get(CallingStationID)) != "192.0.2.10" {
+		t.Errorf("Unexpected attributes: %+v", parsed.Attributes)
+	}
+	if parsed.Get(State) != nil {
+		t.Error("Expected no State attribute")
+	}
+
+	for _, malformed := range [][]byte{
+		data[:19],
+		data[:39],
+		append(append([]byte{}, data[:20]...), 1, 1),
+		append(append([]byte{}, data[:20]...), 1, 9, 'x'),
+	} {
+		fixed := append([]byte{}, malformed...)
+		if len(fixed) >= 4 && len(fixed) != 39 {
+			fixed[3] = byte(len(fixed))
+		}
+		if _, err := Parse(fixed); err != ErrMalformedPacket {
+			t.Errorf("Expected ErrMalformedPacket for %x, got %v", fixed, err)
+		}
+	}
+
+	p.Add(ReplyMessage, bytes.Repeat([]byte("x"), 254))
+	if _, err := p.Encode(); err == nil {
+		t.Error("Expected error for an attribute that is too long")
+	}
+}
+
+func TestPasswordHiding(t *testing.T) {
+	secret := []byte("xyzzy5461")
+	var authenticator [16]byte
+	copy(authenticator[:], "0123456789abcdef")
+	for _, password := range []string{"", "1", "arctangent", "0123456789abcdef", "0123456789abcdefg"} {
+		hidden, err := hidePassword([]byte(password), secret, authenticator)
+		if err != nil {
+			t.Fatalf("Failed to hide %q: %v", password, err)
+		}
+		if len(hidden)%16 != 0 || len(hidden) == 0 || bytes.Contains(hidden, []byte(password)) && password != "" {
+			t.Errorf("Unexpected hidden password %x for %q", hidden, password)
+		}
+		revealed, err := revealPassword(hidden, secret, authenticator)
+		if err != nil || string(revealed) != password {
+			t.Errorf("Expected %q, got %q, %v", password, revealed, err)
+		}
+	}
+	if _, err := revealPassword(make([]byte, 15), secret, authenticator); err == nil {
+		t.Error("Expected error for a truncated password")
+	}
+	if _, err := hidePassword(make([]byte, 129), secret, authenticator); err == nil {
+		t.Error("Expected error for a password that is too long")
+	}
+}
+
+func TestResponseAuthenticators(t *testing.T) {
+	secret := []byte("s3cret")
+	var authenticator [16]byte
+	copy(authenticator[:], "0123456789abcdef")
+	response := &Packet{Code: AccessAccept, Identifier: 7}
+	response.Add(ReplyMessage, []byte("Success. Logging you in..."))
+	data, err := encodeResponse(response, authenticator, secret)
+	if err != nil {
+		t.Fatal("Failed to encode response: " + err.Error())
+	}
+	if err := verifyResponse(data, authenticator, secret); err != nil {
+		t.Error("Failed to verify response: " + err.Error())
+	}
+	if err := verifyResponse(data, authenticator, []byte("wrong")); err == nil {
+		t.Error("Expected error with the wrong secret")
+	}
+	data[len(data)-1] ^= 1
+	if err := verifyResponse(data, authenticator, secret); err == nil {
+		t.Error("Expected error for a modified response")
+	}
+}
@@ -0,0 +1,425 @@
+package radius
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	duoapi "github.com/duosecurity/duo_api_golang"
+	"github.com/duosecurity/duo_api_golang/authapi"
+)
+
+// SecretFunc returns the shared secret for a RADIUS client, or nil if the
+// client is not allowed to use the server.
+type SecretFunc func(addr net.Addr) []byte
+
+// StaticSecret returns a SecretFunc using the same secret for every client.
+func StaticSecret(secret []byte) SecretFunc {
+	return func(addr net.Addr) []byte {
+		return secret
+	}
+}
+
+// ClientSecrets returns a SecretFunc looking up secrets by the client's IP
+// address. Requests from other addresses are ignored.
+//
+// Example: radius.ClientSecrets(map[string][]byte{"192.0.2.10": []byte("s3cret")})
+func ClientSecrets(secrets map[string][]byte) SecretFunc {
+	return func(addr net.Addr) []byte {
+		host, _, err := net.SplitHostPort(addr.String())
+		if err != nil {
+			return nil
+		}
+		return secrets[host]
+	}
+}
+
+// PrimaryAuthFunc checks the primary password of a user, when the password
+// field carries both a password and a factor.
+type PrimaryAuthFunc func(username, password string) (bool, error)
+
+// Server answers RADIUS Access-Requests by authenticating users with Duo.
+//
+// The User-Password field selects the second factor: "push", "phone" or
+// "sms" start that factor with the user's default device, an empty value uses
+// the default factor, and anything else is checked as a passcode. "sms"
+// sends passcodes and answers with an Access-Challenge; the user's reply is
+// then checked as a passcode.
+//
+// With SetDelimiter, the field is "<password><delimiter><factor>" instead, and
+// the password is checked with the PrimaryAuthFunc set with SetPrimaryAuth.
+// Every request is rejected if SetDelimiter is used without SetPrimaryAuth.
+type Server struct {
+	api     *authapi.AuthApi
+	secrets SecretFunc
+
+	delimiter         string
+	defaultFactor     string
+	failOpen          bool
+	requireMsgAuth    bool
+	primary           PrimaryAuthFunc
+	logger            *log.Logger
+	challengeLifetime time.Duration
+
+	mu         sync.Mutex
+	challenges map[string]challenge
+	recent     map[string]*recentRequest
+}
+
+// challenge is an outstanding Access-Challenge.
+type challenge struct {
+	username string
+	expires  time.Time
+}
+
+// recentRequest lets retransmitted requests be answered without calling Duo
+// again.
+type recentRequest struct {
+	response []byte // nil while the request is being handled.
+	expires  time.Time
+}
+
+// Default settings of a Server.
+const (
+	defaultFactor            = "push"
+	defaultChallengeLifetime = 5 * time.Minute
+	duplicateLifetime        = 30 * time.Second
+)
+
+// Optional parameter for NewServer. The password field is then split at the
+// last occurrence of delimiter into a primary password and a factor, as in
+// "hunter2,push" with the delimiter ",".
+func SetDelimiter(delimiter string) func(*Server) {
+	return func(s *Server) {
+		s.delimiter = delimiter
+	}
+}
+
+// Optional parameter for NewServer, used to check the primary password when
+// SetDelimiter is used. It is required with SetDelimiter.
+func SetPrimaryAuth(primary PrimaryAuthFunc) func(*Server) {
+	return func(s *Server) {
+		s.primary = primary
+	}
+}
+
+// Optional parameter for NewServer, used to change the factor used when the
+// password field does not name one. The default is "push".
+func SetDefaultFactor(factor string) func(*Server) {
+	return func(s *Server) {
+		s.defaultFactor = factor
+	}
+}
+
+// Optional parameter for NewServer. When Duo cannot be reached, accept users
+// instead of rejecting them.
+func SetFailOpen() func(*Server) {
+	return func(s *Server) {
+		s.failOpen = true
+	}
+}
+
+// Optional parameter for NewServer. Ignore requests without a
+// Message-Authenticator attribute, which protects against forged responses
+// (RFC 3579). Requests that include one are always checked.
+func SetRequireMessageAuthenticator() func(*Server) {
+	return func(s *Server) {
+		s.requireMsgAuth = true
+	}
+}
+
+// Optional parameter for NewServer, used to log dropped requests and errors.
+func SetLogger(logger *log.Logger) func(*Server) {
+	return func(s *Server) {
+		s.logger = logger
+	}
+}
+
+// NewServer returns a Server using api to authenticate users. secrets returns
+// the shared secret of each client.
+//
+// Example: radius.NewServer(api, radius.StaticSecret([]byte("s3cret")), radius.SetFailOpen())
+func NewServer(api *authapi.AuthApi, secrets SecretFunc, options ...func(*Server)) *Server {
+	s := &Server{
+		api:               api,
+		secrets:           secrets,
+		defaultFactor:     defaultFactor,
+		challengeLifetime: defaultChallengeLifetime,
+		challenges:        make(map[string]challenge),
+		recent:            make(map[string]*recentRequest),
+	}
+	for _, o := range options {
+		o(s)
+	}
+	return s
+}
+
+// ListenAndServe listens on the UDP address addr, such as ":1812", and
+// serves requests until an error occurs.
+func (s *Server) ListenAndServe(addr string) error {
+	conn, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return s.Serve(conn)
+}
+
+// Serve answers the requests received on conn, each in its own goroutine,
+// until reading from conn fails, for example because it was closed.
+func (s *Server) Serve(conn net.PacketConn) error {
+	buf := make([]byte, maxPacketLength)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			return err
+		}
+		data := append([]byte{}, buf[:n]...)
+		go func() {
+			if response := s.handle(data, addr); response != nil {
+				if _, err := conn.WriteTo(response, addr); err != nil {
+					s.logf("radius: failed to reply to %s: %v", addr, err)
+				}
+			}
+		}()
+	}
+}
+
+// handle returns the encoded response to a request, or nil to drop it.
+func (s *Server) handle(data []byte, addr net.Addr) []byte {
+	secret := s.secrets(addr)
+	if secret == nil {
+		s.logf("radius: dropping request from unknown client %s", addr)
+		return nil
+	}
+	request, err := Parse(data)
+	if err != nil || request.Code != AccessRequest {
+		s.logf("radius: dropping invalid request from %s", addr)
+		return nil
+	}
+	present, err := verifyMessageAuthenticator(data, secret)
+	if err != nil || (!present && s.requireMsgAuth) {
+		s.logf("radius: dropping request from %s without a valid Message-Authenticator", addr)
+		return nil
+	}
+
+	key := addr.String() + "/" + string(request.Identifier) + string(request.Authenticator[:])
+	if response, duplicate := s.startRequest(key); duplicate {
+		return response
+	}
+	response, err := encodeResponse(s.respond(request, secret), request.Authenticator, secret)
+	if err != nil {
+		s.logf("radius: failed to encode response to %s: %v", addr, err)
+	}
+	s.finishRequest(key, response)
+	return response
+}
+
+// startRequest records a request being handled. For a retransmission it
+// returns the response already sent, or nil if it is still being handled.
+func (s *Server) startRequest(key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for k, r := range s.recent {
+		if r.response != nil && now.After(r.expires) {
+			delete(s.recent, k)
+		}
+	}
+	if r, ok := s.recent[key]; ok {
+		return r.response, true
+	}
+	s.recent[key] = &recentRequest{}
+	return nil, false
+}
+
+func (s *Server) finishRequest(key string, response []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if response == nil {
+		delete(s.recent, key)
+		return
+	}
+	s.recent[key] = &recentRequest{response: response, expires: time.Now().Add(duplicateLifetime)}
+}
+
+// respond authenticates the user of a request and returns the response.
+func (s *Server) respond(request *Packet, secret []byte) *Packet {
+	username := string(request.Get(UserName))
+	hidden := request.Get(UserPassword)
+	if username == "" || hidden == nil {
+		return reply(request, AccessReject, "")
+	}
+	password, err := revealPassword(hidden, secret, request.Authenticator)
+	if err != nil {
+		return reply(request, AccessReject, "")
+	}
+	ipaddr := string(request.Get(CallingStationID))
+	if net.ParseIP(ipaddr) == nil {
+		ipaddr = ""
+	}
+
+	if state := request.Get(State); state != nil {
+		if !s.takeChallenge(string(state), username) {
+			return reply(request, AccessReject, "Your login attempt expired. Please try again.")
+		}
+		return s.auth(request, username, ipaddr, "passcode", string(password))
+	}
+
+	factor := string(password)
+	if s.delimiter != "" {
+		primary := factor
+		factor = ""
+		if i := strings.LastIndex(primary, s.delimiter); i >= 0 {
+			primary, factor = primary[:i], primary[i+len(s.delimiter):]
+		}
+		if s.primary == nil {
+			s.logf("radius: rejecting %q: a delimiter is set without a primary authenticator", username)
+			return reply(request, AccessReject, "")
+		}
+		ok, err := s.primary(username, primary)
+		if err != nil {
+			s.logf("radius: primary authentication failed for %q: %v", username, err)
+		}
+		if err != nil || !ok {
+			return reply(request, AccessReject, "")
+		}
+	}
+
+	options := []func(*url.Values){authapi.PreauthUsername(username)}
+	if ipaddr != "" {
+		options = append(options, authapi.PreauthIpAddr(ipaddr))
+	}
+	preauth, err := s.api.Preauth(options...)
+	if err != nil {
+		return s.fail(request, err, nil)
+	}
+	if preauth.Stat != "OK" {
+		return s.fail(request, nil, &preauth.StatResult)
+	}
+	switch preauth.Response.Result {
+	case "allow":
+		return reply(request, AccessAccept, preauth.Response.Status_Msg)
+	case "auth":
+	default:
+		return reply(request, AccessReject, preauth.Response.Status_Msg)
+	}
+
+	switch strings.ToLower(factor) {
+	case "":
+		factor = s.defaultFactor
+	case "push", "phone", "sms", "auto":
+		factor = strings.ToLower(factor)
+	default:
+		return s.auth(request, username, ipaddr, "passcode", factor)
+	}
+	return s.auth(request, username, ipaddr, factor, "")
+}
+
+// auth calls Duo's Auth method and converts the result to a response.
+func (s *Server) auth(request *Packet, username, ipaddr, factor, passcode string) *Packet {
+	options := []func(*url.Values){authapi.AuthUsername(username)}
+	if ipaddr != "" {
+		options = append(options, authapi.AuthIpAddr(ipaddr))
+	}
+	if factor == "passcode" {
+		options = append(options, authapi.AuthPasscode(passcode))
+	} else {
+		options = append(options, authapi.AuthDevice("auto"))
+	}
+	result, err := s.api.Auth(factor, options...)
+	if err != nil {
+		return s.fail(request, err, nil)
+	}
+	if result.Stat != "OK" {
+		return s.fail(request, nil, &result.StatResult)
+	}
+
+	switch {
+	case result.Response.Result == "allow":
+		return reply(request, AccessAccept, result.Response.Status_Msg)
+	case factor == "sms" && result.Response.Status == "sent":
+		state, err := s.newChallenge(username)
+		if err != nil {
+			s.logf("radius: failed to create challenge: %v", err)
+			return reply(request, AccessReject, "")
+		}
+		response := reply(request, AccessChallenge, "Enter a passcode sent by SMS: ")
+		response.Add(State, []byte(state))
+		return response
+	default:
+		return reply(request, AccessReject, result.Response.Status_Msg)
+	}
+}
+
+// fail answers a request that Duo could not process. Duo being unavailable,
+// a transport error or a 5xxxx error code, is handled according to the fail
+// mode; other errors reject the user.
+func (s *Server) fail(request *Packet, err error, stat *duoapi.StatResult) *Packet {
+	if err != nil {
+		s.logf("radius: Duo call failed: %v", err)
+	} else {
+		s.logf("radius: Duo call failed: %v", stat.Err())
+	}
+	unavailable := err != nil || (stat.Code != nil && *stat.Code >= 50000)
+	if unavailable && s.failOpen {
+		return reply(request, AccessAccept, "")
+	}
+	return reply(request, AccessReject, "")
+}
+
+func (s *Server) newChallenge(username string) (string, error) {
+	state := make([]byte, 16)
+	if _, err := rand.Read(state); err != nil {
+		return "", err
+	}
+	key := hex.EncodeToString(state)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for k, c := range s.challenges {
+		if now.After(c.expires) {
+			delete(s.challenges, k)
+		}
+	}
+	s.challenges[key] = challenge{username, now.Add(s.challengeLifetime)}
+	return key, nil
+}
+
+// takeChallenge consumes the challenge with the given state, reporting
+// whether it was issued to username and has not expired.
+func (s *Server) takeChallenge(state, username string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.challenges[state]
+	delete(s.challenges, state)
+	return ok && c.username == username && time.Now().Before(c.expires)
+}
+
+func (s *Server) logf(format string, args ...interface{}) {
+	if s.logger != nil {
+		s.logger.Printf(format, args...)
+	}
+}
+
+// reply returns a response to request with an optional Reply-Message.
+func reply(request *Packet, code Code, message string) *Packet {
+	response := &Packet{Code: code, Identifier: request.Identifier}
+	if len(message) > maxAttributeLength {
+		message = message[:maxAttributeLength]
+	}
+	if message != "" {
+		response.Add(ReplyMessage, []byte(message))
+	}
+	return response
+}
@@ -0,0 +1,332 @@
+package radius
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	duoapi "github.com/duosecurity/duo_api_golang"
+	"github.com/duosecurity/duo_api_golang/authapi"
+)
+
+func buildAuthApi(url string) *authapi.AuthApi {
+	host := strings.Split(url, "//")[1]
+	return authapi.NewAuthApi(*duoapi.NewDuoApi("eyekey",
+		"esskey",
+		host,
+		"GoTestClient",
+		duoapi.SetTimeout(1*time.Second),
+		duoapi.SetInsecure()))
+}
+
+const (
+	preauthAuth  = `{"stat": "OK", "response": {"result": "auth", "status_msg": "Account is active"}}`
+	preauthAllow = `{"stat": "OK", "response": {"result": "allow", "status_msg": "Account is active"}}`
+	authAllow    = `{"stat": "OK", "response": {"result": "allow", "status": "allow", "status_msg": "Success. Logging you in..."}}`
+	authDeny     = `{"stat": "OK", "response": {"result": "deny", "status": "deny", "status_msg": "Incorrect passcode. Please try again."}}`
+	authSMSSent  = `{"stat": "OK", "response": {"result": "deny", "status": "sent", "status_msg": "New SMS passcodes sent"}}`
+	duoDown      = `{"stat": "FAIL", "code": 50000, "message": "Internal error"}`
+)
+
+// fakeDuo serves canned responses for each Auth API path and records the
+// parameters of each request.
+type fakeDuo struct {
+	mu        sync.Mutex
+	responses map[string][]string
+	params    map[string][]url.Values
+}
+
+func newFakeDuo(t *testing.T, responses map[string][]string) (*fakeDuo, *httptest.Server) {
+	fake := &fakeDuo{responses: responses, params: make(map[string][]url.Values)}
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("Failed to parse request: %v", err)
+		}
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		fake.params[r.URL.Path] = append(fake.params[r.URL.Path], r.Form)
+		queue := fake.responses[r.URL.Path]
+		if len(queue) == 0 {
+			t.Errorf("Unexpected request to %s", r.URL.Path)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if strings.Contains(queue[0], `"FAIL"`) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		fmt.Fprintln(w, queue[0])
+		if len(queue) > 1 {
+			fake.responses[r.URL.Path] = queue[1:]
+		}
+	}))
+	return fake, ts
+}
+
+func (f *fakeDuo) last(path string) url.Values {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	calls := f.params[path]
+	if len(calls) == 0 {
+		return url.Values{}
+	}
+	return calls[len(calls)-1]
+}
+
+func (f *fakeDuo) calls(path string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.params[path])
+}
+
+// startServer serves s on a local UDP port and returns a client for it.
+func startServer(t *testing.T, s *Server) (*Client, func()) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve(conn)
+	client := &Client{Addr: conn.LocalAddr().String(), Secret: []byte("s3cret"), Timeout: 5 * time.Second}
+	return client, func() { conn.Close() }
+}
+
+func TestServerPush(t *testing.T) {
+	fake, ts := newFakeDuo(t, map[string][]string{
+		"/auth/v2/preauth": {preauthAuth},
+		"/auth/v2/auth":    {authAllow},
+	})
+	defer ts.Close()
+	client, stop := startServer(t, NewServer(buildAuthApi(ts.URL), StaticSecret([]byte("s3cret"))))
+	defer stop()
+
+	response, err := client.Authenticate("jsmith", "push", nil)
+	if err != nil {
+		t.Fatal("Failed to authenticate: " + err.Error())
+	}
+	if response.Code != AccessAccept || string(response.Get(ReplyMessage)) != "Success. Logging you in..." {
+		t.Errorf("Expected Access-Accept, got %s %q", response.Code, response.Get(ReplyMessage))
+	}
+	auth := fake.last("/auth/v2/auth")
+	if auth.Get("username") != "jsmith" || auth.Get("factor") != "push" || auth.Get("device") != "auto" {
+		t.Errorf("Unexpected auth parameters: %v", auth)
+	}
+}
+
+func TestServerPasscodeAndDefaultFactor(t *testing.T) {
+	fake, ts := newFakeDuo(t, map[string][]string{
+		"/auth/v2/preauth": {preauthAuth},
+		"/auth/v2/auth":    {authDeny, authAllow},
+	})
+	defer ts.Close()
+	server := NewServer(buildAuthApi(ts.URL), StaticSecret([]byte("s3cret")), SetDefaultFactor("phone"))
+	client, stop := startServer(t, server)
+	defer stop()
+
+	response, err := client.Authenticate("jsmith", "123456", nil)
+	if err != nil {
+		t.Fatal("Failed to authenticate: " + err.Error())
+	}
+	if response.Code != AccessReject || string(response.Get(ReplyMessage)) != "Incorrect passcode. Please try again." {
+		t.Errorf("Expected Access-Reject, got %s %q", response.Code, response.Get(ReplyMessage))
+	}
+	if auth := fake.last("/auth/v2/auth"); auth.Get("factor") != "passcode" || auth.Get("passcode") != "123456" {
+		t.Errorf("Unexpected auth parameters: %v", auth)
+	}
+
+	if response, err = client.Authenticate("jsmith", "", nil); err != nil || response.Code != AccessAccept {
+		t.Fatalf("Expected Access-Accept, got %v, %v", response, err)
+	}
+	if auth := fake.last("/auth/v2/auth"); auth.Get("factor") != "phone" {
+		t.Errorf("Expected the default factor, got %v", auth)
+	}
+}
+
+func TestServerPreauthResults(t *testing.T) {
+	fake, ts := newFakeDuo(t, map[string][]string{
+		"/auth/v2/preauth": {
+			preauthAllow,
+			`{"stat": "OK", "response": {"result": "deny", "status_msg": "Your account is disabled"}}`,
+		},
+	})
+	defer ts.Close()
+	client, stop := startServer(t, NewServer(buildAuthApi(ts.URL), StaticSecret([]byte("s3cret"))))
+	defer stop()
+
+	if response, err := client.Authenticate("jsmith", "push", nil); err != nil || response.Code != AccessAccept {
+		t.Errorf("Expected Access-Accept for a bypass user, got %v, %v", response, err)
+	}
+	response, err := client.Authenticate("jsmith", "push", nil)
+	if err != nil || response.Code != AccessReject || string(response.Get(ReplyMessage)) != "Your account is disabled" {
+		t.Errorf("Expected Access-Reject for a denied user, got %v, %v", response, err)
+	}
+	if fake.calls("/auth/v2/auth") != 0 {
+		t.Error("Expected no Auth calls")
+	}
+}
+
+func TestServerSMSChallenge(t *testing.T) {
+	fake, ts := newFakeDuo(t, map[string][]string{
+		"/auth/v2/preauth": {preauthAuth},
+		"/auth/v2/auth":    {authSMSSent, authSMSSent, authAllow},
+	})
+	defer ts.Close()
+	client, stop := startServer(t, NewServer(buildAuthApi(ts.URL), StaticSecret([]byte("s3cret"))))
+	defer stop()
+
+	response, err := client.Authenticate("jsmith", "sms", nil)
+	if err != nil {
+		t.Fatal("Failed to authenticate: " + err.Error())
+	}
+	state := response.Get(State)
+	if response.Code != AccessChallenge || state == nil {
+		t.Fatalf("Expected Access-Challenge with State, got %s", response.Code)
+	}
+
+	// The same state cannot be used by another user.
+	if response, err = client.Authenticate("jdoe", "1234567", state); err != nil || response.Code != AccessReject {
+		t.Errorf("Expected Access-Reject for another user, got %v, %v", response, err)
+	}
+
+	response, err = client.Authenticate("jsmith", "1234567", state)
+	if err != nil || response.Code != AccessReject {
+		t.Errorf("Expected Access-Reject for a consumed challenge, got %v, %v", response, err)
+	}
+
+	response, err = client.Authenticate("jsmith", "sms", nil)
+	if err != nil || response.Code != AccessChallenge {
+		t.Fatalf("Expected Access-Challenge, got %v, %v", response, err)
+	}
+	response, err = client.Authenticate("jsmith", "1234567", response.Get(State))
+	if err != nil || response.Code != AccessAccept {
+		t.Errorf("Expected Access-Accept, got %v, %v", response, err)
+	}
+	if auth := fake.last("/auth/v2/auth"); auth.Get("factor") != "passcode" || auth.Get("passcode") != "1234567" {
+		t.Errorf("Unexpected auth parameters: %v", auth)
+	}
+	if fake.calls("/auth/v2/preauth") != 2 {
+		t.Errorf("Expected no preauth for challenge responses, got %d", fake.calls("/auth/v2/preauth"))
+	}
+}
+
+func TestServerDelimiter(t *testing.T) {
+	fake, ts := newFakeDuo(t, map[string][]string{
+		"/auth/v2/preauth": {preauthAuth},
+		"/auth/v2/auth":    {authAllow},
+	})
+	defer ts.Close()
+	primary := func(username, password string) (bool, error) {
+		return username == "jsmith" && password == "hunter2,x", nil
+	}
+	server := NewServer(buildAuthApi(ts.URL), StaticSecret([]byte("s3cret")),
+		SetDelimiter(","), SetPrimaryAuth(primary))
+	client, stop := startServer(t, server)
+	defer stop()
+
+	if response, err := client.Authenticate("jsmith", "wrong,push", nil); err != nil || response.Code != AccessReject {
+		t.Errorf("Expected Access-Reject for a wrong password, got %v, %v", response, err)
+	}
+	if fake.calls("/auth/v2/preauth") != 0 {
+		t.Error("Expected no call to Duo after a wrong password")
+	}
+
+	response, err := client.Authenticate("jsmith", "hunter2,x,123456", nil)
+	if err != nil || response.Code != AccessAccept {
+		t.Errorf("Expected Access-Accept, got %v, %v", response, err)
+	}
+	if auth := fake.last("/auth/v2/auth"); auth.Get("passcode") != "123456" {
+		t.Errorf("Unexpected auth parameters: %v", auth)
+	}
+}
+
+// Test that a delimiter without a primary authenticator never skips the
+// password check.
+func TestServerDelimiterWithoutPrimary(t *testing.T) {
+	fake, ts := newFakeDuo(t, map[string][]string{
+		"/auth/v2/preauth": {preauthAuth},
+		"/auth/v2/auth":    {authAllow},
+	})
+	defer ts.Close()
+	client, stop := startServer(t, NewServer(buildAuthApi(ts.URL), StaticSecret([]byte("s3cret")), SetDelimiter(",")))
+	defer stop()
+
+	if response, err := client.Authenticate("jsmith", "anything,push", nil); err != nil || response.Code != AccessReject {
+		t.Errorf("Expected Access-Reject, got %v, %v", response, err)
+	}
+	if fake.calls("/auth/v2/preauth") != 0 {
+		t.Error("Expected no call to Duo")
+	}
+}
+
+func TestServerFailMode(t *testing.T) {
+	_, ts := newFakeDuo(t, map[string][]string{
+		"/auth/v2/preauth": {duoDown},
+	})
+	defer ts.Close()
+	api := buildAuthApi(ts.URL)
+
+	client, stop := startServer(t, NewServer(api, StaticSecret([]byte("s3cret"))))
+	defer stop()
+	if response, err := client.Authenticate("jsmith", "push", nil); err != nil || response.Code != AccessReject {
+		t.Errorf("Expected Access-Reject when failing closed, got %v, %v", response, err)
+	}
+
+	client, stop = startServer(t, NewServer(api, StaticSecret([]byte("s3cret")), SetFailOpen()))
+	defer stop()
+	if response, err := client.Authenticate("jsmith", "push", nil); err != nil || response.Code != AccessAccept {
+		t.Errorf("Expected Access-Accept when failing open, got %v, %v", response, err)
+	}
+}
+
+// Test that requests with the wrong secret, or from unknown clients, are dropped.
+func TestServerSecrets(t *testing.T) {
+	_, ts := newFakeDuo(t, map[string][]string{})
+	defer ts.Close()
+	secrets := ClientSecrets(map[string][]byte{"192.0.2.10": []byte("s3cret")})
+	client, stop := startServer(t, NewServer(buildAuthApi(ts.URL), secrets))
+	defer stop()
+
+	client.Timeout = 200 * time.Millisecond
+	if _, err := client.Authenticate("jsmith", "push", nil); err == nil {
+		t.Error("Expected no response for an unknown client")
+	}
+
+	client, stop = startServer(t, NewServer(buildAuthApi(ts.URL), StaticSecret([]byte("other"))))
+	defer stop()
+	client.Timeout = 200 * time.Millisecond
+	if _, err := client.Authenticate("jsmith", "push", nil); err == nil {
+		t.Error("Expected no response with the wrong secret")
+	}
+}
+
+// Test that a retransmitted request gets the same response without calling
+// Duo again.
+func TestServerRetransmission(t *testing.T) {
+	fake, ts := newFakeDuo(t, map[string][]string{
+		"/auth/v2/preauth": {preauthAuth},
+		"/auth/v2/auth":    {authAllow},
+	})
+	defer ts.Close()
+	server := NewServer(buildAuthApi(ts.URL), StaticSecret([]byte("s3cret")))
+
+	request := &Packet{Code: AccessRequest, Identifier: 1}
+	copy(request.Authenticator[:], "0123456789abcdef")
+	hidden, _ := hidePassword([]byte("push"), []byte("s3cret"), request.Authenticator)
+	request.Add(UserName, []byte("jsmith"))
+	request.Add(UserPassword, hidden)
+	data, _ := request.Encode()
+	addr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 5000}
+
+	first := server.handle(data, addr)
+	second := server.handle(data, addr)
+	if first == nil || string(first) != string(second) {
+		t.Errorf("Expected the same response, got %x and %x", first, second)
+	}
+	if fake.calls("/auth/v2/auth") != 1 {
+		t.Errorf("Expected a single Auth call, got %d", fake.calls("/auth/v2/auth"))
+	}
+}